@@ -32,7 +32,17 @@ func main() {
 		log.Fatalf("schema ensure failed: %v", err)
 	}
 
-	if err := game.EnsureUniverse(ctx, pool, game.UniverseConfig{Seed: cfg.UniverseSeed, Sectors: cfg.UniverseSectors}); err != nil {
+	genParams, err := game.ParseGeneratorParams(cfg.UniverseGeneratorParams)
+	if err != nil {
+		log.Fatalf("invalid UNIVERSE_GENERATOR_PARAMS: %v", err)
+	}
+	universeCfg := game.UniverseConfig{
+		Seed:      cfg.UniverseSeed,
+		Sectors:   cfg.UniverseSectors,
+		Generator: cfg.UniverseGenerator,
+		Params:    genParams,
+	}
+	if err := game.EnsureUniverse(ctx, pool, universeCfg); err != nil {
 		log.Fatalf("universe init failed: %v", err)
 	}
 
@@ -40,6 +50,18 @@ func main() {
 		log.Fatalf("protectorate init failed: %v", err)
 	}
 
+	if err := game.EnsurePortClasses(ctx, pool, cfg.UniverseSeed); err != nil {
+		log.Fatalf("port class init failed: %v", err)
+	}
+
+	if err := game.EnsureSectorEnvironments(ctx, pool, cfg.UniverseSeed); err != nil {
+		log.Fatalf("sector environment init failed: %v", err)
+	}
+
+	if err := game.EnsureProtectorateReachability(ctx, pool); err != nil {
+		log.Fatalf("protectorate reachability failed: %v", err)
+	}
+
 	if res, err := game.EnsureInitialAdmin(ctx, pool, cfg.InitialAdminUser, cfg.InitialAdminPass); err != nil {
 		log.Printf("initial admin ensure failed: %v", err)
 	} else if res.Created {
@@ -52,6 +74,9 @@ func main() {
 	game.StartPlanetTicker(ctx, pool, cfg.PlanetTickSeconds)
 	game.StartEventTicker(ctx, pool, cfg.EventTickSeconds)
 	game.StartProtectorateTicker(ctx, pool, cfg.ProtectorateTickSeconds)
+	game.StartBankTicker(ctx, pool, cfg.BankTickSeconds)
+	game.StartSeasonTicker(ctx, pool, cfg.SeasonTickSeconds)
+	game.StartRankingTicker(ctx, pool, cfg.RankingTickSeconds)
 
 	srv := &http.Server{
 		Addr:              cfg.HTTPAddr,
@@ -68,6 +68,8 @@ func (s *Server) Router() http.Handler {
 
 	if s.Cfg.AdminSecret != "" {
 		r.Post("/api/admin/soft_wipe", s.handleAdminSoftWipe)
+		r.Post("/api/admin/season", s.handleAdminSeasonSchedule)
+		r.Post("/api/admin/season/rules", s.handleAdminSeasonRules)
 	}
 
 	r.Group(func(protected chi.Router) {
@@ -75,6 +77,14 @@ func (s *Server) Router() http.Handler {
 		protected.Get("/api/state", s.handleState)
 		protected.Post("/api/command", s.handleCommand)
 		protected.Post("/api/change_password", s.handleChangePassword)
+		protected.Get("/api/map", s.handleMap)
+		protected.Get("/api/news", s.handleNews)
+		protected.Get("/api/seasons", s.handleSeasons)
+		protected.Get("/api/seasons/{id}/results", s.handleSeasonResults)
+		// Corp chat
+		protected.Get("/api/corp/chat", s.handleCorpChat)
+		protected.Post("/api/corp/chat/mark_read", s.handleCorpChatMarkRead)
+		protected.Post("/api/corp/chat/delete", s.handleCorpChatDelete)
 		// Direct messages / bug reporting
 		protected.Get("/api/messages/inbox", s.handleInboxMessages)
 		protected.Get("/api/messages/sent", s.handleSentMessages)
@@ -85,6 +95,9 @@ func (s *Server) Router() http.Handler {
 		protected.Post("/api/messages/report", s.handleReportMessage)
 		protected.Get("/api/messages/attachments/{id}", s.handleDownloadMessageAttachment)
 		protected.Get("/api/admin/ansi_map", s.handleAdminAnsiMap)
+		protected.Get("/api/admin/svg_map", s.handleAdminSVGMap)
+		protected.Get("/api/admin/analysis", s.handleAdminAnalysis)
+		protected.Post("/api/admin/expand", s.handleAdminExpand)
 		protected.Post("/api/bug_report", s.handleBugReport)
 	})
 
@@ -442,7 +455,7 @@ func (s *Server) loadState(ctx context.Context, playerID string) (game.PlayerSta
 	}
 
 	// Turns regenerate on demand, but the last regen timestamp must be persisted to avoid double counting.
-	game.RegenTurns(&p, s.Cfg.TurnRegenSeconds, time.Now().UTC())
+	game.RegenTurns(&p, p.Rules.TurnRegen(s.Cfg.TurnRegenSeconds), time.Now().UTC())
 	if err := game.SavePlayer(ctx, tx, p); err != nil {
 		return game.PlayerState{}, game.SectorView{}, nil, err
 	}
@@ -460,6 +473,102 @@ func (s *Server) loadState(ctx context.Context, playerID string) (game.PlayerSta
 	return p.ToState(), sector, logs, nil
 }
 
+// handleMap returns the player's discovered part of the galaxy. ?format=dot and ?format=ansi
+// return GraphViz and terminal renderings as text/plain instead of JSON.
+func (s *Server) handleMap(w http.ResponseWriter, r *http.Request) {
+	pid, ok := playerIDFrom(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "missing player context")
+		return
+	}
+
+	m, err := game.LoadPlayerMap(r.Context(), s.Pool, pid)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	var text string
+	switch strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format"))) {
+	case "", "json":
+		writeJSON(w, http.StatusOK, map[string]any{
+			"ok":  true,
+			"map": m,
+		})
+		return
+	case "dot":
+		text = m.DOT()
+	case "ansi":
+		text = m.ANSI()
+	default:
+		writeError(w, http.StatusBadRequest, "format must be json, dot or ansi")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(text))
+}
+
+// handleNews returns the latest galaxy news headlines, newest first (?limit=, default 20, max 100).
+func (s *Server) handleNews(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	news, err := game.LoadGalaxyNews(r.Context(), s.Pool, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":   true,
+		"news": news,
+	})
+}
+
+// handleSeasons lists finished seasons, newest first (?limit=, default 10, max 100), with the
+// all-time records across them.
+func (s *Server) handleSeasons(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	ctx := r.Context()
+	seasons, err := game.LoadArchivedSeasons(ctx, s.Pool, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	records, err := game.LoadSeasonRecords(ctx, s.Pool)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":      true,
+		"seasons": seasons,
+		"records": records,
+	})
+}
+
+// handleSeasonResults returns a finished season's final standings (?limit=, default 100, max 1000).
+func (s *Server) handleSeasonResults(w http.ResponseWriter, r *http.Request) {
+	seasonID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || seasonID < 1 {
+		writeError(w, http.StatusBadRequest, "invalid season id")
+		return
+	}
+	limit := parseLimit(r, 100, 1000)
+	season, results, err := game.LoadSeasonResults(r.Context(), s.Pool, seasonID, limit)
+	if errors.Is(err, game.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "season not found or still running")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":      true,
+		"season":  season,
+		"results": results,
+	})
+}
+
 func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
 	pid, ok := playerIDFrom(r.Context())
 	if !ok {
@@ -572,6 +681,107 @@ type deleteMessageRequest struct {
 	MessageID int64 `json:"message_id"`
 }
 
+const (
+	defaultCorpChatLimit = 50
+	defaultCorpChatMax   = 100
+)
+
+// handleCorpChat returns a page of the player's corp chat, newest first. ?before= takes the
+// next_before cursor of the previous page.
+func (s *Server) handleCorpChat(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pid := mustPlayerID(ctx)
+
+	var before int64
+	if q := strings.TrimSpace(r.URL.Query().Get("before")); q != "" {
+		n, err := strconv.ParseInt(q, 10, 64)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "invalid before")
+			return
+		}
+		before = n
+	}
+	limit := parseLimit(r, defaultCorpChatLimit, defaultCorpChatMax)
+
+	page, err := game.LoadCorpChat(ctx, s.Pool, pid, before, limit)
+	if errors.Is(err, game.ErrNotInCorp) {
+		writeError(w, http.StatusNotFound, "not in a corporation")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":           true,
+		"messages":     page.Messages,
+		"next_before":  page.NextBefore,
+		"last_read_id": page.LastReadID,
+		"unread":       page.Unread,
+	})
+}
+
+type corpChatMarkReadRequest struct {
+	UpTo int64 `json:"up_to"`
+}
+
+// handleCorpChatMarkRead moves the read marker to up_to, or to the newest message when omitted.
+func (s *Server) handleCorpChatMarkRead(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pid := mustPlayerID(ctx)
+
+	var req corpChatMarkReadRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json")
+			return
+		}
+	}
+
+	readID, err := game.MarkCorpChatRead(ctx, s.Pool, pid, req.UpTo)
+	if errors.Is(err, game.ErrNotInCorp) {
+		writeError(w, http.StatusNotFound, "not in a corporation")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "last_read_id": readID})
+}
+
+func (s *Server) handleCorpChatDelete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pid := mustPlayerID(ctx)
+
+	var req deleteMessageRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.MessageID <= 0 {
+		writeError(w, http.StatusBadRequest, "message_id required")
+		return
+	}
+
+	err := game.DeleteCorpChatMessage(ctx, s.Pool, pid, req.MessageID)
+	switch {
+	case errors.Is(err, game.ErrNotInCorp), errors.Is(err, game.ErrNotFound):
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	case errors.Is(err, game.ErrForbidden):
+		writeError(w, http.StatusForbidden, "forbidden")
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
+
 func (s *Server) handleUnreadMessageCount(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	pid := mustPlayerID(ctx)
@@ -809,11 +1019,12 @@ func (s *Server) handleReportMessage(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (s *Server) handleAdminAnsiMap(w http.ResponseWriter, r *http.Request) {
+// requireAdmin writes an error response and returns false unless the caller is an admin player.
+func (s *Server) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
 	pid, ok := playerIDFrom(r.Context())
 	if !ok || pid == "" {
 		writeError(w, http.StatusUnauthorized, "missing player context")
-		return
+		return false
 	}
 
 	var isAdmin bool
@@ -826,13 +1037,20 @@ func (s *Server) handleAdminAnsiMap(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			writeError(w, http.StatusUnauthorized, "player not found")
-			return
+			return false
 		}
 		writeError(w, http.StatusInternalServerError, "db error")
-		return
+		return false
 	}
 	if !isAdmin {
 		writeError(w, http.StatusForbidden, "admin access required")
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleAdminAnsiMap(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
 		return
 	}
 
@@ -848,6 +1066,109 @@ func (s *Server) handleAdminAnsiMap(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (s *Server) handleAdminSVGMap(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	q := r.URL.Query()
+	overlays, err := game.ParseMapOverlays(q.Get("overlays"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	svg, err := game.GenerateAdminSVGMap(r.Context(), s.Pool, game.AdminSVGMapOptions{
+		Seed:     s.Cfg.UniverseSeed,
+		Overlays: overlays,
+		Corp:     q.Get("corp"),
+		Region:   q.Get("region"),
+	})
+	if errors.Is(err, game.ErrInvalidMapFilter) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(svg))
+}
+
+func (s *Server) handleAdminAnalysis(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	analysis, err := game.LoadUniverseAnalysis(r.Context(), s.Pool)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	if strings.EqualFold(r.URL.Query().Get("format"), "text") {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(analysis.Report()))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":       true,
+		"analysis": analysis,
+	})
+}
+
+type expandRequest struct {
+	Sectors   int    `json:"sectors"`
+	Seed      int64  `json:"seed"`
+	Generator string `json:"generator"`
+	Params    string `json:"params"`
+	Frontier  bool   `json:"frontier"`
+	Gateways  []int  `json:"gateways"`
+}
+
+func (s *Server) handleAdminExpand(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	var req expandRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	params, err := game.ParseGeneratorParams(req.Params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := game.ExpandUniverse(r.Context(), s.Pool, game.ExpandUniverseRequest{
+		Sectors:   req.Sectors,
+		Seed:      req.Seed,
+		Generator: req.Generator,
+		Params:    params,
+		Frontier:  req.Frontier,
+		Gateways:  req.Gateways,
+	})
+	if err != nil {
+		if errors.Is(err, game.ErrInvalidExpansion) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":     true,
+		"result": res,
+	})
+}
+
 func (s *Server) handleBugReport(w http.ResponseWriter, r *http.Request) {
 	pid, ok := playerIDFrom(r.Context())
 	if !ok {
@@ -1135,30 +1456,101 @@ func sanitizeFilename(name string) string {
 	return name
 }
 
+// seasonScheduleRequest is the season end time and victory targets; zero disables each one.
+type seasonScheduleRequest struct {
+	EndsInHours     float64 `json:"ends_in_hours"`
+	VictoryNetWorth int64   `json:"victory_net_worth"`
+	VictoryPlanets  int     `json:"victory_planets"`
+}
+
+func (req seasonScheduleRequest) schedule() game.SeasonSchedule {
+	return game.SeasonSchedule{
+		EndsIn:          time.Duration(req.EndsInHours * float64(time.Hour)),
+		VictoryNetWorth: req.VictoryNetWorth,
+		VictoryPlanets:  req.VictoryPlanets,
+	}
+}
+
 type softWipeRequest struct {
-	SeasonName string `json:"season_name"`
-	ResetCorps bool   `json:"reset_corps"`
+	SeasonName string          `json:"season_name"`
+	ResetCorps bool            `json:"reset_corps"`
+	Rules      json.RawMessage `json:"rules"`
+	seasonScheduleRequest
 }
 
-func (s *Server) handleAdminSoftWipe(w http.ResponseWriter, r *http.Request) {
+// checkAdminSecret writes an error response and returns false unless X-Admin-Secret matches.
+func (s *Server) checkAdminSecret(w http.ResponseWriter, r *http.Request) bool {
 	if s.Cfg.AdminSecret == "" {
 		writeError(w, http.StatusNotFound, "not found")
-		return
+		return false
 	}
 	secret := r.Header.Get("X-Admin-Secret")
 	if secret == "" || secret != s.Cfg.AdminSecret {
 		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleAdminSoftWipe(w http.ResponseWriter, r *http.Request) {
+	if !s.checkAdminSecret(w, r) {
 		return
 	}
 
 	var req softWipeRequest
 	_ = json.NewDecoder(r.Body).Decode(&req)
+	if req.EndsInHours < 0 || req.VictoryNetWorth < 0 || req.VictoryPlanets < 0 {
+		writeError(w, http.StatusBadRequest, "schedule values must not be negative")
+		return
+	}
+	rules, err := game.ParseSeasonRules(req.Rules)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	ctx := r.Context()
 	res, err := game.SoftWipe(ctx, s.Pool, game.SoftWipeRequest{
 		SeasonName: strings.TrimSpace(req.SeasonName),
 		ResetCorps: req.ResetCorps,
+		Schedule:   req.schedule(),
+		Rules:      rules,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":     true,
+		"result": res,
 	})
+}
+
+func (s *Server) handleAdminSeasonSchedule(w http.ResponseWriter, r *http.Request) {
+	if !s.checkAdminSecret(w, r) {
+		return
+	}
+
+	var req seasonScheduleRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.EndsInHours < 0 || req.VictoryNetWorth < 0 || req.VictoryPlanets < 0 {
+		writeError(w, http.StatusBadRequest, "schedule values must not be negative")
+		return
+	}
+
+	res, err := game.SetSeasonSchedule(r.Context(), s.Pool, req.schedule())
+	if errors.Is(err, game.ErrSeasonFrozen) {
+		writeError(w, http.StatusConflict, "season is frozen")
+		return
+	}
+	if errors.Is(err, game.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "no active season")
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "db error")
 		return
@@ -1170,6 +1562,43 @@ func (s *Server) handleAdminSoftWipe(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleAdminSeasonRules replaces the active season's rule set with the request body.
+func (s *Server) handleAdminSeasonRules(w http.ResponseWriter, r *http.Request) {
+	if !s.checkAdminSecret(w, r) {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	rules, err := game.ParseSeasonRules(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := game.SetSeasonRules(r.Context(), s.Pool, rules)
+	if errors.Is(err, game.ErrSeasonFrozen) {
+		writeError(w, http.StatusConflict, "season is frozen")
+		return
+	}
+	if errors.Is(err, game.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "no active season")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":    true,
+		"rules": res,
+	})
+}
+
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
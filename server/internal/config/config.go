@@ -18,11 +18,16 @@ type Config struct {
 	InitialAdminPass        string
 	UniverseSeed            int64
 	UniverseSectors         int
+	UniverseGenerator       string
+	UniverseGeneratorParams string
 	TurnRegenSeconds        int
 	PortTickSeconds         int
 	PlanetTickSeconds       int
 	EventTickSeconds        int
 	ProtectorateTickSeconds int
+	BankTickSeconds         int
+	SeasonTickSeconds       int
+	RankingTickSeconds      int
 	HTTPAddr                string
 	WebRoot                 string
 }
@@ -36,11 +41,16 @@ func Load() Config {
 		InitialAdminPass:        env("INITIAL_ADMIN_PASSWORD", "ChangeMeNow!"),
 		UniverseSeed:            envInt64("UNIVERSE_SEED", 2002),
 		UniverseSectors:         envInt("UNIVERSE_SECTORS", 200),
+		UniverseGenerator:       env("UNIVERSE_GENERATOR", "RING"),
+		UniverseGeneratorParams: env("UNIVERSE_GENERATOR_PARAMS", ""),
 		TurnRegenSeconds:        envInt("TURN_REGEN_SECONDS", 120),
 		PortTickSeconds:         envInt("PORT_TICK_SECONDS", 60),
 		PlanetTickSeconds:       envInt("PLANET_TICK_SECONDS", 60),
 		EventTickSeconds:        envInt("EVENT_TICK_SECONDS", 60),
 		ProtectorateTickSeconds: envInt("PROTECTORATE_TICK_SECONDS", 60),
+		BankTickSeconds:         envInt("BANK_TICK_SECONDS", 3600),
+		SeasonTickSeconds:       envInt("SEASON_TICK_SECONDS", 60),
+		RankingTickSeconds:      envInt("RANKING_TICK_SECONDS", 300),
 		HTTPAddr:                env("HTTP_ADDR", ":8080"),
 		WebRoot:                 env("WEB_ROOT", ""),
 	}
@@ -12,6 +12,8 @@ import (
 type SoftWipeRequest struct {
 	SeasonName string
 	ResetCorps bool
+	Schedule   SeasonSchedule
+	Rules      SeasonRules
 }
 
 type SoftWipeResult struct {
@@ -29,7 +31,29 @@ func SoftWipe(ctx context.Context, pool *pgxpool.Pool, req SoftWipeRequest) (Sof
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	_, _ = tx.Exec(ctx, "UPDATE seasons SET active=false, ended_at=now() WHERE active=true")
+	// Archive the ending season's standings before players are reset.
+	rows, err := tx.Query(ctx, "UPDATE seasons SET active=false, ended_at=now() WHERE active=true RETURNING id")
+	if err != nil {
+		return SoftWipeResult{}, err
+	}
+	var ended []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return SoftWipeResult{}, err
+		}
+		ended = append(ended, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return SoftWipeResult{}, err
+	}
+	for _, id := range ended {
+		if err := archiveSeasonResults(ctx, tx, id); err != nil {
+			return SoftWipeResult{}, err
+		}
+	}
 
 	seasonName := strings.TrimSpace(req.SeasonName)
 	if seasonName == "" {
@@ -38,8 +62,17 @@ func SoftWipe(ctx context.Context, pool *pgxpool.Pool, req SoftWipeRequest) (Sof
 		seasonName = fmt.Sprintf("Season %d", next)
 	}
 
+	rules, err := encodeSeasonRules(req.Rules)
+	if err != nil {
+		return SoftWipeResult{}, err
+	}
+
 	var newID int
-	if err := tx.QueryRow(ctx, "INSERT INTO seasons(name, active, started_at) VALUES ($1,true,now()) RETURNING id", seasonName).Scan(&newID); err != nil {
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO seasons(name, active, started_at, ends_at, victory_net_worth, victory_planets, rules)
+		VALUES ($1,true,now(),$2,$3,$4,$5)
+		RETURNING id
+	`, seasonName, req.Schedule.endsAt(time.Now().UTC()), req.Schedule.VictoryNetWorth, req.Schedule.VictoryPlanets, rules).Scan(&newID); err != nil {
 		return SoftWipeResult{}, err
 	}
 
@@ -53,6 +86,9 @@ func SoftWipe(ctx context.Context, pool *pgxpool.Pool, req SoftWipeRequest) (Sof
 			cargo_ore = 0,
 			cargo_organics = 0,
 			cargo_equipment = 0,
+			cargo_colonists = 0,
+			cargo_contraband = 0,
+			ship_mines = 0,
 			last_turn_regen = now(),
 			season_id = $1
 	`, newID)
@@ -63,12 +99,18 @@ func SoftWipe(ctx context.Context, pool *pgxpool.Pool, req SoftWipeRequest) (Sof
 	// Fog-of-war and activity are per-season in practice.
 	_, _ = tx.Exec(ctx, "DELETE FROM player_discoveries")
 	_, _ = tx.Exec(ctx, "DELETE FROM player_sector_intel")
+	_, _ = tx.Exec(ctx, "DELETE FROM player_sector_hazards")
 	_, _ = tx.Exec(ctx, "DELETE FROM logs")
 	_, _ = tx.Exec(ctx, "DELETE FROM events")
+	_, _ = tx.Exec(ctx, "DELETE FROM bank_accounts")
+	_, _ = tx.Exec(ctx, "DELETE FROM fuel_purchases")
+	_, _ = tx.Exec(ctx, "DELETE FROM sector_beacons")
+	_, _ = tx.Exec(ctx, "DELETE FROM galaxy_news")
 
 	// Clear deployed assets.
 	_, _ = tx.Exec(ctx, "DELETE FROM mines")
-	_, _ = tx.Exec(ctx, "UPDATE planets SET owner_player_id=NULL, owner_corp_id=NULL, storage_ore=0, storage_organics=0, storage_equipment=0, citadel_level=0")
+	_, _ = tx.Exec(ctx, "DELETE FROM warp_gates")
+	_, _ = tx.Exec(ctx, "UPDATE planets SET owner_player_id=NULL, owner_corp_id=NULL, storage_ore=0, storage_organics=0, storage_equipment=0, citadel_level=0, shortage='', population=1000")
 
 	if req.ResetCorps {
 		_, _ = tx.Exec(ctx, "DELETE FROM corp_messages")
@@ -77,11 +119,16 @@ func SoftWipe(ctx context.Context, pool *pgxpool.Pool, req SoftWipeRequest) (Sof
 	} else {
 		_, _ = tx.Exec(ctx, "DELETE FROM corp_messages")
 		_, _ = tx.Exec(ctx, "UPDATE corporations SET credits=0")
+		_, _ = tx.Exec(ctx, "DELETE FROM corp_bank_transactions")
+		_, _ = tx.Exec(ctx, "DELETE FROM corp_withdrawal_requests")
+		_, _ = tx.Exec(ctx, "UPDATE corp_members SET deposit_balance=0")
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return SoftWipeResult{}, err
 	}
+	// The old snapshot belongs to the archived season; rebuild it now rather than on the next tick.
+	_ = refreshRankings(ctx, pool)
 
 	return SoftWipeResult{
 		NewSeasonID:   newID,
@@ -0,0 +1,575 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Overlays drawn on the admin SVG map; all are on unless the request names a subset.
+const (
+	MapOverlayPlayers      = "players"
+	MapOverlayPlanets      = "planets"
+	MapOverlayMines        = "mines"
+	MapOverlayEvents       = "events"
+	MapOverlayProtectorate = "protectorate"
+)
+
+var allMapOverlays = []string{MapOverlayPlayers, MapOverlayPlanets, MapOverlayMines, MapOverlayEvents, MapOverlayProtectorate}
+
+var ErrInvalidMapFilter = errors.New("invalid map filter")
+
+const (
+	svgLayoutIterations = 150
+	svgNodeSpacing      = 50.0 // ideal edge length in layout units
+	svgMargin           = 40.0
+)
+
+// AdminSVGMapOptions selects overlays and filters for GenerateAdminSVGMap.
+type AdminSVGMapOptions struct {
+	Seed     int64           // layout seed, normally UNIVERSE_SEED
+	Overlays map[string]bool // nil draws every overlay
+	Corp     string          // corp name or id; limits players, planets and mines to that corp
+	Region   string          // region name; limits the drawing to sectors in that region
+}
+
+// ParseMapOverlays parses a comma-separated overlay list. "" and "all" select every overlay.
+func ParseMapOverlays(s string) (map[string]bool, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" || s == "all" {
+		return nil, nil
+	}
+	out := map[string]bool{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || part == "none" {
+			continue
+		}
+		known := false
+		for _, o := range allMapOverlays {
+			if part == o {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown overlay %q", ErrInvalidMapFilter, part)
+		}
+		out[part] = true
+	}
+	return out, nil
+}
+
+type mapPoint struct{ X, Y float64 }
+
+type svgSector struct {
+	ID             int
+	Name           string
+	Region         string
+	IsProtectorate bool
+}
+
+type svgPlanet struct {
+	Name     string
+	CorpID   string
+	CorpName string
+	Owner    string // username when owned by a player outside a corp
+}
+
+type svgMarker struct {
+	Label  string
+	CorpID string
+}
+
+type svgMines struct {
+	Qty    int
+	CorpID string
+}
+
+// svgMapData is everything the renderer needs; loaded from the database by GenerateAdminSVGMap.
+type svgMapData struct {
+	Sectors []svgSector
+	Warps   [][2]int
+	Players map[int][]svgMarker
+	Planets map[int]svgPlanet
+	Mines   map[int][]svgMines
+	Events  map[int]string
+}
+
+// forceLayout places sectors with a Fruchterman-Reingold spring layout. It is deterministic for a
+// seed and graph: all iteration runs over sorted IDs and edges.
+func forceLayout(ids []int, warps [][2]int, seed int64) map[int]mapPoint {
+	n := len(ids)
+	out := make(map[int]mapPoint, n)
+	if n == 0 {
+		return out
+	}
+	ids = append([]int(nil), ids...)
+	sort.Ints(ids)
+	index := make(map[int]int, n)
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	// Undirected, de-duplicated edges.
+	seen := map[[2]int]bool{}
+	var edges [][2]int
+	for _, w := range warps {
+		a, okA := index[w[0]]
+		b, okB := index[w[1]]
+		if !okA || !okB || a == b {
+			continue
+		}
+		if a > b {
+			a, b = b, a
+		}
+		if !seen[[2]int{a, b}] {
+			seen[[2]int{a, b}] = true
+			edges = append(edges, [2]int{a, b})
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+
+	k := svgNodeSpacing
+	side := k * math.Sqrt(float64(n))
+	rng := rand.New(rand.NewSource(seed))
+	pos := make([]mapPoint, n)
+	for i := range pos {
+		pos[i] = mapPoint{rng.Float64() * side, rng.Float64() * side}
+	}
+	disp := make([]mapPoint, n)
+	temp := side / 10
+
+	for it := 0; it < svgLayoutIterations; it++ {
+		for i := range disp {
+			disp[i] = mapPoint{}
+		}
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				dx, dy := pos[i].X-pos[j].X, pos[i].Y-pos[j].Y
+				dist := math.Max(math.Hypot(dx, dy), 0.01)
+				f := k * k / dist
+				disp[i].X += dx / dist * f
+				disp[i].Y += dy / dist * f
+				disp[j].X -= dx / dist * f
+				disp[j].Y -= dy / dist * f
+			}
+		}
+		for _, e := range edges {
+			a, b := e[0], e[1]
+			dx, dy := pos[a].X-pos[b].X, pos[a].Y-pos[b].Y
+			dist := math.Max(math.Hypot(dx, dy), 0.01)
+			f := dist * dist / k
+			disp[a].X -= dx / dist * f
+			disp[a].Y -= dy / dist * f
+			disp[b].X += dx / dist * f
+			disp[b].Y += dy / dist * f
+		}
+		for i := range pos {
+			l := math.Hypot(disp[i].X, disp[i].Y)
+			if l > 0 {
+				step := math.Min(l, temp)
+				pos[i].X += disp[i].X / l * step
+				pos[i].Y += disp[i].Y / l * step
+			}
+			pos[i].X = math.Min(side, math.Max(0, pos[i].X))
+			pos[i].Y = math.Min(side, math.Max(0, pos[i].Y))
+		}
+		temp = side / 10 * (1 - float64(it+1)/svgLayoutIterations)
+	}
+
+	for i, id := range ids {
+		out[id] = pos[i]
+	}
+	return out
+}
+
+// The layout only depends on the seed and the warp graph, so it is computed once and reused until
+// the graph changes (e.g. after an admin expansion).
+type svgLayoutKey struct {
+	Seed        int64
+	Fingerprint uint64
+}
+
+var svgLayoutCache struct {
+	sync.Mutex
+	key    svgLayoutKey
+	layout map[int]mapPoint
+}
+
+func graphFingerprint(ids []int, warps [][2]int) uint64 {
+	h := fnv.New64a()
+	for _, id := range ids {
+		fmt.Fprintf(h, "s%d;", id)
+	}
+	for _, w := range warps {
+		fmt.Fprintf(h, "w%d-%d;", w[0], w[1])
+	}
+	return h.Sum64()
+}
+
+func cachedForceLayout(ids []int, warps [][2]int, seed int64) map[int]mapPoint {
+	key := svgLayoutKey{Seed: seed, Fingerprint: graphFingerprint(ids, warps)}
+	svgLayoutCache.Lock()
+	defer svgLayoutCache.Unlock()
+	if svgLayoutCache.layout != nil && svgLayoutCache.key == key {
+		return svgLayoutCache.layout
+	}
+	layout := forceLayout(ids, warps, seed)
+	svgLayoutCache.key = key
+	svgLayoutCache.layout = layout
+	return layout
+}
+
+// GenerateAdminSVGMap renders the warp graph as SVG with the requested overlays.
+func GenerateAdminSVGMap(ctx context.Context, pool *pgxpool.Pool, opts AdminSVGMapOptions) (string, error) {
+	data := svgMapData{
+		Players: map[int][]svgMarker{},
+		Planets: map[int]svgPlanet{},
+		Mines:   map[int][]svgMines{},
+		Events:  map[int]string{},
+	}
+
+	rows, err := pool.Query(ctx, `SELECT id, name, region, is_protectorate FROM sectors ORDER BY id`)
+	if err != nil {
+		return "", err
+	}
+	for rows.Next() {
+		var s svgSector
+		if err := rows.Scan(&s.ID, &s.Name, &s.Region, &s.IsProtectorate); err != nil {
+			rows.Close()
+			return "", err
+		}
+		data.Sectors = append(data.Sectors, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	warpRows, err := pool.Query(ctx, `SELECT from_sector, to_sector FROM warps ORDER BY from_sector, to_sector`)
+	if err != nil {
+		return "", err
+	}
+	for warpRows.Next() {
+		var w [2]int
+		if err := warpRows.Scan(&w[0], &w[1]); err != nil {
+			warpRows.Close()
+			return "", err
+		}
+		data.Warps = append(data.Warps, w)
+	}
+	warpRows.Close()
+	if err := warpRows.Err(); err != nil {
+		return "", err
+	}
+
+	corpID := ""
+	if c := strings.TrimSpace(opts.Corp); c != "" {
+		err := pool.QueryRow(ctx, `SELECT id FROM corporations WHERE id=$1 OR lower(name)=lower($1)`, c).Scan(&corpID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("%w: unknown corp %q", ErrInvalidMapFilter, c)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	playerRows, err := pool.Query(ctx, `
+		SELECT p.sector_id, u.username, COALESCE(cm.corp_id, '')
+		FROM players p
+		JOIN users u ON u.id = p.user_id
+		LEFT JOIN corp_members cm ON cm.player_id = p.id
+		ORDER BY p.sector_id, u.username
+	`)
+	if err != nil {
+		return "", err
+	}
+	for playerRows.Next() {
+		var sid int
+		var m svgMarker
+		if err := playerRows.Scan(&sid, &m.Label, &m.CorpID); err != nil {
+			playerRows.Close()
+			return "", err
+		}
+		data.Players[sid] = append(data.Players[sid], m)
+	}
+	playerRows.Close()
+	if err := playerRows.Err(); err != nil {
+		return "", err
+	}
+
+	planetRows, err := pool.Query(ctx, `
+		SELECT pl.sector_id, pl.name, COALESCE(c.id, ''), COALESCE(c.name, ''), COALESCE(u.username, '')
+		FROM planets pl
+		LEFT JOIN players p ON p.id = pl.owner_player_id
+		LEFT JOIN users u ON u.id = p.user_id
+		LEFT JOIN corp_members cm ON cm.player_id = pl.owner_player_id
+		LEFT JOIN corporations c ON c.id = COALESCE(pl.owner_corp_id, cm.corp_id)
+	`)
+	if err != nil {
+		return "", err
+	}
+	for planetRows.Next() {
+		var sid int
+		var pl svgPlanet
+		if err := planetRows.Scan(&sid, &pl.Name, &pl.CorpID, &pl.CorpName, &pl.Owner); err != nil {
+			planetRows.Close()
+			return "", err
+		}
+		data.Planets[sid] = pl
+	}
+	planetRows.Close()
+	if err := planetRows.Err(); err != nil {
+		return "", err
+	}
+
+	mineRows, err := pool.Query(ctx, `
+		SELECT m.sector_id, m.qty, COALESCE(m.owner_corp_id, cm.corp_id, '')
+		FROM mines m
+		LEFT JOIN corp_members cm ON cm.player_id = m.owner_player_id
+		WHERE m.qty > 0
+		ORDER BY m.sector_id
+	`)
+	if err != nil {
+		return "", err
+	}
+	for mineRows.Next() {
+		var sid int
+		var m svgMines
+		if err := mineRows.Scan(&sid, &m.Qty, &m.CorpID); err != nil {
+			mineRows.Close()
+			return "", err
+		}
+		data.Mines[sid] = append(data.Mines[sid], m)
+	}
+	mineRows.Close()
+	if err := mineRows.Err(); err != nil {
+		return "", err
+	}
+
+	eventRows, err := pool.Query(ctx, `SELECT sector_id, kind, title FROM events WHERE active=true AND ends_at > now()`)
+	if err != nil {
+		return "", err
+	}
+	for eventRows.Next() {
+		var sid int
+		var kind, title string
+		if err := eventRows.Scan(&sid, &kind, &title); err != nil {
+			eventRows.Close()
+			return "", err
+		}
+		data.Events[sid] = fmt.Sprintf("%s: %s", kind, title)
+	}
+	eventRows.Close()
+	if err := eventRows.Err(); err != nil {
+		return "", err
+	}
+
+	if r := strings.TrimSpace(opts.Region); r != "" {
+		found := false
+		for _, s := range data.Sectors {
+			if strings.EqualFold(s.Region, r) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("%w: unknown region %q", ErrInvalidMapFilter, r)
+		}
+	}
+
+	ids := make([]int, 0, len(data.Sectors))
+	for _, s := range data.Sectors {
+		ids = append(ids, s.ID)
+	}
+	layout := cachedForceLayout(ids, data.Warps, opts.Seed)
+	return renderSVGMap(data, layout, opts.Overlays, corpID, opts.Region), nil
+}
+
+// renderSVGMap draws the sectors passing the region filter at their layout positions. With a corp
+// filter only that corp's players, planets and mines are drawn.
+func renderSVGMap(data svgMapData, layout map[int]mapPoint, overlays map[string]bool, corpID, region string) string {
+	on := func(o string) bool { return overlays == nil || overlays[o] }
+	inCorp := func(id string) bool { return corpID == "" || id == corpID }
+
+	shown := map[int]svgSector{}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, s := range data.Sectors {
+		if region != "" && !strings.EqualFold(s.Region, region) {
+			continue
+		}
+		p, ok := layout[s.ID]
+		if !ok {
+			continue
+		}
+		shown[s.ID] = s
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	if len(shown) == 0 {
+		minX, minY, maxX, maxY = 0, 0, 0, 0
+	}
+	// Shift so the drawing starts at the margin, leaving room for the legend on top.
+	const legendHeight = 90.0
+	offX := svgMargin - minX
+	offY := svgMargin + legendHeight - minY
+	width := maxX - minX + 2*svgMargin
+	height := maxY - minY + 2*svgMargin + legendHeight
+	at := func(id int) (float64, float64) {
+		p := layout[id]
+		return p.X + offX, p.Y + offY
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="monospace" font-size="9">`+"\n",
+		math.Max(width, 320), height, math.Max(width, 320), height)
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="16" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="#8a93a6"/></marker></defs>` + "\n")
+	b.WriteString(`<rect width="100%" height="100%" fill="#0b0e17"/>` + "\n")
+
+	// Legend.
+	title := "Sovereign Conquest Universe Map (Admin)"
+	if region != "" {
+		title += " - region " + region
+	}
+	fmt.Fprintf(&b, `<text x="10" y="18" fill="#e6e6e6" font-size="12">%s</text>`+"\n", html.EscapeString(title))
+	fmt.Fprintf(&b, `<text x="10" y="34" fill="#8a93a6">%d sectors shown</text>`+"\n", len(shown))
+	legend := []struct{ color, label, overlay string }{
+		{"#3fbf6f", "Protectorate", MapOverlayProtectorate},
+		{"#f2c744", "player", MapOverlayPlayers},
+		{"#ffffff", "planet (corp color)", MapOverlayPlanets},
+		{"#e0483e", "minefield", MapOverlayMines},
+		{"#ff8c1a", "event", MapOverlayEvents},
+	}
+	lx := 10.0
+	for _, l := range legend {
+		if !on(l.overlay) {
+			continue
+		}
+		fmt.Fprintf(&b, `<circle cx="%.0f" cy="52" r="5" fill="%s"/><text x="%.0f" y="55" fill="#c8c8c8">%s</text>`+"\n", lx+5, l.color, lx+14, l.label)
+		lx += 24 + float64(len(l.label))*5.5
+	}
+
+	// Warps. Two-way lanes are drawn once; one-way lanes get an arrow.
+	known := map[[2]int]bool{}
+	for _, w := range data.Warps {
+		known[w] = true
+	}
+	b.WriteString(`<g stroke="#3a4256" stroke-width="1">` + "\n")
+	for _, w := range data.Warps {
+		if _, ok := shown[w[0]]; !ok {
+			continue
+		}
+		if _, ok := shown[w[1]]; !ok {
+			continue
+		}
+		twoWay := known[[2]int{w[1], w[0]}]
+		if twoWay && w[0] > w[1] {
+			continue
+		}
+		x1, y1 := at(w[0])
+		x2, y2 := at(w[1])
+		marker := ""
+		if !twoWay {
+			marker = ` marker-end="url(#arrow)" stroke-dasharray="4 2"`
+		}
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f"%s/>`+"\n", x1, y1, x2, y2, marker)
+	}
+	b.WriteString("</g>\n")
+
+	ids := make([]int, 0, len(shown))
+	for id := range shown {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		s := shown[id]
+		x, y := at(id)
+		fill := "#5c6b8a"
+		if on(MapOverlayProtectorate) && s.IsProtectorate {
+			fill = "#3fbf6f"
+		}
+		tip := fmt.Sprintf("%d %s", s.ID, s.Name)
+		if s.Region != "" {
+			tip += " (" + s.Region + ")"
+		}
+
+		if on(MapOverlayEvents) {
+			if ev, ok := data.Events[id]; ok {
+				fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="13" fill="none" stroke="#ff8c1a" stroke-width="2"><title>%s</title></circle>`+"\n", x, y, html.EscapeString(ev))
+			}
+		}
+		if on(MapOverlayMines) {
+			total := 0
+			for _, m := range data.Mines[id] {
+				if inCorp(m.CorpID) {
+					total += m.Qty
+				}
+			}
+			if total > 0 {
+				w := 1 + math.Log10(float64(total))
+				fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="10" fill="none" stroke="#e0483e" stroke-width="%.1f"><title>%d mines</title></circle>`+"\n", x, y, w, total)
+			}
+		}
+
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="6" fill="%s"><title>%s</title></circle>`+"\n", x, y, fill, html.EscapeString(tip))
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" fill="#9aa4b8" text-anchor="middle">%d</text>`+"\n", x, y+16, id)
+
+		if on(MapOverlayPlanets) {
+			if pl, ok := data.Planets[id]; ok && (corpID == "" || pl.CorpID == corpID) {
+				color, owner := "#ffffff", "unowned"
+				switch {
+				case pl.CorpID != "":
+					color, owner = corpColor(pl.CorpID), pl.CorpName
+				case pl.Owner != "":
+					color, owner = "#b0b0b0", pl.Owner
+				}
+				fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="6" height="6" fill="%s"><title>%s</title></rect>`+"\n",
+					x+5, y-11, color, html.EscapeString(fmt.Sprintf("Planet %s (%s)", pl.Name, owner)))
+			}
+		}
+		if on(MapOverlayPlayers) {
+			var names []string
+			for _, p := range data.Players[id] {
+				if inCorp(p.CorpID) {
+					names = append(names, p.Label)
+				}
+			}
+			if len(names) > 0 {
+				label := names[0]
+				if len(names) > 1 {
+					label = fmt.Sprintf("%s +%d", names[0], len(names)-1)
+				}
+				fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="#f2c744"><title>%s</title></circle>`+"\n", x-7, y-7, html.EscapeString(strings.Join(names, ", ")))
+				fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" fill="#f2c744" text-anchor="end">%s</text>`+"\n", x-11, y-9, html.EscapeString(label))
+			}
+		}
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// corpColor gives each corporation a stable, distinct hue.
+func corpColor(corpID string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(corpID))
+	return fmt.Sprintf("hsl(%d,70%%,60%%)", h.Sum32()%360)
+}
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestForceLayoutDeterministic(t *testing.T) {
+	ids := []int{1, 2, 3, 4, 5, 6}
+	warps := [][2]int{{1, 2}, {2, 1}, {2, 3}, {3, 4}, {4, 5}, {5, 6}, {6, 1}}
+
+	a := forceLayout(ids, warps, 7)
+	b := forceLayout([]int{6, 5, 4, 3, 2, 1}, warps, 7)
+	for _, id := range ids {
+		if a[id] != b[id] {
+			t.Fatalf("sector %d: %v != %v", id, a[id], b[id])
+		}
+		if math.IsNaN(a[id].X) || math.IsNaN(a[id].Y) {
+			t.Fatalf("sector %d: NaN position", id)
+		}
+	}
+	// Linked sectors should end up closer than the ring's far side.
+	d := func(x, y int) float64 { return math.Hypot(a[x].X-a[y].X, a[x].Y-a[y].Y) }
+	if d(1, 2) >= d(1, 4) {
+		t.Fatalf("expected neighbors closer: d(1,2)=%.1f d(1,4)=%.1f", d(1, 2), d(1, 4))
+	}
+}
+
+func TestParseMapOverlays(t *testing.T) {
+	if o, err := ParseMapOverlays(""); err != nil || o != nil {
+		t.Fatalf("default: %v %v", o, err)
+	}
+	o, err := ParseMapOverlays("Players, mines")
+	if err != nil || !o[MapOverlayPlayers] || !o[MapOverlayMines] || o[MapOverlayEvents] {
+		t.Fatalf("subset: %v %v", o, err)
+	}
+	if _, err := ParseMapOverlays("weather"); err == nil {
+		t.Fatalf("expected error for unknown overlay")
+	}
+}
+
+func TestRenderSVGMapFilters(t *testing.T) {
+	data := svgMapData{
+		Sectors: []svgSector{{ID: 1, Region: "North"}, {ID: 2, Region: "North"}, {ID: 3, Region: "South"}},
+		Warps:   [][2]int{{1, 2}, {2, 3}},
+		Players: map[int][]svgMarker{1: {{Label: "alice", CorpID: "c1"}}, 2: {{Label: "bob", CorpID: "c2"}}},
+		Planets: map[int]svgPlanet{},
+		Mines:   map[int][]svgMines{},
+		Events:  map[int]string{},
+	}
+	layout := map[int]mapPoint{1: {0, 0}, 2: {50, 0}, 3: {100, 0}}
+
+	svg := renderSVGMap(data, layout, nil, "c1", "north")
+	if !strings.Contains(svg, "alice") || strings.Contains(svg, "bob") {
+		t.Fatalf("corp filter not applied:\n%s", svg)
+	}
+	if !strings.Contains(svg, "2 sectors shown") {
+		t.Fatalf("region filter not applied:\n%s", svg)
+	}
+	if !strings.Contains(svg, `marker-end="url(#arrow)"`) {
+		t.Fatalf("one-way warp should be drawn with an arrow:\n%s", svg)
+	}
+	if strings.Count(svg, "<line ") != 1 {
+		t.Fatalf("expected only the in-region warp:\n%s", svg)
+	}
+}
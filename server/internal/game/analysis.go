@@ -0,0 +1,630 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	analysisTopTradePairs = 10
+	analysisListLimit     = 20
+)
+
+// UniverseAnalysis is an admin report on the shape and economy of the generated universe.
+type UniverseAnalysis struct {
+	Sectors      int     `json:"sectors"`
+	Warps        int     `json:"warps"`
+	OneWayWarps  int     `json:"one_way_warps"`
+	AvgOutDegree float64 `json:"avg_out_degree"`
+
+	SCCCount       int   `json:"scc_count"`
+	SCCSizes       []int `json:"scc_sizes"` // largest first
+	Diameter       int   `json:"diameter"`  // longest shortest path inside the largest SCC
+	DiameterFrom   int   `json:"diameter_from"`
+	DiameterTo     int   `json:"diameter_to"`
+	DeadEnds       []int `json:"dead_ends"` // sectors with a single neighbor
+	DeadEndCount   int   `json:"dead_end_count"`
+	Unreachable    []int `json:"unreachable_from_protectorate"`
+	CannotReachHub []int `json:"cannot_reach_protectorate"`
+
+	Protectorate ProtectorateDistanceStats `json:"protectorate_distance"`
+	Ports        PortStats                 `json:"ports"`
+	TopTrades    []TradePair               `json:"top_trade_pairs"`
+	Planets      PlanetStats               `json:"planets"`
+}
+
+// ProtectorateDistanceStats describes how many moves sectors are from the nearest Protectorate sector.
+type ProtectorateDistanceStats struct {
+	Sectors   int         `json:"protectorate_sectors"`
+	Max       int         `json:"max"`
+	Avg       float64     `json:"avg"`
+	Histogram map[int]int `json:"histogram"` // distance -> sector count
+}
+
+type PortStats struct {
+	Count       int                       `json:"count"`
+	Modes       map[string]map[string]int `json:"modes"` // commodity -> BUY/SELL -> count
+	PriceSpread map[string]PriceSpread    `json:"price_spread"`
+}
+
+type PriceSpread struct {
+	Min int     `json:"min"`
+	Max int     `json:"max"`
+	Avg float64 `json:"avg"`
+}
+
+// TradePair is a buy-low/sell-high pair ranked by margin per move.
+type TradePair struct {
+	Commodity    string `json:"commodity"`
+	BuySectorID  int    `json:"buy_sector_id"`
+	BuyPrice     int    `json:"buy_price"`
+	SellSectorID int    `json:"sell_sector_id"`
+	SellPrice    int    `json:"sell_price"`
+	Margin       int    `json:"margin"`
+	Moves        int    `json:"moves"`
+}
+
+// PlanetStats counts planets and who holds them, overall and per region.
+type PlanetStats struct {
+	Count              int                 `json:"count"`
+	Owned              int                 `json:"owned"`
+	CorpOwned          int                 `json:"corp_owned"`
+	SectorsWithPlanets int                 `json:"sectors_with_planets"`
+	MaxPerSector       int                 `json:"max_per_sector"`
+	MaxPerSectorID     int                 `json:"max_per_sector_id"`
+	ByClass            map[string]int      `json:"by_class"`
+	Regions            []RegionPlanetStats `json:"regions"` // by region name
+}
+
+type RegionPlanetStats struct {
+	Region          string `json:"region"`
+	Sectors         int    `json:"sectors"`
+	Planets         int    `json:"planets"`
+	Owned           int    `json:"owned"`
+	Owners          int    `json:"owners"`
+	TopOwner        string `json:"top_owner,omitempty"`
+	TopOwnerPlanets int    `json:"top_owner_planets,omitempty"`
+}
+
+type planetListing struct {
+	SectorID  int
+	Class     string
+	Owner     string // corp name for corp planets, else the owner's username; "" when unowned
+	CorpOwned bool
+}
+
+type portListing struct {
+	SectorID  int
+	Commodity string
+	Mode      string
+	Price     int
+}
+
+// LoadUniverseAnalysis reads the universe from the database and analyzes it.
+func LoadUniverseAnalysis(ctx context.Context, pool *pgxpool.Pool) (UniverseAnalysis, error) {
+	rows, err := pool.Query(ctx, "SELECT id, is_protectorate, region FROM sectors ORDER BY id")
+	if err != nil {
+		return UniverseAnalysis{}, err
+	}
+	var sectors []int
+	prot := map[int]bool{}
+	regions := map[int]string{}
+	for rows.Next() {
+		var id int
+		var isProt bool
+		var region string
+		if err := rows.Scan(&id, &isProt, &region); err != nil {
+			rows.Close()
+			return UniverseAnalysis{}, err
+		}
+		sectors = append(sectors, id)
+		regions[id] = region
+		if isProt {
+			prot[id] = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return UniverseAnalysis{}, err
+	}
+
+	wrows, err := pool.Query(ctx, "SELECT from_sector, to_sector, one_way FROM warps ORDER BY from_sector, to_sector")
+	if err != nil {
+		return UniverseAnalysis{}, err
+	}
+	var warps []Warp
+	for wrows.Next() {
+		var w Warp
+		if err := wrows.Scan(&w.From, &w.To, &w.OneWay); err != nil {
+			wrows.Close()
+			return UniverseAnalysis{}, err
+		}
+		warps = append(warps, w)
+	}
+	wrows.Close()
+	if err := wrows.Err(); err != nil {
+		return UniverseAnalysis{}, err
+	}
+
+	prows, err := pool.Query(ctx, `
+		SELECT sector_id,
+			ore_mode, ore_base_price, ore_base_qty, ore_qty,
+			organics_mode, organics_base_price, organics_base_qty, organics_qty,
+			equipment_mode, equipment_base_price, equipment_base_qty, equipment_qty
+		FROM ports
+		ORDER BY sector_id
+	`)
+	if err != nil {
+		return UniverseAnalysis{}, err
+	}
+	var listings []portListing
+	for prows.Next() {
+		var sid int
+		var modes [3]string
+		var basePrice, baseQty, qty [3]int
+		if err := prows.Scan(&sid,
+			&modes[0], &basePrice[0], &baseQty[0], &qty[0],
+			&modes[1], &basePrice[1], &baseQty[1], &qty[1],
+			&modes[2], &basePrice[2], &baseQty[2], &qty[2],
+		); err != nil {
+			prows.Close()
+			return UniverseAnalysis{}, err
+		}
+		for i, c := range []string{"ORE", "ORGANICS", "EQUIPMENT"} {
+			listings = append(listings, portListing{
+				SectorID:  sid,
+				Commodity: c,
+				Mode:      strings.ToUpper(modes[i]),
+				Price:     PricePerUnit(basePrice[i], baseQty[i], qty[i]),
+			})
+		}
+	}
+	prows.Close()
+	if err := prows.Err(); err != nil {
+		return UniverseAnalysis{}, err
+	}
+
+	plrows, err := pool.Query(ctx, `
+		SELECT pl.sector_id, pl.planet_class, COALESCE(c.name, u.username, ''), pl.owner_corp_id IS NOT NULL
+		FROM planets pl
+		LEFT JOIN corporations c ON c.id = pl.owner_corp_id
+		LEFT JOIN players p ON p.id = pl.owner_player_id
+		LEFT JOIN users u ON u.id = p.user_id
+		ORDER BY pl.id
+	`)
+	if err != nil {
+		return UniverseAnalysis{}, err
+	}
+	var planets []planetListing
+	for plrows.Next() {
+		var pl planetListing
+		if err := plrows.Scan(&pl.SectorID, &pl.Class, &pl.Owner, &pl.CorpOwned); err != nil {
+			plrows.Close()
+			return UniverseAnalysis{}, err
+		}
+		planets = append(planets, pl)
+	}
+	plrows.Close()
+	if err := plrows.Err(); err != nil {
+		return UniverseAnalysis{}, err
+	}
+
+	a := AnalyzeUniverse(sectors, prot, warps, listings)
+	a.Planets = analyzePlanets(regions, planets)
+	return a, nil
+}
+
+// AnalyzeUniverse computes graph and economy statistics for a universe.
+func AnalyzeUniverse(sectors []int, prot map[int]bool, warps []Warp, listings []portListing) UniverseAnalysis {
+	a := UniverseAnalysis{Sectors: len(sectors), Warps: len(warps)}
+
+	adj := map[int][]int{}
+	radj := map[int][]int{}
+	neighbors := map[int]map[int]bool{}
+	for _, id := range sectors {
+		neighbors[id] = map[int]bool{}
+	}
+	for _, w := range warps {
+		adj[w.From] = append(adj[w.From], w.To)
+		radj[w.To] = append(radj[w.To], w.From)
+		if w.OneWay {
+			a.OneWayWarps++
+		}
+		if neighbors[w.From] != nil {
+			neighbors[w.From][w.To] = true
+		}
+		if neighbors[w.To] != nil {
+			neighbors[w.To][w.From] = true
+		}
+	}
+	if len(sectors) > 0 {
+		a.AvgOutDegree = float64(len(warps)) / float64(len(sectors))
+	}
+
+	for _, id := range sectors {
+		if len(neighbors[id]) <= 1 {
+			a.DeadEnds = append(a.DeadEnds, id)
+		}
+	}
+	a.DeadEndCount = len(a.DeadEnds)
+	a.DeadEnds = truncateInts(a.DeadEnds, analysisListLimit)
+
+	comps := stronglyConnected(sectors, adj, radj)
+	a.SCCCount = len(comps)
+	for _, c := range comps {
+		a.SCCSizes = append(a.SCCSizes, len(c))
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(a.SCCSizes)))
+	a.SCCSizes = truncateInts(a.SCCSizes, analysisListLimit)
+	if len(comps) > 0 {
+		largest := comps[0]
+		for _, c := range comps[1:] {
+			if len(c) > len(largest) {
+				largest = c
+			}
+		}
+		a.Diameter, a.DiameterFrom, a.DiameterTo = diameterWithin(largest, adj)
+	}
+
+	// Distance to nearest Protectorate sector: BFS backwards from Protectorate space.
+	a.Protectorate = ProtectorateDistanceStats{Sectors: len(prot), Histogram: map[int]int{}}
+	toHub := multiSourceBFS(prot, radj)
+	fromHub := multiSourceBFS(prot, adj)
+	total := 0
+	for _, id := range sectors {
+		d, ok := toHub[id]
+		if !ok {
+			a.CannotReachHub = append(a.CannotReachHub, id)
+			continue
+		}
+		a.Protectorate.Histogram[d]++
+		total += d
+		if d > a.Protectorate.Max {
+			a.Protectorate.Max = d
+		}
+		if _, ok := fromHub[id]; !ok {
+			a.Unreachable = append(a.Unreachable, id)
+		}
+	}
+	if reached := len(sectors) - len(a.CannotReachHub); reached > 0 {
+		a.Protectorate.Avg = float64(total) / float64(reached)
+	}
+	a.CannotReachHub = truncateInts(a.CannotReachHub, analysisListLimit)
+	a.Unreachable = truncateInts(a.Unreachable, analysisListLimit)
+
+	a.Ports, a.TopTrades = analyzePorts(listings, adj)
+	return a
+}
+
+func analyzePorts(listings []portListing, adj map[int][]int) (PortStats, []TradePair) {
+	stats := PortStats{Modes: map[string]map[string]int{}, PriceSpread: map[string]PriceSpread{}}
+	portSectors := map[int]bool{}
+	sums := map[string]int{}
+	counts := map[string]int{}
+	sellers := map[string][]portListing{} // port SELLs: the player buys here
+	buyers := map[string][]portListing{}
+	for _, l := range listings {
+		portSectors[l.SectorID] = true
+		if stats.Modes[l.Commodity] == nil {
+			stats.Modes[l.Commodity] = map[string]int{}
+		}
+		stats.Modes[l.Commodity][l.Mode]++
+
+		ps, seen := stats.PriceSpread[l.Commodity]
+		if !seen || l.Price < ps.Min {
+			ps.Min = l.Price
+		}
+		if l.Price > ps.Max {
+			ps.Max = l.Price
+		}
+		stats.PriceSpread[l.Commodity] = ps
+		sums[l.Commodity] += l.Price
+		counts[l.Commodity]++
+
+		switch l.Mode {
+		case "SELL":
+			sellers[l.Commodity] = append(sellers[l.Commodity], l)
+		case "BUY":
+			buyers[l.Commodity] = append(buyers[l.Commodity], l)
+		}
+	}
+	stats.Count = len(portSectors)
+	for c, ps := range stats.PriceSpread {
+		ps.Avg = float64(sums[c]) / float64(counts[c])
+		stats.PriceSpread[c] = ps
+	}
+
+	var pairs []TradePair
+	dist := map[int]map[int]int{}
+	for _, c := range []string{"ORE", "ORGANICS", "EQUIPMENT"} {
+		for _, s := range sellers[c] {
+			if dist[s.SectorID] == nil {
+				dist[s.SectorID] = multiSourceBFS(map[int]bool{s.SectorID: true}, adj)
+			}
+			for _, b := range buyers[c] {
+				moves, ok := dist[s.SectorID][b.SectorID]
+				if !ok || moves == 0 || b.Price <= s.Price {
+					continue
+				}
+				pairs = append(pairs, TradePair{
+					Commodity: c, BuySectorID: s.SectorID, BuyPrice: s.Price,
+					SellSectorID: b.SectorID, SellPrice: b.Price,
+					Margin: b.Price - s.Price, Moves: moves,
+				})
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		// Rank by margin per move, then raw margin, then sector IDs for determinism.
+		li, lj := pairs[i].Margin*pairs[j].Moves, pairs[j].Margin*pairs[i].Moves
+		if li != lj {
+			return li > lj
+		}
+		if pairs[i].Margin != pairs[j].Margin {
+			return pairs[i].Margin > pairs[j].Margin
+		}
+		if pairs[i].BuySectorID != pairs[j].BuySectorID {
+			return pairs[i].BuySectorID < pairs[j].BuySectorID
+		}
+		return pairs[i].SellSectorID < pairs[j].SellSectorID
+	})
+	if len(pairs) > analysisTopTradePairs {
+		pairs = pairs[:analysisTopTradePairs]
+	}
+	return stats, pairs
+}
+
+// analyzePlanets counts planets by class and ownership, overall and per region. regions maps
+// sector IDs to region names; sectors without a region are grouped under "(none)".
+func analyzePlanets(regions map[int]string, planets []planetListing) PlanetStats {
+	stats := PlanetStats{Count: len(planets), ByClass: map[string]int{}}
+	regionName := func(sectorID int) string {
+		if r := regions[sectorID]; r != "" {
+			return r
+		}
+		return "(none)"
+	}
+
+	byRegion := map[string]*RegionPlanetStats{}
+	region := func(name string) *RegionPlanetStats {
+		rs := byRegion[name]
+		if rs == nil {
+			rs = &RegionPlanetStats{Region: name}
+			byRegion[name] = rs
+		}
+		return rs
+	}
+	for id := range regions {
+		region(regionName(id)).Sectors++
+	}
+
+	perSector := map[int]int{}
+	owners := map[string]map[string]int{} // region -> owner -> planets
+	for _, pl := range planets {
+		stats.ByClass[pl.Class]++
+		perSector[pl.SectorID]++
+		name := regionName(pl.SectorID)
+		rs := region(name)
+		rs.Planets++
+		if pl.Owner == "" {
+			continue
+		}
+		stats.Owned++
+		if pl.CorpOwned {
+			stats.CorpOwned++
+		}
+		rs.Owned++
+		if owners[name] == nil {
+			owners[name] = map[string]int{}
+		}
+		owners[name][pl.Owner]++
+	}
+
+	stats.SectorsWithPlanets = len(perSector)
+	for id, n := range perSector {
+		if n > stats.MaxPerSector || (n == stats.MaxPerSector && id < stats.MaxPerSectorID) {
+			stats.MaxPerSector, stats.MaxPerSectorID = n, id
+		}
+	}
+
+	for name, rs := range byRegion {
+		rs.Owners = len(owners[name])
+		for owner, n := range owners[name] {
+			if n > rs.TopOwnerPlanets || (n == rs.TopOwnerPlanets && owner < rs.TopOwner) {
+				rs.TopOwner, rs.TopOwnerPlanets = owner, n
+			}
+		}
+		stats.Regions = append(stats.Regions, *rs)
+	}
+	sort.Slice(stats.Regions, func(i, j int) bool { return stats.Regions[i].Region < stats.Regions[j].Region })
+	return stats
+}
+
+// stronglyConnected returns the strongly connected components (Kosaraju, iterative).
+func stronglyConnected(sectors []int, adj, radj map[int][]int) [][]int {
+	visited := map[int]bool{}
+	order := make([]int, 0, len(sectors))
+	for _, start := range sectors {
+		if visited[start] {
+			continue
+		}
+		type frame struct{ node, next int }
+		stack := []frame{{node: start}}
+		visited[start] = true
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.next < len(adj[top.node]) {
+				n := adj[top.node][top.next]
+				top.next++
+				if !visited[n] {
+					visited[n] = true
+					stack = append(stack, frame{node: n})
+				}
+				continue
+			}
+			order = append(order, top.node)
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	assigned := map[int]bool{}
+	var comps [][]int
+	for i := len(order) - 1; i >= 0; i-- {
+		root := order[i]
+		if assigned[root] {
+			continue
+		}
+		comp := []int{}
+		stack := []int{root}
+		assigned[root] = true
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			comp = append(comp, n)
+			for _, m := range radj[n] {
+				if !assigned[m] {
+					assigned[m] = true
+					stack = append(stack, m)
+				}
+			}
+		}
+		sort.Ints(comp)
+		comps = append(comps, comp)
+	}
+	return comps
+}
+
+// multiSourceBFS returns hop counts from any source sector following adj.
+func multiSourceBFS(sources map[int]bool, adj map[int][]int) map[int]int {
+	dist := make(map[int]int, len(sources))
+	queue := make([]int, 0, len(sources))
+	for id := range sources {
+		dist[id] = 0
+		queue = append(queue, id)
+	}
+	sort.Ints(queue)
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range adj[cur] {
+			if _, ok := dist[next]; ok {
+				continue
+			}
+			dist[next] = dist[cur] + 1
+			queue = append(queue, next)
+		}
+	}
+	return dist
+}
+
+// diameterWithin is the longest shortest path between two sectors of comp.
+func diameterWithin(comp []int, adj map[int][]int) (int, int, int) {
+	in := make(map[int]bool, len(comp))
+	for _, id := range comp {
+		in[id] = true
+	}
+	best, bestFrom, bestTo := 0, 0, 0
+	for _, src := range comp {
+		dist := multiSourceBFS(map[int]bool{src: true}, adj)
+		for _, dst := range comp {
+			if d, ok := dist[dst]; ok && in[dst] && d > best {
+				best, bestFrom, bestTo = d, src, dst
+			}
+		}
+	}
+	return best, bestFrom, bestTo
+}
+
+func truncateInts(xs []int, n int) []int {
+	if len(xs) > n {
+		return xs[:n]
+	}
+	return xs
+}
+
+// Report renders the analysis as a plain-text report.
+func (a UniverseAnalysis) Report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Universe analysis\n")
+	fmt.Fprintf(&b, "Sectors: %d  Warps: %d (%d one-way)  Avg out-degree: %.2f\n", a.Sectors, a.Warps, a.OneWayWarps, a.AvgOutDegree)
+	fmt.Fprintf(&b, "Strongly connected components: %d (sizes %s)\n", a.SCCCount, joinInts(a.SCCSizes))
+	fmt.Fprintf(&b, "Diameter (largest SCC): %d moves (%d -> %d)\n", a.Diameter, a.DiameterFrom, a.DiameterTo)
+	fmt.Fprintf(&b, "Dead ends: %d %s\n", a.DeadEndCount, bracketInts(a.DeadEnds))
+
+	b.WriteString("\nProtectorate access\n")
+	fmt.Fprintf(&b, "Protectorate sectors: %d  Max distance: %d  Avg distance: %.2f\n", a.Protectorate.Sectors, a.Protectorate.Max, a.Protectorate.Avg)
+	dists := make([]int, 0, len(a.Protectorate.Histogram))
+	for d := range a.Protectorate.Histogram {
+		dists = append(dists, d)
+	}
+	sort.Ints(dists)
+	for _, d := range dists {
+		fmt.Fprintf(&b, "  %2d moves: %d sector(s)\n", d, a.Protectorate.Histogram[d])
+	}
+	if len(a.CannotReachHub) > 0 {
+		fmt.Fprintf(&b, "Cannot reach Protectorate space: %s\n", joinInts(a.CannotReachHub))
+	}
+	if len(a.Unreachable) > 0 {
+		fmt.Fprintf(&b, "Unreachable from Protectorate space: %s\n", joinInts(a.Unreachable))
+	}
+
+	b.WriteString("\nPorts\n")
+	fmt.Fprintf(&b, "Ports: %d\n", a.Ports.Count)
+	for _, c := range []string{"ORE", "ORGANICS", "EQUIPMENT"} {
+		ps := a.Ports.PriceSpread[c]
+		m := a.Ports.Modes[c]
+		fmt.Fprintf(&b, "  %-9s buy=%d sell=%d  price %d..%d (avg %.1f)\n", c, m["BUY"], m["SELL"], ps.Min, ps.Max, ps.Avg)
+	}
+
+	b.WriteString("\nPlanets\n")
+	pst := a.Planets
+	fmt.Fprintf(&b, "Planets: %d  Owned: %d (%d by corps)  Unowned: %d\n", pst.Count, pst.Owned, pst.CorpOwned, pst.Count-pst.Owned)
+	fmt.Fprintf(&b, "Sectors with planets: %d  Most in one sector: %d (sector %d)\n", pst.SectorsWithPlanets, pst.MaxPerSector, pst.MaxPerSectorID)
+	classes := make([]string, 0, len(pst.ByClass))
+	for c := range pst.ByClass {
+		classes = append(classes, c)
+	}
+	sort.Strings(classes)
+	for _, c := range classes {
+		fmt.Fprintf(&b, "  class %s: %d\n", c, pst.ByClass[c])
+	}
+	for _, r := range pst.Regions {
+		if r.Planets == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s: %d planet(s) in %d sector(s), %d owned by %d owner(s)", r.Region, r.Planets, r.Sectors, r.Owned, r.Owners)
+		if r.TopOwner != "" {
+			fmt.Fprintf(&b, ", most by %s (%d)", r.TopOwner, r.TopOwnerPlanets)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nTop trade pairs (margin per move)\n")
+	if len(a.TopTrades) == 0 {
+		b.WriteString("  none\n")
+	}
+	for i, t := range a.TopTrades {
+		fmt.Fprintf(&b, "  %2d. %-9s buy @%d in %d -> sell @%d in %d: +%d/unit over %d move(s)\n",
+			i+1, t.Commodity, t.BuyPrice, t.BuySectorID, t.SellPrice, t.SellSectorID, t.Margin, t.Moves)
+	}
+	return b.String()
+}
+
+func joinInts(xs []int) string {
+	parts := make([]string, 0, len(xs))
+	for _, x := range xs {
+		parts = append(parts, fmt.Sprintf("%d", x))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func bracketInts(xs []int) string {
+	if len(xs) == 0 {
+		return ""
+	}
+	return "[" + joinInts(xs) + "]"
+}
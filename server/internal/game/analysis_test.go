@@ -0,0 +1,104 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeUniverseGraph(t *testing.T) {
+	// 1 <-> 2 <-> 3 form the core (1 is Protectorate), 3 -> 4 is a one-way trap, 5 hangs off 1.
+	warps := []Warp{
+		{From: 1, To: 2}, {From: 2, To: 1},
+		{From: 2, To: 3}, {From: 3, To: 2},
+		{From: 3, To: 4, OneWay: true},
+		{From: 1, To: 5}, {From: 5, To: 1},
+	}
+	a := AnalyzeUniverse([]int{1, 2, 3, 4, 5}, map[int]bool{1: true}, warps, nil)
+
+	if a.OneWayWarps != 1 || a.Warps != 7 {
+		t.Fatalf("unexpected warp counts %d/%d", a.Warps, a.OneWayWarps)
+	}
+	if a.SCCCount != 2 || a.SCCSizes[0] != 4 || a.SCCSizes[1] != 1 {
+		t.Fatalf("expected SCCs [4 1], got %d %v", a.SCCCount, a.SCCSizes)
+	}
+	// Longest shortest path in {1,2,3,5}: 3 -> 5 or 5 -> 3.
+	if a.Diameter != 3 {
+		t.Fatalf("expected diameter 3, got %d", a.Diameter)
+	}
+	if a.DeadEndCount != 2 {
+		t.Fatalf("expected dead ends 4 and 5, got %v", a.DeadEnds)
+	}
+	if len(a.CannotReachHub) != 1 || a.CannotReachHub[0] != 4 {
+		t.Fatalf("expected sector 4 unable to reach Protectorate space, got %v", a.CannotReachHub)
+	}
+	if a.Protectorate.Max != 2 || a.Protectorate.Histogram[1] != 2 {
+		t.Fatalf("unexpected Protectorate distances %+v", a.Protectorate)
+	}
+}
+
+func TestAnalyzeUniverseTradePairs(t *testing.T) {
+	warps := []Warp{
+		{From: 1, To: 2}, {From: 2, To: 1},
+		{From: 2, To: 3}, {From: 3, To: 2},
+	}
+	listings := []portListing{
+		{SectorID: 1, Commodity: "ORE", Mode: "SELL", Price: 10},
+		{SectorID: 2, Commodity: "ORE", Mode: "BUY", Price: 14},
+		{SectorID: 3, Commodity: "ORE", Mode: "BUY", Price: 20},
+		{SectorID: 3, Commodity: "ORGANICS", Mode: "SELL", Price: 30},
+		{SectorID: 1, Commodity: "ORGANICS", Mode: "BUY", Price: 25},
+	}
+	a := AnalyzeUniverse([]int{1, 2, 3}, map[int]bool{1: true}, warps, listings)
+
+	if a.Ports.Count != 3 || a.Ports.Modes["ORE"]["BUY"] != 2 {
+		t.Fatalf("unexpected port stats %+v", a.Ports)
+	}
+	if ps := a.Ports.PriceSpread["ORE"]; ps.Min != 10 || ps.Max != 20 {
+		t.Fatalf("unexpected ORE spread %+v", ps)
+	}
+	// 1->3 earns 10 over 2 moves, 1->2 earns 4 over 1; organics never profit.
+	if len(a.TopTrades) != 2 || a.TopTrades[0].SellSectorID != 3 || a.TopTrades[1].SellSectorID != 2 {
+		t.Fatalf("unexpected trade pairs %+v", a.TopTrades)
+	}
+	if !strings.Contains(a.Report(), "Top trade pairs") {
+		t.Fatalf("report is missing the trade section")
+	}
+}
+
+func TestAnalyzePlanets(t *testing.T) {
+	regions := map[int]string{1: "The Core", 2: "The Core", 3: "The Rim", 4: ""}
+	planets := []planetListing{
+		{SectorID: 1, Class: "M", Owner: "alice"},
+		{SectorID: 1, Class: "D", Owner: "Nova", CorpOwned: true},
+		{SectorID: 2, Class: "M"},
+		{SectorID: 3, Class: "O", Owner: "Nova", CorpOwned: true},
+		{SectorID: 3, Class: "O", Owner: "Nova", CorpOwned: true},
+		{SectorID: 3, Class: "L", Owner: "bob"},
+	}
+	st := analyzePlanets(regions, planets)
+
+	if st.Count != 6 || st.Owned != 5 || st.CorpOwned != 3 {
+		t.Fatalf("counts: %+v", st)
+	}
+	if st.SectorsWithPlanets != 3 || st.MaxPerSector != 3 || st.MaxPerSectorID != 3 {
+		t.Fatalf("per sector: %d sectors, max %d in %d", st.SectorsWithPlanets, st.MaxPerSector, st.MaxPerSectorID)
+	}
+	if st.ByClass["M"] != 2 || st.ByClass["O"] != 2 {
+		t.Fatalf("by class: %v", st.ByClass)
+	}
+	if len(st.Regions) != 3 || st.Regions[0].Region != "(none)" || st.Regions[0].Planets != 0 || st.Regions[0].Sectors != 1 {
+		t.Fatalf("regions: %+v", st.Regions)
+	}
+	core, rim := st.Regions[1], st.Regions[2]
+	if core.Sectors != 2 || core.Planets != 3 || core.Owned != 2 || core.Owners != 2 || core.TopOwner != "Nova" {
+		t.Fatalf("core: %+v", core)
+	}
+	if rim.Planets != 3 || rim.Owners != 2 || rim.TopOwner != "Nova" || rim.TopOwnerPlanets != 2 {
+		t.Fatalf("rim: %+v", rim)
+	}
+
+	a := UniverseAnalysis{Planets: st}
+	if r := a.Report(); !strings.Contains(r, "Planets: 6  Owned: 5 (3 by corps)  Unowned: 1") || !strings.Contains(r, "The Rim: 3 planet(s) in 1 sector(s), 3 owned by 2 owner(s), most by Nova (2)") {
+		t.Fatalf("report missing planet section:\n%s", r)
+	}
+}
@@ -0,0 +1,580 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// Interest is applied once per bank tick, in basis points (1/100 of a percent).
+	bankDepositRateBps = 10
+	bankLoanRateBps    = 50
+
+	bankLoanBase     = 2000
+	bankLoanPerLevel = 500
+	// Up to this fraction (1/n) of positive net worth can be borrowed on top of the rank allowance.
+	bankLoanNetWorthDivisor = 4
+	bankLoanTerm            = 48 * time.Hour
+
+	// Cap for CORP INTEREST so a leader cannot drain the corp bank in a few ticks.
+	corpInterestMaxBps = 100
+)
+
+type bankAccount struct {
+	Deposit   int64
+	Loan      int64
+	LoanDueAt *time.Time
+	Defaulted bool
+}
+
+func loadBankAccount(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, playerID string) (bankAccount, error) {
+	var a bankAccount
+	err := q.QueryRow(ctx, `
+		SELECT deposit, loan, loan_due_at, defaulted
+		FROM bank_accounts
+		WHERE player_id=$1
+	`, playerID).Scan(&a.Deposit, &a.Loan, &a.LoanDueAt, &a.Defaulted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return bankAccount{}, nil
+	}
+	return a, err
+}
+
+func saveBankAccount(ctx context.Context, tx pgx.Tx, playerID string, a bankAccount) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO bank_accounts(player_id, deposit, loan, loan_due_at, defaulted, updated_at)
+		VALUES ($1,$2,$3,$4,$5,now())
+		ON CONFLICT (player_id) DO UPDATE SET
+			deposit = EXCLUDED.deposit,
+			loan = EXCLUDED.loan,
+			loan_due_at = EXCLUDED.loan_due_at,
+			defaulted = EXCLUDED.defaulted,
+			updated_at = EXCLUDED.updated_at
+	`, playerID, a.Deposit, a.Loan, a.LoanDueAt, a.Defaulted)
+	return err
+}
+
+// LoanLimit is the total a player may owe: a rank allowance plus a share of positive net worth.
+func LoanLimit(level int, netWorth int64) int64 {
+	if level < 1 {
+		level = 1
+	}
+	limit := int64(bankLoanBase + level*bankLoanPerLevel)
+	if netWorth > 0 {
+		limit += netWorth / bankLoanNetWorthDivisor
+	}
+	return limit
+}
+
+// interestFor returns the interest on balance at rate basis points, rounded down.
+func interestFor(balance int64, bps int) int64 {
+	if balance <= 0 || bps <= 0 {
+		return 0
+	}
+	return balance * int64(bps) / 10000
+}
+
+func executeBankCommand(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRequest) (phase2Result, error) {
+	action := strings.ToUpper(strings.TrimSpace(cmd.Action))
+	if action == "" {
+		action = "INFO"
+	}
+
+	acct, err := loadBankAccount(ctx, tx, p.ID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if action == "INFO" {
+		return bankInfo(ctx, tx, *p, acct)
+	}
+
+	isProt, err := IsProtectorateSector(ctx, tx, p.SectorID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !isProt {
+		msg := "No bank is available in this sector. The Protectorate bank operates in Protectorate sectors."
+		return phase2Result{OK: false, Message: msg, ErrorCode: "NO_BANK"}, nil
+	}
+
+	switch action {
+	case "DEPOSIT":
+		return bankDeposit(ctx, tx, p, acct, cmd.Quantity)
+	case "WITHDRAW":
+		return bankWithdraw(ctx, tx, p, acct, cmd.Quantity)
+	case "LOAN":
+		return bankLoan(ctx, tx, p, acct, cmd.Quantity)
+	case "REPAY":
+		return bankRepay(ctx, tx, p, acct, cmd.Quantity)
+	default:
+		return phase2Result{OK: false, Message: "Unknown BANK subcommand.", ErrorCode: "UNKNOWN_SUBCOMMAND"}, nil
+	}
+}
+
+func bankInfo(ctx context.Context, tx pgx.Tx, p Player, acct bankAccount) (phase2Result, error) {
+	netWorth, err := estimateNetWorth(ctx, tx, p)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	limit := LoanLimit(p.Level, netWorth)
+
+	lines := []string{
+		"BANK commands (Protectorate sectors): BANK DEPOSIT {credits} | BANK WITHDRAW {credits} | BANK LOAN {credits} | BANK REPAY [credits]",
+		fmt.Sprintf("Deposit: %d (earns %d.%02d%% per bank cycle)", acct.Deposit, bankDepositRateBps/100, bankDepositRateBps%100),
+		fmt.Sprintf("Loan: %d (accrues %d.%02d%% per bank cycle)", acct.Loan, bankLoanRateBps/100, bankLoanRateBps%100),
+	}
+	if acct.Loan > 0 && acct.LoanDueAt != nil {
+		lines = append(lines, fmt.Sprintf("Loan due: %s", acct.LoanDueAt.UTC().Format(time.RFC3339)))
+	}
+	if acct.Defaulted {
+		lines = append(lines, "Status: IN DEFAULT. Debt collectors will seize assets until the loan is repaid.")
+	} else {
+		lines = append(lines, fmt.Sprintf("Credit limit: %d (available %d)", limit, max64(0, limit-acct.Loan)))
+	}
+	msg := strings.Join(lines, "\n")
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "SYSTEM", msg: msg}}}, nil
+}
+
+func bankDeposit(ctx context.Context, tx pgx.Tx, p *Player, acct bankAccount, amount int) (phase2Result, error) {
+	if amount < 1 {
+		return phase2Result{OK: false, Message: "Deposit amount must be at least 1.", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	amt := int64(amount)
+	if p.Credits < amt {
+		return phase2Result{OK: false, Message: "Not enough credits.", ErrorCode: "INSUFFICIENT_CREDITS"}, nil
+	}
+	p.Credits -= amt
+	acct.Deposit += amt
+	if err := saveBankAccount(ctx, tx, p.ID, acct); err != nil {
+		return phase2Result{}, err
+	}
+	msg := fmt.Sprintf("Deposited %d credits with the Protectorate bank. Balance: %d.", amt, acct.Deposit)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+func bankWithdraw(ctx context.Context, tx pgx.Tx, p *Player, acct bankAccount, amount int) (phase2Result, error) {
+	if amount < 1 {
+		return phase2Result{OK: false, Message: "Withdraw amount must be at least 1.", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	amt := int64(amount)
+	if acct.Deposit < amt {
+		return phase2Result{OK: false, Message: "Your bank balance is too low.", ErrorCode: "INSUFFICIENT_FUNDS"}, nil
+	}
+	p.Credits += amt
+	acct.Deposit -= amt
+	if err := saveBankAccount(ctx, tx, p.ID, acct); err != nil {
+		return phase2Result{}, err
+	}
+	msg := fmt.Sprintf("Withdrew %d credits from the Protectorate bank. Balance: %d.", amt, acct.Deposit)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+func bankLoan(ctx context.Context, tx pgx.Tx, p *Player, acct bankAccount, amount int) (phase2Result, error) {
+	if amount < 1 {
+		return phase2Result{OK: false, Message: "Loan amount must be at least 1.", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	if acct.Defaulted {
+		return phase2Result{OK: false, Message: "You are in default. Repay your loan before borrowing again.", ErrorCode: "LOAN_DEFAULTED"}, nil
+	}
+	netWorth, err := estimateNetWorth(ctx, tx, *p)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	amt := int64(amount)
+	limit := LoanLimit(p.Level, netWorth)
+	if acct.Loan+amt > limit {
+		msg := fmt.Sprintf("Loan denied. Your credit limit is %d and you owe %d.", limit, acct.Loan)
+		return phase2Result{OK: false, Message: msg, ErrorCode: "LOAN_LIMIT"}, nil
+	}
+
+	if acct.Loan == 0 || acct.LoanDueAt == nil {
+		due := time.Now().UTC().Add(bankLoanTerm)
+		acct.LoanDueAt = &due
+	}
+	acct.Loan += amt
+	p.Credits += amt
+	if err := saveBankAccount(ctx, tx, p.ID, acct); err != nil {
+		return phase2Result{}, err
+	}
+	msg := fmt.Sprintf("Borrowed %d credits. You owe %d, due %s.", amt, acct.Loan, acct.LoanDueAt.UTC().Format(time.RFC3339))
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+// bankRepay pays down the loan. Without an amount it repays as much as the player's credits allow.
+func bankRepay(ctx context.Context, tx pgx.Tx, p *Player, acct bankAccount, amount int) (phase2Result, error) {
+	if acct.Loan <= 0 {
+		return phase2Result{OK: false, Message: "You have no outstanding loan.", ErrorCode: "NO_LOAN"}, nil
+	}
+	amt := acct.Loan
+	if amount > 0 && int64(amount) < amt {
+		amt = int64(amount)
+	}
+	if p.Credits < amt {
+		if amount > 0 || p.Credits < 1 {
+			return phase2Result{OK: false, Message: "Not enough credits.", ErrorCode: "INSUFFICIENT_CREDITS"}, nil
+		}
+		amt = p.Credits
+	}
+
+	p.Credits -= amt
+	acct.Loan -= amt
+	msg := fmt.Sprintf("Repaid %d credits. Outstanding loan: %d.", amt, acct.Loan)
+	if acct.Loan == 0 {
+		acct.LoanDueAt = nil
+		acct.Defaulted = false
+		msg = fmt.Sprintf("Repaid %d credits. Your loan is cleared.", amt)
+	}
+	if err := saveBankAccount(ctx, tx, p.ID, acct); err != nil {
+		return phase2Result{}, err
+	}
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+// StartBankTicker accrues deposit and loan interest, pays corp member interest, and runs
+// debt collection against overdue loans.
+func StartBankTicker(ctx context.Context, pool *pgxpool.Pool, tickSeconds int) {
+	if tickSeconds <= 0 {
+		return
+	}
+	if tickSeconds < 10 {
+		tickSeconds = 10
+	}
+	pick := func(r SeasonRules) int { return r.BankTickSeconds }
+	startRulesTicker(ctx, pool, tickSeconds, 10, pick, func() { _ = runBankTick(ctx, pool) })
+}
+
+func runBankTick(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		UPDATE bank_accounts SET
+			deposit = deposit + (deposit * $1 / 10000),
+			loan = loan + (loan * $2 / 10000),
+			updated_at = now()
+		WHERE deposit > 0 OR loan > 0
+	`, bankDepositRateBps, bankLoanRateBps)
+	if err != nil {
+		return err
+	}
+	if err := payCorpMemberInterest(ctx, pool); err != nil {
+		return err
+	}
+	return collectOverdueLoans(ctx, pool)
+}
+
+// payCorpMemberInterest pays each member of a corp with a member interest rate their share,
+// in join order, for as long as the corp bank can cover it.
+func payCorpMemberInterest(ctx context.Context, pool *pgxpool.Pool) error {
+	rows, err := pool.Query(ctx, "SELECT id FROM corporations WHERE member_interest_bps > 0 ORDER BY id")
+	if err != nil {
+		return err
+	}
+	var corpIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		corpIDs = append(corpIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, corpID := range corpIDs {
+		if err := payCorpInterest(ctx, pool, corpID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func payCorpInterest(ctx context.Context, pool *pgxpool.Pool, corpID string) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var name string
+	var credits int64
+	var bps int
+	err = tx.QueryRow(ctx, "SELECT name, credits, member_interest_bps FROM corporations WHERE id=$1 FOR UPDATE", corpID).Scan(&name, &credits, &bps)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	opening := credits
+
+	type payout struct {
+		playerID string
+		amount   int64
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT player_id, deposit_balance
+		FROM corp_members
+		WHERE corp_id=$1 AND deposit_balance > 0
+		ORDER BY joined_at ASC
+	`, corpID)
+	if err != nil {
+		return err
+	}
+	var payouts []payout
+	for rows.Next() {
+		var pid string
+		var bal int64
+		if err := rows.Scan(&pid, &bal); err != nil {
+			rows.Close()
+			return err
+		}
+		amt := interestFor(bal, bps)
+		if amt < 1 || amt > credits {
+			continue
+		}
+		credits -= amt
+		payouts = append(payouts, payout{playerID: pid, amount: amt})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(payouts) == 0 {
+		return nil
+	}
+
+	balance := opening
+	for _, po := range payouts {
+		if _, err := tx.Exec(ctx, "UPDATE players SET credits = credits + $2 WHERE id=$1", po.playerID, po.amount); err != nil {
+			return err
+		}
+		balance -= po.amount
+		if err := recordCorpBankTx(ctx, tx, corpID, po.playerID, CorpTxInterest, -po.amount, balance, ""); err != nil {
+			return err
+		}
+		msg := fmt.Sprintf("Corp interest: %s paid you %d credits on your deposits.", name, po.amount)
+		if err := InsertLog(ctx, tx, po.playerID, "CORP", msg); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx, "UPDATE corporations SET credits=$2 WHERE id=$1", corpID, credits); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func collectOverdueLoans(ctx context.Context, pool *pgxpool.Pool) error {
+	rows, err := pool.Query(ctx, "SELECT player_id FROM bank_accounts WHERE loan > 0 AND loan_due_at <= now() ORDER BY player_id")
+	if err != nil {
+		return err
+	}
+	var playerIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		playerIDs = append(playerIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range playerIDs {
+		if err := collectDebt(ctx, pool, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectDebt marks an overdue loan as defaulted and seizes assets against it, in order:
+// bank deposit, credits, ship cargo, then storage on planets the player owns. Goods are
+// credited at reference prices.
+func collectDebt(ctx context.Context, pool *pgxpool.Pool, playerID string) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	p, err := LoadPlayerForUpdate(ctx, tx, playerID)
+	if err != nil {
+		return err
+	}
+	acct, err := loadBankAccount(ctx, tx, playerID)
+	if err != nil {
+		return err
+	}
+	if acct.Loan <= 0 {
+		return nil
+	}
+	wasDefaulted := acct.Defaulted
+
+	var seized []string
+	take := func(available int64) int64 {
+		amt := min64(available, acct.Loan)
+		acct.Loan -= amt
+		return amt
+	}
+
+	if amt := take(acct.Deposit); amt > 0 {
+		acct.Deposit -= amt
+		seized = append(seized, fmt.Sprintf("%d credits from your bank deposit", amt))
+	}
+	if amt := take(p.Credits); amt > 0 {
+		p.Credits -= amt
+		seized = append(seized, fmt.Sprintf("%d credits", amt))
+	}
+	for _, c := range []struct {
+		name string
+		qty  *int
+	}{
+		{"EQUIPMENT", &p.CargoEquipment},
+		{"ORGANICS", &p.CargoOrganics},
+		{"ORE", &p.CargoOre},
+	} {
+		units := seizeUnits(*c.qty, referencePrice(c.name), acct.Loan)
+		if units < 1 {
+			continue
+		}
+		*c.qty -= units
+		acct.Loan -= int64(units) * referencePrice(c.name)
+		seized = append(seized, fmt.Sprintf("%d %s from your hold", units, strings.ToLower(c.name)))
+	}
+	if acct.Loan > 0 {
+		fromPlanets, err := seizePlanetStorage(ctx, tx, playerID, &acct.Loan)
+		if err != nil {
+			return err
+		}
+		seized = append(seized, fromPlanets...)
+	}
+	if acct.Loan < 0 {
+		acct.Loan = 0
+	}
+
+	acct.Defaulted = acct.Loan > 0
+	if acct.Loan == 0 {
+		acct.LoanDueAt = nil
+	}
+	if err := saveBankAccount(ctx, tx, playerID, acct); err != nil {
+		return err
+	}
+	if err := SavePlayer(ctx, tx, p); err != nil {
+		return err
+	}
+
+	msg := "Your Protectorate loan is overdue and in default."
+	if len(seized) > 0 {
+		msg += " Debt collectors seized " + strings.Join(seized, ", ") + "."
+	}
+	if acct.Loan > 0 {
+		msg += fmt.Sprintf(" Outstanding: %d credits.", acct.Loan)
+	} else {
+		msg += " The debt is settled."
+	}
+	// Notify on the first default and whenever something is seized, not on every idle tick.
+	if len(seized) > 0 || !wasDefaulted {
+		if err := InsertLog(ctx, tx, playerID, "COMBAT", msg); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// seizeUnits is how many units worth unitPrice each are needed to cover debt, capped at available.
+func seizeUnits(available int, unitPrice, debt int64) int {
+	if available <= 0 || unitPrice <= 0 || debt <= 0 {
+		return 0
+	}
+	need := (debt + unitPrice - 1) / unitPrice
+	if need > int64(available) {
+		return available
+	}
+	return int(need)
+}
+
+func seizePlanetStorage(ctx context.Context, tx pgx.Tx, playerID string, debt *int64) ([]string, error) {
+	rows, err := tx.Query(ctx, "SELECT sector_id FROM planets WHERE owner_player_id=$1 ORDER BY sector_id", playerID)
+	if err != nil {
+		return nil, err
+	}
+	var sectors []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		sectors = append(sectors, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var seized []string
+	for _, sectorID := range sectors {
+		if *debt <= 0 {
+			break
+		}
+		pl, ok, err := loadPlanet(ctx, tx, sectorID, true)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		taken := 0
+		for _, c := range []struct {
+			name string
+			qty  *int
+		}{
+			{"EQUIPMENT", &pl.StorageEquipment},
+			{"ORGANICS", &pl.StorageOrganics},
+			{"ORE", &pl.StorageOre},
+		} {
+			units := seizeUnits(*c.qty, referencePrice(c.name), *debt)
+			if units < 1 {
+				continue
+			}
+			*c.qty -= units
+			*debt -= int64(units) * referencePrice(c.name)
+			taken += units
+		}
+		if taken == 0 {
+			continue
+		}
+		if err := savePlanetStorage(ctx, tx, pl); err != nil {
+			return nil, err
+		}
+		seized = append(seized, fmt.Sprintf("%d units stored on %s", taken, pl.Name))
+	}
+	return seized, nil
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
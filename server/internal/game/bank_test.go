@@ -0,0 +1,42 @@
+package game
+
+import "testing"
+
+func TestLoanLimit(t *testing.T) {
+	if got := LoanLimit(1, 0); got != 2500 {
+		t.Fatalf("expected 2500, got %d", got)
+	}
+	if got := LoanLimit(10, 40000); got != 2000+10*500+10000 {
+		t.Fatalf("expected %d, got %d", 2000+10*500+10000, got)
+	}
+	// Negative net worth does not reduce the rank allowance.
+	if got := LoanLimit(2, -5000); got != 3000 {
+		t.Fatalf("expected 3000, got %d", got)
+	}
+}
+
+func TestInterestFor(t *testing.T) {
+	if got := interestFor(10000, 50); got != 50 {
+		t.Fatalf("expected 50, got %d", got)
+	}
+	if got := interestFor(199, 50); got != 0 {
+		t.Fatalf("expected 0 (rounded down), got %d", got)
+	}
+	if got := interestFor(-100, 50); got != 0 {
+		t.Fatalf("expected 0 for negative balance, got %d", got)
+	}
+}
+
+func TestSeizeUnits(t *testing.T) {
+	// 100 credits of debt at 60/unit needs 2 units.
+	if got := seizeUnits(10, 60, 100); got != 2 {
+		t.Fatalf("expected 2, got %d", got)
+	}
+	// Capped at what is available.
+	if got := seizeUnits(3, 11, 1000); got != 3 {
+		t.Fatalf("expected 3, got %d", got)
+	}
+	if got := seizeUnits(5, 11, 0); got != 0 {
+		t.Fatalf("expected 0 with no debt, got %d", got)
+	}
+}
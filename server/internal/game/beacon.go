@@ -0,0 +1,171 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	beaconFee    = 250
+	beaconMaxLen = 80
+)
+
+type beaconRow struct {
+	BeaconView
+	PlayerID string
+}
+
+func loadBeaconRow(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, sectorID int) (beaconRow, bool, error) {
+	var b beaconRow
+	err := q.QueryRow(ctx, `
+		SELECT b.player_id, b.message, COALESCE(u.username, ''), b.created_at
+		FROM sector_beacons b
+		LEFT JOIN players p ON p.id = b.player_id
+		LEFT JOIN users u ON u.id = p.user_id
+		WHERE b.sector_id=$1
+	`, sectorID).Scan(&b.PlayerID, &b.Message, &b.Author, &b.PlacedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return beaconRow{}, false, nil
+	}
+	if err != nil {
+		return beaconRow{}, false, err
+	}
+	return b, true, nil
+}
+
+func loadBeacon(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, sectorID int) (BeaconView, bool, error) {
+	b, ok, err := loadBeaconRow(ctx, q, sectorID)
+	return b.BeaconView, ok, err
+}
+
+func executeBeaconCommand(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRequest) (phase2Result, error) {
+	switch strings.ToUpper(strings.TrimSpace(cmd.Action)) {
+	case "", "INFO":
+		return beaconInfo(ctx, tx, *p)
+	case "SET":
+		return beaconSet(ctx, tx, p, cmd.Text)
+	case "CLEAR":
+		return beaconClear(ctx, tx, *p)
+	case "REPORT":
+		return beaconReport(ctx, tx, *p)
+	default:
+		return phase2Result{OK: false, Message: "Unknown BEACON subcommand.", ErrorCode: "UNKNOWN_SUBCOMMAND"}, nil
+	}
+}
+
+func beaconInfo(ctx context.Context, tx pgx.Tx, p Player) (phase2Result, error) {
+	b, ok, err := loadBeaconRow(ctx, tx, p.SectorID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !ok {
+		msg := fmt.Sprintf("No beacon in this sector. BEACON SET {text} places one for %d credits.", beaconFee)
+		return phase2Result{OK: true, Message: msg}, nil
+	}
+	msg := fmt.Sprintf("Beacon (%s, %s): %s", b.Author, b.PlacedAt.UTC().Format("2006-01-02 15:04"), b.Message)
+	return phase2Result{OK: true, Message: msg}, nil
+}
+
+// beaconSet places the sector's beacon for a credit fee, or replaces the caller's own beacon.
+// Another player's beacon stays until its owner (or an admin) clears it.
+func beaconSet(ctx context.Context, tx pgx.Tx, p *Player, text string) (phase2Result, error) {
+	text = strings.ReplaceAll(text, "\n", " ")
+	text = strings.ReplaceAll(text, "\r", " ")
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return phase2Result{OK: false, Message: "Beacon text cannot be empty.", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	if len(text) > beaconMaxLen {
+		return phase2Result{OK: false, Message: fmt.Sprintf("Beacon text too long (max %d).", beaconMaxLen), ErrorCode: "INVALID_ARGS"}, nil
+	}
+	if p.Credits < beaconFee {
+		return phase2Result{OK: false, Message: fmt.Sprintf("A beacon costs %d credits.", beaconFee), ErrorCode: "INSUFFICIENT_CREDITS"}, nil
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO sector_beacons(sector_id, player_id, message, created_at)
+		VALUES ($1,$2,$3,now())
+		ON CONFLICT (sector_id) DO UPDATE SET
+			message = EXCLUDED.message,
+			created_at = EXCLUDED.created_at
+		WHERE sector_beacons.player_id = EXCLUDED.player_id
+	`, p.SectorID, p.ID, text)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return phase2Result{OK: false, Message: "This sector already has another player's beacon. It must be cleared before a new one can be placed.", ErrorCode: "BEACON_TAKEN"}, nil
+	}
+	p.Credits -= beaconFee
+
+	msg := fmt.Sprintf("Beacon placed in sector %d for %d credits.", p.SectorID, beaconFee)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+// beaconClear removes the sector's beacon. Only its author or an admin may clear it.
+func beaconClear(ctx context.Context, tx pgx.Tx, p Player) (phase2Result, error) {
+	b, ok, err := loadBeaconRow(ctx, tx, p.SectorID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !ok {
+		return phase2Result{OK: false, Message: "No beacon in this sector.", ErrorCode: "NO_BEACON"}, nil
+	}
+	if b.PlayerID != p.ID && !p.IsAdmin {
+		return phase2Result{OK: false, Message: "Only the beacon's owner can clear it.", ErrorCode: "NOT_OWNER"}, nil
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM sector_beacons WHERE sector_id=$1", p.SectorID); err != nil {
+		return phase2Result{}, err
+	}
+	msg := fmt.Sprintf("Beacon in sector %d cleared.", p.SectorID)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+// beaconReport files a spam/abuse report for the sector's beacon with the admin inbox, the same
+// way reported direct messages are handled. Admins remove beacons with BEACON CLEAR.
+func beaconReport(ctx context.Context, tx pgx.Tx, p Player) (phase2Result, error) {
+	b, ok, err := loadBeaconRow(ctx, tx, p.SectorID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !ok {
+		return phase2Result{OK: false, Message: "No beacon in this sector.", ErrorCode: "NO_BEACON"}, nil
+	}
+	if b.PlayerID == p.ID {
+		return phase2Result{OK: false, Message: "You cannot report your own beacon.", ErrorCode: "INVALID_ARGS"}, nil
+	}
+
+	adminPID, err := LookupAdminPlayerID(ctx, tx, "")
+	if errors.Is(err, ErrNotFound) {
+		return phase2Result{OK: false, Message: "Admin account not available.", ErrorCode: "NO_ADMIN"}, nil
+	}
+	if err != nil {
+		return phase2Result{}, err
+	}
+
+	subject := fmt.Sprintf("Spam/Abuse Report: beacon in sector %d", p.SectorID)
+	if _, err := InsertDirectMessage(ctx, tx, p.ID, adminPID, MessageKindSpamReport, subject, FormatBeaconReportBody(p.Username, p.SectorID, b.BeaconView), nil); err != nil {
+		return phase2Result{}, err
+	}
+	return phase2Result{OK: true, Message: "Beacon reported to admin."}, nil
+}
+
+func FormatBeaconReportBody(reporterUsername string, sectorID int, b BeaconView) string {
+	return fmt.Sprintf(
+		"Spam/abuse report submitted by %s.\n\nReported beacon:\n- sector: %d\n- placed_by: %s\n- placed_at: %s\n\nText:\n%s\n\nMove to the sector and use BEACON CLEAR to remove it.\n",
+		reporterUsername,
+		sectorID,
+		b.Author,
+		b.PlacedAt.UTC().Format(time.RFC3339),
+		b.Message,
+	)
+}
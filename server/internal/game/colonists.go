@@ -0,0 +1,38 @@
+package game
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Colonists are recruited at a flat price in Protectorate sectors and occupy one cargo hold each.
+const colonistPrice int64 = 20
+
+func tradeColonists(ctx context.Context, tx pgx.Tx, p *Player, action string, qty int) (string, bool, error) {
+	if action != "BUY" {
+		return "Colonists cannot be sold. Settle them with PLANET UNLOAD COLONISTS {qty}.", false, nil
+	}
+	if qty < 1 || qty > 1000000 {
+		return "Quantity must be at least 1.", false, nil
+	}
+	isProt, err := IsProtectorateSector(ctx, tx, p.SectorID)
+	if err != nil {
+		return "Trade failed.", false, err
+	}
+	if !isProt {
+		return "Colonists can only be recruited in Protectorate sectors.", false, nil
+	}
+	if qty > p.CargoMax-totalCargo(p) {
+		return "Not enough cargo space.", false, nil
+	}
+	total := colonistPrice * int64(qty)
+	if p.Credits < total {
+		return "Not enough credits.", false, nil
+	}
+
+	p.Credits -= total
+	p.CargoColonists += qty
+	return fmt.Sprintf("Recruited %d colonists for %d credits (%d each).", qty, total, colonistPrice), true, nil
+}
@@ -25,6 +25,10 @@ func executeCorpCommand(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRe
 		action = "INFO"
 	}
 
+	if err := purgeExpiredCorpInvites(ctx, tx); err != nil {
+		return phase2Result{}, err
+	}
+
 	switch action {
 	case "INFO":
 		return corpInfo(ctx, tx, *p)
@@ -40,6 +44,50 @@ func executeCorpCommand(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRe
 		return corpDeposit(ctx, tx, p, cmd.Quantity)
 	case "WITHDRAW":
 		return corpWithdraw(ctx, tx, p, cmd.Quantity)
+	case "INTEREST":
+		return corpSetInterest(ctx, tx, *p, cmd.Quantity)
+	case "MODE":
+		return corpSetMembership(ctx, tx, *p, cmd.Name)
+	case "INVITE":
+		return corpInvite(ctx, tx, *p, cmd.Name)
+	case "APPLY":
+		return corpApply(ctx, tx, *p, cmd.Name)
+	case "ACCEPT":
+		return corpAnswer(ctx, tx, p, cmd.Name, true)
+	case "REJECT":
+		return corpAnswer(ctx, tx, p, cmd.Name, false)
+	case "INVITES":
+		return corpListInvites(ctx, tx, *p)
+	case "PROMOTE":
+		return corpPromote(ctx, tx, *p, cmd.Name)
+	case "DEMOTE":
+		return corpDemote(ctx, tx, *p, cmd.Name)
+	case "KICK":
+		return corpKick(ctx, tx, *p, cmd.Name)
+	case "TRANSFER":
+		return corpTransfer(ctx, tx, p, cmd.Name)
+	case "MUTE":
+		return corpMute(ctx, tx, *p, cmd.Name, true)
+	case "UNMUTE":
+		return corpMute(ctx, tx, *p, cmd.Name, false)
+	case "PERMIT":
+		return corpPermit(ctx, tx, p, cmd.Name)
+	case "PERMISSIONS":
+		return corpListPermissions(*p)
+	case "DIPLOMACY":
+		return executeCorpDiplomacy(ctx, tx, *p, cmd.Name)
+	case "INTEL":
+		return corpSetIntelPool(ctx, tx, *p, cmd.Name)
+	case "SHARE":
+		return corpShareIntel(ctx, tx, *p, cmd.Name)
+	case "LEDGER":
+		return corpLedger(ctx, tx, *p, cmd.Quantity)
+	case "LIMIT":
+		return corpSetLimit(ctx, tx, *p, cmd.Name)
+	case "APPROVE":
+		return corpAnswerWithdrawal(ctx, tx, p, cmd.Name, true)
+	case "DENY":
+		return corpAnswerWithdrawal(ctx, tx, p, cmd.Name, false)
 	default:
 		return phase2Result{OK: false, Message: "Unknown CORP subcommand.", ErrorCode: "UNKNOWN_SUBCOMMAND"}, nil
 	}
@@ -53,11 +101,22 @@ func corpInfo(ctx context.Context, tx pgx.Tx, p Player) (phase2Result, error) {
 
 	var name string
 	var credits int64
-	err := tx.QueryRow(ctx, "SELECT name, credits FROM corporations WHERE id=$1", p.CorpID).Scan(&name, &credits)
+	var interestBps int
+	var membership string
+	var shareIntel bool
+	err := tx.QueryRow(ctx, "SELECT name, credits, member_interest_bps, membership, share_intel FROM corporations WHERE id=$1", p.CorpID).Scan(&name, &credits, &interestBps, &membership, &shareIntel)
+	if err != nil {
+		return phase2Result{}, err
+	}
+
+	policy, err := loadCorpWithdrawPolicy(ctx, tx, p.CorpID)
 	if err != nil {
 		return phase2Result{}, err
 	}
 
+	var deposited int64
+	_ = tx.QueryRow(ctx, "SELECT deposit_balance FROM corp_members WHERE player_id=$1", p.ID).Scan(&deposited)
+
 	var members int
 	_ = tx.QueryRow(ctx, "SELECT COUNT(1) FROM corp_members WHERE corp_id=$1", p.CorpID).Scan(&members)
 
@@ -68,8 +127,13 @@ func corpInfo(ctx context.Context, tx pgx.Tx, p Player) (phase2Result, error) {
 		fmt.Sprintf("Corporation: %s", name),
 		fmt.Sprintf("Role: %s", p.CorpRole),
 		fmt.Sprintf("Members: %d", members),
+		fmt.Sprintf("Membership: %s", membership),
+		fmt.Sprintf("Pooled market intel: %s", yesNo(shareIntel)),
+		describeWithdrawPolicy(policy),
 		fmt.Sprintf("Bank credits: %d", credits),
 		fmt.Sprintf("Planets controlled: %d", planets),
+		fmt.Sprintf("Member interest: %d.%02d%% per bank cycle on deposits", interestBps/100, interestBps%100),
+		fmt.Sprintf("Your deposits: %d", deposited),
 	}, "\n")
 
 	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "SYSTEM", msg: msg}}}, nil
@@ -121,7 +185,8 @@ func corpJoin(ctx context.Context, tx pgx.Tx, p *Player, name string) (phase2Res
 	var corpID string
 	var corpName string
 	var corpCredits int64
-	err := tx.QueryRow(ctx, "SELECT id, name, credits FROM corporations WHERE lower(name)=lower($1)", name).Scan(&corpID, &corpName, &corpCredits)
+	var membership string
+	err := tx.QueryRow(ctx, "SELECT id, name, credits, membership FROM corporations WHERE lower(name)=lower($1)", name).Scan(&corpID, &corpName, &corpCredits, &membership)
 	if err == pgx.ErrNoRows {
 		return phase2Result{OK: false, Message: "Corporation not found.", ErrorCode: "NOT_FOUND"}, nil
 	}
@@ -129,6 +194,20 @@ func corpJoin(ctx context.Context, tx pgx.Tx, p *Player, name string) (phase2Res
 		return phase2Result{}, err
 	}
 
+	if membership != CorpMembershipOpen {
+		invited, err := takePendingInvite(ctx, tx, corpID, p.ID, corpInviteKindInvite)
+		if err != nil {
+			return phase2Result{}, err
+		}
+		if !invited {
+			if membership == CorpMembershipApply {
+				msg := fmt.Sprintf("%s accepts members by application. Use CORP APPLY %s.", corpName, corpName)
+				return phase2Result{OK: false, Message: msg, ErrorCode: "APPLY_ONLY"}, nil
+			}
+			return phase2Result{OK: false, Message: fmt.Sprintf("%s is invite-only.", corpName), ErrorCode: "INVITE_ONLY"}, nil
+		}
+	}
+
 	_, err = tx.Exec(ctx, "INSERT INTO corp_members(corp_id, player_id, role) VALUES ($1,$2,'MEMBER')", corpID, p.ID)
 	if err != nil {
 		return phase2Result{OK: false, Message: "Unable to join corporation.", ErrorCode: "JOIN_FAILED"}, nil
@@ -156,24 +235,36 @@ func corpLeave(ctx context.Context, tx pgx.Tx, p *Player) (phase2Result, error)
 	}
 
 	if strings.ToUpper(p.CorpRole) == "LEADER" && members > 1 {
-		var newLeader string
+		// Leadership passes to the longest-serving officer, or the oldest member if there are none.
+		var newLeader, newLeaderName string
 		err := tx.QueryRow(ctx, `
-			SELECT player_id
-			FROM corp_members
-			WHERE corp_id=$1 AND player_id <> $2
-			ORDER BY joined_at ASC
+			SELECT cm.player_id, u.username
+			FROM corp_members cm
+			JOIN players pl ON pl.id = cm.player_id
+			JOIN users u ON u.id = pl.user_id
+			WHERE cm.corp_id=$1 AND cm.player_id <> $2
+			ORDER BY (cm.role = 'OFFICER') DESC, cm.joined_at ASC
 			LIMIT 1
-		`, corpID, p.ID).Scan(&newLeader)
+		`, corpID, p.ID).Scan(&newLeader, &newLeaderName)
 		if err != nil {
 			return phase2Result{}, err
 		}
-		_, _ = tx.Exec(ctx, "UPDATE corp_members SET role='LEADER' WHERE corp_id=$1 AND player_id=$2", corpID, newLeader)
+		if err := corpSetRole(ctx, tx, corpID, newLeader, CorpRoleLeader); err != nil {
+			return phase2Result{}, err
+		}
+		if err := logToCorp(ctx, tx, corpID, fmt.Sprintf("%s left %s; leadership passed to %s.", p.Username, corpName, newLeaderName)); err != nil {
+			return phase2Result{}, err
+		}
 	}
 
 	_, err := tx.Exec(ctx, "DELETE FROM corp_members WHERE player_id=$1", p.ID)
 	if err != nil {
 		return phase2Result{}, err
 	}
+	// Intel the member pushed to the corp leaves with them.
+	if err := unshareIntel(ctx, tx, p.ID); err != nil {
+		return phase2Result{}, err
+	}
 
 	if members <= 1 {
 		// No members remain; remove corporation.
@@ -203,29 +294,18 @@ func corpSay(ctx context.Context, tx pgx.Tx, p Player, text string) (phase2Resul
 	if len(text) > corpSayMaxLen {
 		return phase2Result{OK: false, Message: fmt.Sprintf("Message too long (max %d).", corpSayMaxLen), ErrorCode: "INVALID_ARGS"}, nil
 	}
-
-	_, err := tx.Exec(ctx, "INSERT INTO corp_messages(corp_id, player_id, message) VALUES ($1,$2,$3)", p.CorpID, p.ID, text)
-	if err != nil {
+	var muted bool
+	if err := tx.QueryRow(ctx, "SELECT muted FROM corp_members WHERE player_id=$1", p.ID).Scan(&muted); err != nil {
 		return phase2Result{}, err
 	}
+	if muted {
+		return phase2Result{OK: false, Message: "You have been muted in corp chat.", ErrorCode: "MUTED"}, nil
+	}
 
-	payload := fmt.Sprintf("[%s] %s: %s", p.CorpName, p.Username, text)
-
-	memberRows, err := tx.Query(ctx, "SELECT player_id FROM corp_members WHERE corp_id=$1", p.CorpID)
+	_, err := tx.Exec(ctx, "INSERT INTO corp_messages(corp_id, player_id, message) VALUES ($1,$2,$3)", p.CorpID, p.ID, text)
 	if err != nil {
 		return phase2Result{}, err
 	}
-	defer memberRows.Close()
-	for memberRows.Next() {
-		var pid string
-		if err := memberRows.Scan(&pid); err != nil {
-			return phase2Result{}, err
-		}
-		_, _ = tx.Exec(ctx, "INSERT INTO logs(player_id, kind, message) VALUES ($1,'CORP',$2)", pid, payload)
-	}
-	if err := memberRows.Err(); err != nil {
-		return phase2Result{}, err
-	}
 
 	msg := "Corp message sent."
 	return phase2Result{OK: true, Message: msg}, nil
@@ -249,9 +329,25 @@ func corpDeposit(ctx context.Context, tx pgx.Tx, p *Player, amount int) (phase2R
 		return phase2Result{}, err
 	}
 
+	_, err = tx.Exec(ctx, "UPDATE corp_members SET deposit_balance = deposit_balance + $2 WHERE player_id=$1", p.ID, amt)
+	if err != nil {
+		return phase2Result{}, err
+	}
+
 	p.Credits -= amt
 	p.CorpCredits = newCredits
 
+	if err := recordCorpBankTx(ctx, tx, p.CorpID, p.ID, CorpTxDeposit, amt, newCredits, ""); err != nil {
+		return phase2Result{}, err
+	}
+	if amt >= corpLargeMovementCredits {
+		subject := fmt.Sprintf("Large deposit: %d credits", amt)
+		body := fmt.Sprintf("%s deposited %d credits to the %s bank. New balance: %d.\n", p.Username, amt, p.CorpName, newCredits)
+		if err := notifyCorpLeader(ctx, tx, p.CorpID, p.ID, subject, body); err != nil {
+			return phase2Result{}, err
+		}
+	}
+
 	msg := fmt.Sprintf("Deposited %d credits to corp bank. New bank balance: %d.", amt, newCredits)
 	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
 }
@@ -260,17 +356,37 @@ func corpWithdraw(ctx context.Context, tx pgx.Tx, p *Player, amount int) (phase2
 	if p.CorpID == "" {
 		return phase2Result{OK: false, Message: "You are not in a corporation.", ErrorCode: "NOT_IN_CORP"}, nil
 	}
-	role := strings.ToUpper(strings.TrimSpace(p.CorpRole))
-	if role != "LEADER" && role != "OFFICER" {
-		return phase2Result{OK: false, Message: "Only corp leadership can withdraw.", ErrorCode: "FORBIDDEN"}, nil
+	if !p.corpAllows(CorpPermWithdraw) {
+		return phase2Result{OK: false, Message: "Your corp role does not allow withdrawals.", ErrorCode: "FORBIDDEN"}, nil
 	}
 	if amount < 1 {
 		return phase2Result{OK: false, Message: "Withdraw amount must be at least 1.", ErrorCode: "INVALID_ARGS"}, nil
 	}
 	amt := int64(amount)
 
+	policy, err := loadCorpWithdrawPolicy(ctx, tx, p.CorpID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if limit := policy.dailyLimit(p.CorpRole); limit > 0 {
+		withdrawn, pending, err := corpWithdrawnToday(ctx, tx, p.CorpID, p.ID)
+		if err != nil {
+			return phase2Result{}, err
+		}
+		if room := withdrawRoom(limit, withdrawn, pending); amt > room {
+			msg := fmt.Sprintf("Daily withdrawal limit for %s is %d credits; you can withdraw %d more in the next 24h.", strings.ToUpper(p.CorpRole), limit, room)
+			if pending > 0 {
+				msg += fmt.Sprintf(" %d credits are pending approval.", pending)
+			}
+			return phase2Result{OK: false, Message: msg, ErrorCode: "LIMIT_EXCEEDED"}, nil
+		}
+	}
+	if policy.needsApproval(p.CorpRole, amt) {
+		return corpRequestWithdrawal(ctx, tx, *p, amt)
+	}
+
 	var newCredits int64
-	err := tx.QueryRow(ctx, "UPDATE corporations SET credits = credits - $2 WHERE id=$1 AND credits >= $2 RETURNING credits", p.CorpID, amt).Scan(&newCredits)
+	err = tx.QueryRow(ctx, "UPDATE corporations SET credits = credits - $2 WHERE id=$1 AND credits >= $2 RETURNING credits", p.CorpID, amt).Scan(&newCredits)
 	if err == pgx.ErrNoRows {
 		return phase2Result{OK: false, Message: "Corp bank does not have enough credits.", ErrorCode: "INSUFFICIENT_FUNDS"}, nil
 	}
@@ -278,13 +394,50 @@ func corpWithdraw(ctx context.Context, tx pgx.Tx, p *Player, amount int) (phase2
 		return phase2Result{}, err
 	}
 
+	if err := lowerCorpDeposit(ctx, tx, p.ID, amt); err != nil {
+		return phase2Result{}, err
+	}
+
 	p.Credits += amt
 	p.CorpCredits = newCredits
 
+	if err := recordCorpBankTx(ctx, tx, p.CorpID, p.ID, CorpTxWithdraw, -amt, newCredits, ""); err != nil {
+		return phase2Result{}, err
+	}
+	if amt >= corpLargeMovementCredits {
+		subject := fmt.Sprintf("Large withdrawal: %d credits", amt)
+		body := fmt.Sprintf("%s withdrew %d credits from the %s bank. New balance: %d.\n", p.Username, amt, p.CorpName, newCredits)
+		if err := notifyCorpLeader(ctx, tx, p.CorpID, p.ID, subject, body); err != nil {
+			return phase2Result{}, err
+		}
+	}
+
 	msg := fmt.Sprintf("Withdrew %d credits from corp bank. New bank balance: %d.", amt, newCredits)
 	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
 }
 
+// corpSetInterest sets the per-bank-cycle rate, in basis points, the corp pays members on their deposits.
+func corpSetInterest(ctx context.Context, tx pgx.Tx, p Player, bps int) (phase2Result, error) {
+	if p.CorpID == "" {
+		return phase2Result{OK: false, Message: "You are not in a corporation.", ErrorCode: "NOT_IN_CORP"}, nil
+	}
+	if strings.ToUpper(strings.TrimSpace(p.CorpRole)) != "LEADER" {
+		return phase2Result{OK: false, Message: "Only the corp leader can set member interest.", ErrorCode: "FORBIDDEN"}, nil
+	}
+	if bps < 0 || bps > corpInterestMaxBps {
+		msg := fmt.Sprintf("Interest must be between 0 and %d basis points.", corpInterestMaxBps)
+		return phase2Result{OK: false, Message: msg, ErrorCode: "INVALID_ARGS"}, nil
+	}
+
+	_, err := tx.Exec(ctx, "UPDATE corporations SET member_interest_bps=$2 WHERE id=$1", p.CorpID, bps)
+	if err != nil {
+		return phase2Result{}, err
+	}
+
+	msg := fmt.Sprintf("Member interest set to %d.%02d%% per bank cycle on deposits.", bps/100, bps%100)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
 func validateCorpName(name string) (string, error) {
 	name = sanitizeCorpName(name)
 	if len(name) < corpNameMinLen {
@@ -0,0 +1,360 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// corp_bank_transactions.kind. Amounts are signed: positive into the corp bank, negative out of it.
+const (
+	CorpTxDeposit   = "DEPOSIT"
+	CorpTxWithdraw  = "WITHDRAW"
+	CorpTxInterest  = "INTEREST"   // member interest paid out by the bank ticker
+	CorpTxGateBuild = "GATE_BUILD" // corp-funded GATE BUILD
+	CorpTxToll      = "TOLL"       // toll collected by a corp-owned gate
+)
+
+const (
+	corpLedgerDefault = 10
+	corpLedgerMax     = 50
+
+	// Deposits and withdrawals of at least this much are reported to the leader by direct message.
+	corpLargeMovementCredits = int64(100000)
+
+	corpWithdrawLimitWindow = 24 * time.Hour
+)
+
+// recordCorpBankTx appends one ledger row. playerID may be empty for movements no player made.
+func recordCorpBankTx(ctx context.Context, tx pgx.Tx, corpID, playerID, kind string, amount, balanceAfter int64, note string) error {
+	var pid any = nil
+	if playerID != "" {
+		pid = playerID
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO corp_bank_transactions(corp_id, player_id, kind, amount, balance_after, note)
+		VALUES ($1,$2,$3,$4,$5,$6)
+	`, corpID, pid, kind, amount, balanceAfter, note)
+	return err
+}
+
+type corpWithdrawPolicy struct {
+	OfficerLimit      int64
+	MemberLimit       int64
+	ApprovalThreshold int64
+}
+
+// dailyLimit is the rolling 24h withdrawal cap for role; 0 means no cap. Leaders are never capped.
+func (wp corpWithdrawPolicy) dailyLimit(role string) int64 {
+	switch strings.ToUpper(strings.TrimSpace(role)) {
+	case CorpRoleOfficer:
+		return wp.OfficerLimit
+	case CorpRoleMember:
+		return wp.MemberLimit
+	default:
+		return 0
+	}
+}
+
+// needsApproval reports whether a non-leader withdrawal of amount must wait for the leader.
+func (wp corpWithdrawPolicy) needsApproval(role string, amount int64) bool {
+	return wp.ApprovalThreshold > 0 && amount > wp.ApprovalThreshold && corpRoleRank(role) < 3
+}
+
+func loadCorpWithdrawPolicy(ctx context.Context, tx pgx.Tx, corpID string) (corpWithdrawPolicy, error) {
+	var wp corpWithdrawPolicy
+	err := tx.QueryRow(ctx, `
+		SELECT withdraw_limit_officer, withdraw_limit_member, withdraw_approval_threshold
+		FROM corporations WHERE id=$1
+	`, corpID).Scan(&wp.OfficerLimit, &wp.MemberLimit, &wp.ApprovalThreshold)
+	return wp, err
+}
+
+// withdrawRoom is how much more a member may take out under a daily limit, counting both what
+// they withdrew in the window and what they are still waiting on in approval requests.
+func withdrawRoom(limit, withdrawn, pending int64) int64 {
+	return max64(0, limit-withdrawn-pending)
+}
+
+// corpWithdrawnToday returns what playerID withdrew from the corp bank in the last 24 hours
+// and the total of their withdrawal requests still awaiting approval.
+func corpWithdrawnToday(ctx context.Context, tx pgx.Tx, corpID, playerID string) (withdrawn, pending int64, err error) {
+	err = tx.QueryRow(ctx, `
+		SELECT
+			COALESCE((
+				SELECT SUM(-amount) FROM corp_bank_transactions
+				WHERE corp_id=$1 AND player_id=$2 AND kind='WITHDRAW' AND created_at > $3
+			), 0),
+			COALESCE((
+				SELECT SUM(amount) FROM corp_withdrawal_requests
+				WHERE corp_id=$1 AND player_id=$2
+			), 0)
+	`, corpID, playerID, time.Now().UTC().Add(-corpWithdrawLimitWindow)).Scan(&withdrawn, &pending)
+	return withdrawn, pending, err
+}
+
+// depositAfterWithdraw is a member's interest-bearing deposit balance after taking amt out of
+// the corp bank. Withdrawals beyond what the member put in leave it at 0.
+func depositAfterWithdraw(balance, amt int64) int64 {
+	if amt >= balance {
+		return 0
+	}
+	return balance - amt
+}
+
+// lowerCorpDeposit reduces playerID's deposit balance for a withdrawal so member interest is
+// only paid on credits still in the corp bank.
+func lowerCorpDeposit(ctx context.Context, tx pgx.Tx, playerID string, amt int64) error {
+	var balance int64
+	err := tx.QueryRow(ctx, "SELECT deposit_balance FROM corp_members WHERE player_id=$1 FOR UPDATE", playerID).Scan(&balance)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, "UPDATE corp_members SET deposit_balance=$2 WHERE player_id=$1", playerID, depositAfterWithdraw(balance, amt))
+	return err
+}
+
+// notifyCorpLeader sends a CORP_BANK message to the corp leader unless they are the sender.
+func notifyCorpLeader(ctx context.Context, tx pgx.Tx, corpID, fromID, subject, body string) error {
+	var leaderID string
+	err := tx.QueryRow(ctx, "SELECT player_id FROM corp_members WHERE corp_id=$1 AND role='LEADER'", corpID).Scan(&leaderID)
+	if errors.Is(err, pgx.ErrNoRows) || leaderID == fromID {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = InsertDirectMessage(ctx, tx, fromID, leaderID, MessageKindCorpBank, subject, body, nil)
+	return err
+}
+
+// corpRequestWithdrawal files a withdrawal above the approval threshold for the leader to answer.
+func corpRequestWithdrawal(ctx context.Context, tx pgx.Tx, p Player, amt int64) (phase2Result, error) {
+	var id int64
+	err := tx.QueryRow(ctx, "INSERT INTO corp_withdrawal_requests(corp_id, player_id, amount) VALUES ($1,$2,$3) RETURNING id", p.CorpID, p.ID, amt).Scan(&id)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	subject := fmt.Sprintf("Withdrawal request #%d: %d credits", id, amt)
+	body := fmt.Sprintf("%s (%s) asks to withdraw %d credits from the %s bank.\n\nUse CORP APPROVE %d or CORP DENY %d.\n", p.Username, p.CorpRole, amt, p.CorpName, id, id)
+	if err := notifyCorpLeader(ctx, tx, p.CorpID, p.ID, subject, body); err != nil {
+		return phase2Result{}, err
+	}
+	msg := fmt.Sprintf("Withdrawals over the approval threshold need the leader's approval. Request #%d for %d credits filed.", id, amt)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+// corpAnswerWithdrawal handles CORP APPROVE/DENY {request id}.
+func corpAnswerWithdrawal(ctx context.Context, tx pgx.Tx, p *Player, arg string, approve bool) (phase2Result, error) {
+	if res := corpRequireLeader(*p, "answer withdrawal requests"); res != nil {
+		return *res, nil
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(arg), "#"), 10, 64)
+	if err != nil || id < 1 {
+		return phase2Result{OK: false, Message: "Usage: CORP APPROVE {request} or CORP DENY {request}", ErrorCode: "INVALID_ARGS"}, nil
+	}
+
+	var requesterID, requester string
+	var amt int64
+	err = tx.QueryRow(ctx, `
+		SELECT r.player_id, u.username, r.amount
+		FROM corp_withdrawal_requests r
+		JOIN players pl ON pl.id = r.player_id
+		JOIN users u ON u.id = pl.user_id
+		WHERE r.id=$1 AND r.corp_id=$2
+		FOR UPDATE OF r
+	`, id, p.CorpID).Scan(&requesterID, &requester, &amt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return phase2Result{OK: false, Message: fmt.Sprintf("No pending withdrawal request #%d.", id), ErrorCode: "NOT_FOUND"}, nil
+	}
+	if err != nil {
+		return phase2Result{}, err
+	}
+
+	var stillMember bool
+	if err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM corp_members WHERE corp_id=$1 AND player_id=$2)", p.CorpID, requesterID).Scan(&stillMember); err != nil {
+		return phase2Result{}, err
+	}
+	if approve && !stillMember {
+		approve = false
+	}
+
+	if approve {
+		var newCredits int64
+		err := tx.QueryRow(ctx, "UPDATE corporations SET credits = credits - $2 WHERE id=$1 AND credits >= $2 RETURNING credits", p.CorpID, amt).Scan(&newCredits)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return phase2Result{OK: false, Message: "Corp bank does not have enough credits.", ErrorCode: "INSUFFICIENT_FUNDS"}, nil
+		}
+		if err != nil {
+			return phase2Result{}, err
+		}
+		if _, err := tx.Exec(ctx, "UPDATE players SET credits = credits + $2 WHERE id=$1", requesterID, amt); err != nil {
+			return phase2Result{}, err
+		}
+		if err := lowerCorpDeposit(ctx, tx, requesterID, amt); err != nil {
+			return phase2Result{}, err
+		}
+		note := fmt.Sprintf("request #%d approved by %s", id, p.Username)
+		if err := recordCorpBankTx(ctx, tx, p.CorpID, requesterID, CorpTxWithdraw, -amt, newCredits, note); err != nil {
+			return phase2Result{}, err
+		}
+		p.CorpCredits = newCredits
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM corp_withdrawal_requests WHERE id=$1", id); err != nil {
+		return phase2Result{}, err
+	}
+
+	msg := fmt.Sprintf("%s denied %s's withdrawal request #%d for %d credits.", p.Username, requester, id, amt)
+	if approve {
+		msg = fmt.Sprintf("%s approved %s's withdrawal request #%d for %d credits.", p.Username, requester, id, amt)
+	}
+	if stillMember {
+		return corpRoleChanged(ctx, tx, *p, msg)
+	}
+	if err := InsertLog(ctx, tx, requesterID, "CORP", msg); err != nil {
+		return phase2Result{}, err
+	}
+	return phase2Result{OK: true, Message: msg + " They are no longer a member."}, nil
+}
+
+// corpSetLimit handles CORP LIMIT {OFFICER|MEMBER|APPROVAL} {credits}; 0 removes the limit.
+func corpSetLimit(ctx context.Context, tx pgx.Tx, p Player, args string) (phase2Result, error) {
+	if res := corpRequireLeader(p, "set withdrawal limits"); res != nil {
+		return *res, nil
+	}
+	usage := phase2Result{OK: false, Message: "Usage: CORP LIMIT {OFFICER|MEMBER|APPROVAL} {credits} (0 = none)", ErrorCode: "INVALID_ARGS"}
+	fields := strings.Fields(strings.ToUpper(args))
+	if len(fields) != 2 {
+		return usage, nil
+	}
+	amt, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || amt < 0 {
+		return usage, nil
+	}
+	var column, what string
+	switch fields[0] {
+	case CorpRoleOfficer:
+		column, what = "withdraw_limit_officer", "Officer daily withdrawal limit"
+	case CorpRoleMember:
+		column, what = "withdraw_limit_member", "Member daily withdrawal limit"
+	case "APPROVAL":
+		column, what = "withdraw_approval_threshold", "Withdrawal approval threshold"
+	default:
+		return usage, nil
+	}
+	if _, err := tx.Exec(ctx, "UPDATE corporations SET "+column+"=$2 WHERE id=$1", p.CorpID, amt); err != nil {
+		return phase2Result{}, err
+	}
+	value := fmt.Sprintf("%d credits", amt)
+	if amt == 0 {
+		value = "none"
+	}
+	return corpRoleChanged(ctx, tx, p, fmt.Sprintf("%s set %s to %s.", p.Username, strings.ToLower(what[:1])+what[1:], value))
+}
+
+func describeWithdrawPolicy(wp corpWithdrawPolicy) string {
+	limit := func(v int64) string {
+		if v == 0 {
+			return "unlimited"
+		}
+		return fmt.Sprintf("%d/day", v)
+	}
+	s := fmt.Sprintf("Withdrawal limits: officers %s, members %s", limit(wp.OfficerLimit), limit(wp.MemberLimit))
+	if wp.ApprovalThreshold > 0 {
+		s += fmt.Sprintf("; leader approval above %d", wp.ApprovalThreshold)
+	}
+	return s
+}
+
+// corpLedger shows the latest n corp bank transactions and any withdrawals awaiting approval.
+func corpLedger(ctx context.Context, tx pgx.Tx, p Player, n int) (phase2Result, error) {
+	if p.CorpID == "" {
+		return phase2Result{OK: false, Message: "You are not in a corporation.", ErrorCode: "NOT_IN_CORP"}, nil
+	}
+	if n < 1 {
+		n = corpLedgerDefault
+	}
+	if n > corpLedgerMax {
+		n = corpLedgerMax
+	}
+
+	lines := []string{fmt.Sprintf("%s bank ledger (balance %d):", p.CorpName, p.CorpCredits)}
+	rows, err := tx.Query(ctx, `
+		SELECT t.created_at, t.kind, t.amount, t.balance_after, t.note, COALESCE(u.username, '')
+		FROM corp_bank_transactions t
+		LEFT JOIN players pl ON pl.id = t.player_id
+		LEFT JOIN users u ON u.id = pl.user_id
+		WHERE t.corp_id=$1
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT $2
+	`, p.CorpID, n)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	now := time.Now()
+	count := 0
+	for rows.Next() {
+		var at time.Time
+		var kind, note, who string
+		var amount, balance int64
+		if err := rows.Scan(&at, &kind, &amount, &balance, &note, &who); err != nil {
+			rows.Close()
+			return phase2Result{}, err
+		}
+		line := fmt.Sprintf("- [%s ago] %s %+d -> %d", formatAgeShort(now, at), kind, amount, balance)
+		if who != "" {
+			line += " by " + who
+		}
+		if note != "" {
+			line += " (" + note + ")"
+		}
+		lines = append(lines, line)
+		count++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return phase2Result{}, err
+	}
+	if count == 0 {
+		lines = append(lines, "- no transactions yet")
+	}
+
+	reqRows, err := tx.Query(ctx, `
+		SELECT r.id, u.username, r.amount, r.created_at
+		FROM corp_withdrawal_requests r
+		JOIN players pl ON pl.id = r.player_id
+		JOIN users u ON u.id = pl.user_id
+		WHERE r.corp_id=$1
+		ORDER BY r.created_at ASC
+	`, p.CorpID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	defer reqRows.Close()
+	header := false
+	for reqRows.Next() {
+		var id, amount int64
+		var who string
+		var at time.Time
+		if err := reqRows.Scan(&id, &who, &amount, &at); err != nil {
+			return phase2Result{}, err
+		}
+		if !header {
+			lines = append(lines, "Awaiting leader approval:")
+			header = true
+		}
+		lines = append(lines, fmt.Sprintf("- #%d %s wants %d credits (%s ago)", id, who, amount, formatAgeShort(now, at)))
+	}
+	if err := reqRows.Err(); err != nil {
+		return phase2Result{}, err
+	}
+	return phase2Result{OK: true, Message: strings.Join(lines, "\n")}, nil
+}
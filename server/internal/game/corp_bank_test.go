@@ -0,0 +1,74 @@
+package game
+
+import "testing"
+
+func TestCorpWithdrawPolicy(t *testing.T) {
+	wp := corpWithdrawPolicy{OfficerLimit: 5000, MemberLimit: 500, ApprovalThreshold: 2000}
+	if got := wp.dailyLimit(CorpRoleOfficer); got != 5000 {
+		t.Fatalf("officer limit: got %d", got)
+	}
+	if got := wp.dailyLimit("member"); got != 500 {
+		t.Fatalf("member limit: got %d", got)
+	}
+	if got := wp.dailyLimit(CorpRoleLeader); got != 0 {
+		t.Fatalf("leader should be unlimited, got %d", got)
+	}
+	if wp.needsApproval(CorpRoleOfficer, 2000) || !wp.needsApproval(CorpRoleOfficer, 2001) {
+		t.Fatalf("approval should start above the threshold")
+	}
+	if wp.needsApproval(CorpRoleLeader, 1_000_000) {
+		t.Fatalf("leader never needs approval")
+	}
+	if (corpWithdrawPolicy{}).needsApproval(CorpRoleMember, 1_000_000) {
+		t.Fatalf("no threshold means no approval step")
+	}
+}
+
+func TestDescribeWithdrawPolicy(t *testing.T) {
+	if got := describeWithdrawPolicy(corpWithdrawPolicy{}); got != "Withdrawal limits: officers unlimited, members unlimited" {
+		t.Fatalf("got %q", got)
+	}
+	got := describeWithdrawPolicy(corpWithdrawPolicy{OfficerLimit: 100, ApprovalThreshold: 50})
+	if got != "Withdrawal limits: officers 100/day, members unlimited; leader approval above 50" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestWithdrawLowersInterestBearingDeposit(t *testing.T) {
+	const bps = 100
+	deposit := int64(10000)
+	if got := interestFor(deposit, bps); got != 100 {
+		t.Fatalf("before withdrawal: got %d", got)
+	}
+	deposit = depositAfterWithdraw(deposit, 4000)
+	if deposit != 6000 {
+		t.Fatalf("partial withdrawal: got %d", deposit)
+	}
+	if got := interestFor(deposit, bps); got != 60 {
+		t.Fatalf("interest after withdrawal: got %d", got)
+	}
+	deposit = depositAfterWithdraw(deposit, 50000)
+	if deposit != 0 {
+		t.Fatalf("withdrawing more than deposited should floor at 0, got %d", deposit)
+	}
+	if got := interestFor(deposit, bps); got != 0 {
+		t.Fatalf("no interest once everything is withdrawn, got %d", got)
+	}
+}
+
+func TestWithdrawRoomCountsPendingRequests(t *testing.T) {
+	cases := []struct {
+		limit, withdrawn, pending, want int64
+	}{
+		{10000, 0, 0, 10000},
+		{10000, 3000, 0, 7000},
+		{10000, 0, 4000, 6000},
+		{10000, 3000, 4000, 3000},
+		{10000, 6000, 6000, 0},
+	}
+	for _, c := range cases {
+		if got := withdrawRoom(c.limit, c.withdrawn, c.pending); got != c.want {
+			t.Errorf("withdrawRoom(%d, %d, %d) = %d, want %d", c.limit, c.withdrawn, c.pending, got, c.want)
+		}
+	}
+}
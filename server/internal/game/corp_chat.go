@@ -0,0 +1,183 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotInCorp = errors.New("not in a corporation")
+	ErrForbidden = errors.New("forbidden")
+)
+
+// CorpChatMessage is one CORP SAY line.
+type CorpChatMessage struct {
+	ID        int64     `json:"id"`
+	From      string    `json:"from"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+	Unread    bool      `json:"unread"`
+}
+
+// CorpChatPage is a page of corp chat, newest first. NextBefore is the cursor for the next
+// (older) page and is nil on the last page.
+type CorpChatPage struct {
+	Messages   []CorpChatMessage `json:"messages"`
+	NextBefore *int64            `json:"next_before,omitempty"`
+	LastReadID int64             `json:"last_read_id"`
+	Unread     int               `json:"unread"`
+}
+
+func loadCorpChatMember(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, playerID string) (corpID string, readID int64, err error) {
+	err = q.QueryRow(ctx, "SELECT corp_id, chat_read_id FROM corp_members WHERE player_id=$1", playerID).Scan(&corpID, &readID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", 0, ErrNotInCorp
+	}
+	return corpID, readID, err
+}
+
+// LoadCorpChat returns up to limit messages of the player's corp chat older than before
+// (0 = from the newest). Messages by others newer than the member's read marker are unread.
+func LoadCorpChat(ctx context.Context, pool *pgxpool.Pool, playerID string, before int64, limit int) (CorpChatPage, error) {
+	corpID, readID, err := loadCorpChatMember(ctx, pool, playerID)
+	if err != nil {
+		return CorpChatPage{}, err
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT m.id, u.username, m.message, m.created_at, m.player_id
+		FROM corp_messages m
+		JOIN players pl ON pl.id = m.player_id
+		JOIN users u ON u.id = pl.user_id
+		WHERE m.corp_id=$1 AND ($2 = 0 OR m.id < $2)
+		ORDER BY m.id DESC
+		LIMIT $3
+	`, corpID, before, limit+1)
+	if err != nil {
+		return CorpChatPage{}, err
+	}
+	defer rows.Close()
+
+	page := CorpChatPage{Messages: []CorpChatMessage{}, LastReadID: readID}
+	for rows.Next() {
+		var m CorpChatMessage
+		var from string
+		if err := rows.Scan(&m.ID, &m.From, &m.Message, &m.CreatedAt, &from); err != nil {
+			return CorpChatPage{}, err
+		}
+		m.Unread = corpChatUnread(m.ID, from, readID, playerID)
+		page.Messages = append(page.Messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return CorpChatPage{}, err
+	}
+	page.Messages, page.NextBefore = trimCorpChatPage(page.Messages, limit)
+
+	err = pool.QueryRow(ctx, `
+		SELECT COUNT(1) FROM corp_messages WHERE corp_id=$1 AND id > $2 AND player_id <> $3
+	`, corpID, readID, playerID).Scan(&page.Unread)
+	return page, err
+}
+
+// MarkCorpChatRead moves the member's read marker up to upTo (0 = the newest message). The
+// marker never moves backwards. It returns the new marker.
+func MarkCorpChatRead(ctx context.Context, pool *pgxpool.Pool, playerID string, upTo int64) (int64, error) {
+	corpID, readID, err := loadCorpChatMember(ctx, pool, playerID)
+	if err != nil {
+		return 0, err
+	}
+	var newest int64
+	if err := pool.QueryRow(ctx, "SELECT COALESCE(MAX(id), 0) FROM corp_messages WHERE corp_id=$1", corpID).Scan(&newest); err != nil {
+		return 0, err
+	}
+	upTo, moved := nextCorpChatReadID(readID, upTo, newest)
+	if !moved {
+		return readID, nil
+	}
+	_, err = pool.Exec(ctx, "UPDATE corp_members SET chat_read_id=GREATEST(chat_read_id, $2) WHERE player_id=$1", playerID, upTo)
+	return upTo, err
+}
+
+// DeleteCorpChatMessage removes a message from the player's corp chat. Authors may delete their
+// own messages; anyone else needs the MODERATE permission and a higher role than the author.
+func DeleteCorpChatMessage(ctx context.Context, pool *pgxpool.Pool, playerID string, messageID int64) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	p, err := LoadPlayerForUpdate(ctx, tx, playerID)
+	if err != nil {
+		return err
+	}
+	if p.CorpID == "" {
+		return ErrNotInCorp
+	}
+
+	var authorID, authorRole string
+	err = tx.QueryRow(ctx, `
+		SELECT m.player_id, COALESCE(cm.role, '')
+		FROM corp_messages m
+		LEFT JOIN corp_members cm ON cm.player_id = m.player_id AND cm.corp_id = m.corp_id
+		WHERE m.id=$1 AND m.corp_id=$2
+	`, messageID, p.CorpID).Scan(&authorID, &authorRole)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !canDeleteCorpChat(p, authorID, authorRole) {
+		return ErrForbidden
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM corp_messages WHERE id=$1", messageID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// corpChatUnread reports whether a message by from is unread for playerID at marker readID.
+// Members never have unread messages of their own.
+func corpChatUnread(id int64, from string, readID int64, playerID string) bool {
+	return id > readID && from != playerID
+}
+
+// trimCorpChatPage cuts a page fetched with limit+1 rows down to limit and returns the cursor
+// for the next (older) page, or nil when there is none.
+func trimCorpChatPage(msgs []CorpChatMessage, limit int) ([]CorpChatMessage, *int64) {
+	if limit < 1 || len(msgs) <= limit {
+		return msgs, nil
+	}
+	msgs = msgs[:limit]
+	next := msgs[limit-1].ID
+	return msgs, &next
+}
+
+// nextCorpChatReadID is the read marker after marking up to upTo (0 or past the newest message
+// means the newest). moved is false when the marker would not advance.
+func nextCorpChatReadID(readID, upTo, newest int64) (int64, bool) {
+	if upTo <= 0 || upTo > newest {
+		upTo = newest
+	}
+	if upTo <= readID {
+		return readID, false
+	}
+	return upTo, true
+}
+
+// canDeleteCorpChat reports whether p may delete a message by authorID, whose current corp role
+// is authorRole ("" once they left the corp).
+func canDeleteCorpChat(p Player, authorID, authorRole string) bool {
+	if authorID == p.ID {
+		return true
+	}
+	return p.corpAllows(CorpPermModerate) && corpRoleRank(authorRole) < corpRoleRank(p.CorpRole)
+}
@@ -0,0 +1,78 @@
+package game
+
+import "testing"
+
+func TestTrimCorpChatPage(t *testing.T) {
+	msgs := []CorpChatMessage{{ID: 9}, {ID: 7}, {ID: 4}}
+	page, next := trimCorpChatPage(msgs, 2)
+	if len(page) != 2 || next == nil || *next != 7 {
+		t.Fatalf("more rows than the limit: got %d messages, next %v", len(page), next)
+	}
+	page, next = trimCorpChatPage(msgs, 3)
+	if len(page) != 3 || next != nil {
+		t.Fatalf("last page: got %d messages, next %v", len(page), next)
+	}
+	page, next = trimCorpChatPage(nil, 50)
+	if len(page) != 0 || next != nil {
+		t.Fatalf("empty chat: got %d messages, next %v", len(page), next)
+	}
+}
+
+func TestCorpChatUnread(t *testing.T) {
+	if !corpChatUnread(11, "bob", 10, "alice") {
+		t.Fatalf("newer message by someone else should be unread")
+	}
+	if corpChatUnread(10, "bob", 10, "alice") {
+		t.Fatalf("message at the marker is read")
+	}
+	if corpChatUnread(11, "alice", 10, "alice") {
+		t.Fatalf("own messages are never unread")
+	}
+}
+
+func TestNextCorpChatReadID(t *testing.T) {
+	cases := []struct {
+		readID, upTo, newest int64
+		want                 int64
+		moved                bool
+	}{
+		{5, 0, 20, 20, true},
+		{5, 12, 20, 12, true},
+		{5, 99, 20, 20, true},
+		{15, 12, 20, 15, false},
+		{20, 0, 20, 20, false},
+		{0, 0, 0, 0, false},
+	}
+	for _, c := range cases {
+		got, moved := nextCorpChatReadID(c.readID, c.upTo, c.newest)
+		if got != c.want || moved != c.moved {
+			t.Fatalf("read %d up to %d newest %d: got %d %v", c.readID, c.upTo, c.newest, got, moved)
+		}
+	}
+}
+
+func TestCanDeleteCorpChat(t *testing.T) {
+	member := Player{ID: "m", CorpID: "c", CorpRole: CorpRoleMember}
+	officer := Player{ID: "o", CorpID: "c", CorpRole: CorpRoleOfficer}
+	leader := Player{ID: "l", CorpID: "c", CorpRole: CorpRoleLeader}
+
+	if !canDeleteCorpChat(member, "m", CorpRoleMember) {
+		t.Fatalf("authors may delete their own messages")
+	}
+	if canDeleteCorpChat(member, "x", CorpRoleMember) {
+		t.Fatalf("members cannot moderate by default")
+	}
+	if !canDeleteCorpChat(officer, "m", CorpRoleMember) || !canDeleteCorpChat(officer, "gone", "") {
+		t.Fatalf("officers moderate members and former members")
+	}
+	if canDeleteCorpChat(officer, "o2", CorpRoleOfficer) || canDeleteCorpChat(officer, "l", CorpRoleLeader) {
+		t.Fatalf("officers cannot delete equal or higher roles")
+	}
+	if !canDeleteCorpChat(leader, "o", CorpRoleOfficer) {
+		t.Fatalf("leader moderates officers")
+	}
+	muted := Player{ID: "o", CorpID: "c", CorpRole: CorpRoleOfficer, CorpPerms: CorpPermissions{CorpPermModerate: CorpRoleLeader}}
+	if canDeleteCorpChat(muted, "m", CorpRoleMember) {
+		t.Fatalf("MODERATE raised to leader should stop officers")
+	}
+}
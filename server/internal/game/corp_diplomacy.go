@@ -0,0 +1,382 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Diplomatic states between two corporations. Pairs without a corp_relations row are NEUTRAL.
+const (
+	CorpRelAllied  = "ALLIED"
+	CorpRelNeutral = "NEUTRAL"
+	CorpRelNAP     = "NAP"
+	CorpRelWar     = "WAR"
+)
+
+// A non-aggression pact must stand this long before either side may break it.
+const napBreakCooldown = 24 * time.Hour
+
+// hostileMineOwnerSQL is a WHERE clause for mines (with $3 the player's corp id) that are hostile
+// to the player: unaffiliated, another corp's, and not laid by a corp allied with or under a NAP
+// with the player's corp.
+const hostileMineOwnerSQL = `(owner_corp_id IS NULL OR $3 = '' OR (owner_corp_id <> $3 AND NOT EXISTS (
+				SELECT 1 FROM corp_relations r
+				WHERE r.state IN ('ALLIED','NAP')
+					AND ((r.corp_a = owner_corp_id AND r.corp_b = $3) OR (r.corp_b = owner_corp_id AND r.corp_a = $3))
+			)))`
+
+type corpRelation struct {
+	CorpA          string
+	CorpB          string
+	State          string
+	SharedPlanets  bool
+	Since          time.Time
+	ProposedState  string
+	ProposedShared bool
+	ProposedBy     string
+}
+
+func corpPair(x, y string) (string, string) {
+	if x < y {
+		return x, y
+	}
+	return y, x
+}
+
+func describeCorpRelation(state string, shared bool) string {
+	switch state {
+	case CorpRelAllied:
+		if shared {
+			return "an alliance with shared planets"
+		}
+		return "an alliance"
+	case CorpRelNAP:
+		return "a non-aggression pact"
+	case CorpRelWar:
+		return "war"
+	default:
+		return "peace"
+	}
+}
+
+// napBreakWait is how long until a NAP signed at since may be broken.
+func napBreakWait(since, now time.Time) time.Duration {
+	if wait := since.Add(napBreakCooldown).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// parseDiplomacyProposal splits "{corp...} {ALLIED [PLANETS]|NAP|NEUTRAL|WAR}".
+func parseDiplomacyProposal(fields []string) (corp, state string, shared bool, ok bool) {
+	n := len(fields)
+	if n >= 3 && strings.EqualFold(fields[n-1], "PLANETS") && strings.EqualFold(fields[n-2], CorpRelAllied) {
+		return strings.Join(fields[:n-2], " "), CorpRelAllied, true, true
+	}
+	if n < 2 {
+		return "", "", false, false
+	}
+	state = strings.ToUpper(fields[n-1])
+	switch state {
+	case CorpRelAllied, CorpRelNAP, CorpRelNeutral, CorpRelWar:
+		return strings.Join(fields[:n-1], " "), state, false, true
+	default:
+		return "", "", false, false
+	}
+}
+
+func loadCorpRelation(ctx context.Context, tx pgx.Tx, x, y string) (corpRelation, error) {
+	a, b := corpPair(x, y)
+	rel := corpRelation{CorpA: a, CorpB: b, State: CorpRelNeutral}
+	err := tx.QueryRow(ctx, `
+		SELECT state, shared_planets, since, proposed_state, proposed_shared_planets, COALESCE(proposed_by, '')
+		FROM corp_relations
+		WHERE corp_a=$1 AND corp_b=$2
+		FOR UPDATE
+	`, a, b).Scan(&rel.State, &rel.SharedPlanets, &rel.Since, &rel.ProposedState, &rel.ProposedShared, &rel.ProposedBy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return rel, nil
+	}
+	return rel, err
+}
+
+func saveCorpRelation(ctx context.Context, tx pgx.Tx, rel corpRelation) error {
+	var proposedBy any = nil
+	var proposedAt any = nil
+	if rel.ProposedState != "" {
+		proposedBy = rel.ProposedBy
+		proposedAt = time.Now().UTC()
+	}
+	if rel.Since.IsZero() {
+		rel.Since = time.Now().UTC()
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO corp_relations(corp_a, corp_b, state, shared_planets, since, proposed_state, proposed_shared_planets, proposed_by, proposed_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		ON CONFLICT (corp_a, corp_b) DO UPDATE SET
+			state = EXCLUDED.state,
+			shared_planets = EXCLUDED.shared_planets,
+			since = EXCLUDED.since,
+			proposed_state = EXCLUDED.proposed_state,
+			proposed_shared_planets = EXCLUDED.proposed_shared_planets,
+			proposed_by = EXCLUDED.proposed_by,
+			proposed_at = EXCLUDED.proposed_at
+	`, rel.CorpA, rel.CorpB, rel.State, rel.SharedPlanets, rel.Since, rel.ProposedState, rel.ProposedShared, proposedBy, proposedAt)
+	return err
+}
+
+// setCorpRelation moves the pair to a new state and clears any pending proposal.
+func setCorpRelation(ctx context.Context, tx pgx.Tx, rel corpRelation, state string, shared bool) error {
+	rel.State = state
+	rel.SharedPlanets = state == CorpRelAllied && shared
+	rel.Since = time.Now().UTC()
+	rel.ProposedState = ""
+	rel.ProposedShared = false
+	rel.ProposedBy = ""
+	return saveCorpRelation(ctx, tx, rel)
+}
+
+// loadPlanetAllies returns the corps whose planets members of corpID may use.
+func loadPlanetAllies(ctx context.Context, tx pgx.Tx, corpID string) (map[string]bool, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT CASE WHEN corp_a=$1 THEN corp_b ELSE corp_a END
+		FROM corp_relations
+		WHERE (corp_a=$1 OR corp_b=$1) AND state='ALLIED' AND shared_planets
+	`, corpID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	allies := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		allies[id] = true
+	}
+	return allies, rows.Err()
+}
+
+// executeCorpDiplomacy handles CORP DIPLOMACY [PROPOSE {corp} {state} | ACCEPT {corp} | BREAK {corp}].
+func executeCorpDiplomacy(ctx context.Context, tx pgx.Tx, p Player, args string) (phase2Result, error) {
+	if p.CorpID == "" {
+		return phase2Result{OK: false, Message: "You are not in a corporation.", ErrorCode: "NOT_IN_CORP"}, nil
+	}
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return corpDiplomacyInfo(ctx, tx, p)
+	}
+	action := strings.ToUpper(fields[0])
+	if action == "INFO" {
+		return corpDiplomacyInfo(ctx, tx, p)
+	}
+	if !p.corpAllows(CorpPermDiplomacy) {
+		return phase2Result{OK: false, Message: "Your corp role does not allow diplomacy.", ErrorCode: "FORBIDDEN"}, nil
+	}
+
+	rest := fields[1:]
+	name := strings.Join(rest, " ")
+	state, shared := "", false
+	if action == "PROPOSE" {
+		var ok bool
+		name, state, shared, ok = parseDiplomacyProposal(rest)
+		if !ok {
+			return phase2Result{OK: false, Message: "Usage: CORP DIPLOMACY PROPOSE {corp} {ALLIED [PLANETS]|NAP|NEUTRAL|WAR}", ErrorCode: "INVALID_ARGS"}, nil
+		}
+	}
+	if strings.TrimSpace(name) == "" {
+		return phase2Result{OK: false, Message: "Usage: CORP DIPLOMACY {PROPOSE|ACCEPT|BREAK} {corp} ...", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	other, found, err := loadCorpByName(ctx, tx, name)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !found {
+		return phase2Result{OK: false, Message: "Corporation not found.", ErrorCode: "NOT_FOUND"}, nil
+	}
+	if other.ID == p.CorpID {
+		return phase2Result{OK: false, Message: "That is your own corporation.", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	rel, err := loadCorpRelation(ctx, tx, p.CorpID, other.ID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+
+	switch action {
+	case "PROPOSE":
+		return corpPropose(ctx, tx, p, other, rel, state, shared)
+	case "ACCEPT":
+		return corpAcceptProposal(ctx, tx, p, other, rel)
+	case "BREAK":
+		return corpBreak(ctx, tx, p, other, rel)
+	default:
+		return phase2Result{OK: false, Message: "Unknown CORP DIPLOMACY subcommand.", ErrorCode: "UNKNOWN_SUBCOMMAND"}, nil
+	}
+}
+
+func corpPropose(ctx context.Context, tx pgx.Tx, p Player, other corpRef, rel corpRelation, state string, shared bool) (phase2Result, error) {
+	if rel.State == state && (state != CorpRelAllied || rel.SharedPlanets == shared) {
+		return phase2Result{OK: false, Message: fmt.Sprintf("You are already at %s with %s.", describeCorpRelation(rel.State, rel.SharedPlanets), other.Name), ErrorCode: "INVALID_ARGS"}, nil
+	}
+	if rel.State == CorpRelAllied || rel.State == CorpRelNAP {
+		if state == CorpRelWar || state == CorpRelNeutral {
+			msg := fmt.Sprintf("You have %s with %s; use CORP DIPLOMACY BREAK %s first.", describeCorpRelation(rel.State, rel.SharedPlanets), other.Name, other.Name)
+			return phase2Result{OK: false, Message: msg, ErrorCode: "TREATY_IN_FORCE"}, nil
+		}
+	}
+
+	if state == CorpRelWar {
+		// War needs no acceptance.
+		if err := setCorpRelation(ctx, tx, rel, CorpRelWar, false); err != nil {
+			return phase2Result{}, err
+		}
+		headline := fmt.Sprintf("%s has declared war on %s!", p.CorpName, other.Name)
+		if err := postGalaxyNews(ctx, tx, NewsKindWar, headline); err != nil {
+			return phase2Result{}, err
+		}
+		return corpDiplomacyChanged(ctx, tx, p.CorpID, other.ID, headline)
+	}
+
+	if rel.ProposedBy == other.ID && rel.ProposedState == state && rel.ProposedShared == shared {
+		// They already offered exactly this.
+		return corpAcceptProposal(ctx, tx, p, other, rel)
+	}
+
+	rel.ProposedState = state
+	rel.ProposedShared = state == CorpRelAllied && shared
+	rel.ProposedBy = p.CorpID
+	if err := saveCorpRelation(ctx, tx, rel); err != nil {
+		return phase2Result{}, err
+	}
+	offer := describeCorpRelation(state, rel.ProposedShared)
+	if err := logToCorp(ctx, tx, other.ID, fmt.Sprintf("%s proposes %s. Use CORP DIPLOMACY ACCEPT %s to agree.", p.CorpName, offer, p.CorpName)); err != nil {
+		return phase2Result{}, err
+	}
+	msg := fmt.Sprintf("%s proposed %s to %s.", p.Username, offer, other.Name)
+	if err := logToCorp(ctx, tx, p.CorpID, msg); err != nil {
+		return phase2Result{}, err
+	}
+	return phase2Result{OK: true, Message: msg}, nil
+}
+
+func corpAcceptProposal(ctx context.Context, tx pgx.Tx, p Player, other corpRef, rel corpRelation) (phase2Result, error) {
+	if rel.ProposedState == "" || rel.ProposedBy != other.ID {
+		return phase2Result{OK: false, Message: fmt.Sprintf("%s has not proposed anything.", other.Name), ErrorCode: "NOT_FOUND"}, nil
+	}
+	state, shared := rel.ProposedState, rel.ProposedShared
+	if err := setCorpRelation(ctx, tx, rel, state, shared); err != nil {
+		return phase2Result{}, err
+	}
+	var headline string
+	switch state {
+	case CorpRelAllied:
+		headline = fmt.Sprintf("%s and %s have formed %s.", other.Name, p.CorpName, describeCorpRelation(state, shared))
+	case CorpRelNAP:
+		headline = fmt.Sprintf("%s and %s have signed a non-aggression pact.", other.Name, p.CorpName)
+	default:
+		headline = fmt.Sprintf("%s and %s have made peace.", other.Name, p.CorpName)
+	}
+	if err := postGalaxyNews(ctx, tx, NewsKindTreaty, headline); err != nil {
+		return phase2Result{}, err
+	}
+	return corpDiplomacyChanged(ctx, tx, p.CorpID, other.ID, headline)
+}
+
+// corpBreak ends an alliance or NAP, or withdraws or declines a pending proposal.
+func corpBreak(ctx context.Context, tx pgx.Tx, p Player, other corpRef, rel corpRelation) (phase2Result, error) {
+	switch rel.State {
+	case CorpRelAllied, CorpRelNAP:
+		if rel.State == CorpRelNAP {
+			if wait := napBreakWait(rel.Since, time.Now()); wait > 0 {
+				msg := fmt.Sprintf("The non-aggression pact with %s cannot be broken for another %s.", other.Name, formatDurationShort(wait))
+				return phase2Result{OK: false, Message: msg, ErrorCode: "NAP_COOLDOWN"}, nil
+			}
+		}
+		was := describeCorpRelation(rel.State, rel.SharedPlanets)
+		if err := setCorpRelation(ctx, tx, rel, CorpRelNeutral, false); err != nil {
+			return phase2Result{}, err
+		}
+		headline := fmt.Sprintf("%s has ended %s with %s.", p.CorpName, was, other.Name)
+		if err := postGalaxyNews(ctx, tx, NewsKindTreaty, headline); err != nil {
+			return phase2Result{}, err
+		}
+		return corpDiplomacyChanged(ctx, tx, p.CorpID, other.ID, headline)
+	}
+
+	if rel.ProposedState == "" {
+		return phase2Result{OK: false, Message: fmt.Sprintf("There is no treaty or proposal with %s to break.", other.Name), ErrorCode: "NOT_FOUND"}, nil
+	}
+	offer := describeCorpRelation(rel.ProposedState, rel.ProposedShared)
+	rel.ProposedState = ""
+	rel.ProposedShared = false
+	rel.ProposedBy = ""
+	if err := saveCorpRelation(ctx, tx, rel); err != nil {
+		return phase2Result{}, err
+	}
+	return corpDiplomacyChanged(ctx, tx, p.CorpID, other.ID, fmt.Sprintf("The proposal of %s between %s and %s was withdrawn.", offer, p.CorpName, other.Name))
+}
+
+// corpDiplomacyChanged logs msg to both corps and returns it as the result.
+func corpDiplomacyChanged(ctx context.Context, tx pgx.Tx, corpID, otherID, msg string) (phase2Result, error) {
+	for _, id := range []string{corpID, otherID} {
+		if err := logToCorp(ctx, tx, id, msg); err != nil {
+			return phase2Result{}, err
+		}
+	}
+	return phase2Result{OK: true, Message: msg}, nil
+}
+
+func corpDiplomacyInfo(ctx context.Context, tx pgx.Tx, p Player) (phase2Result, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT c.name, r.state, r.shared_planets, r.since, r.proposed_state, r.proposed_shared_planets, COALESCE(r.proposed_by, '')
+		FROM corp_relations r
+		JOIN corporations c ON c.id = CASE WHEN r.corp_a=$1 THEN r.corp_b ELSE r.corp_a END
+		WHERE (r.corp_a=$1 OR r.corp_b=$1) AND (r.state <> 'NEUTRAL' OR r.proposed_state <> '')
+		ORDER BY c.name
+	`, p.CorpID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	lines := []string{fmt.Sprintf("Diplomacy for %s (corps not listed are NEUTRAL):", p.CorpName)}
+	for rows.Next() {
+		var name string
+		var rel corpRelation
+		if err := rows.Scan(&name, &rel.State, &rel.SharedPlanets, &rel.Since, &rel.ProposedState, &rel.ProposedShared, &rel.ProposedBy); err != nil {
+			return phase2Result{}, err
+		}
+		line := fmt.Sprintf("- %s: %s", name, rel.State)
+		if rel.State == CorpRelAllied && rel.SharedPlanets {
+			line += " (shared planets)"
+		}
+		if rel.State == CorpRelNAP {
+			if wait := napBreakWait(rel.Since, now); wait > 0 {
+				line += fmt.Sprintf(" (breakable in %s)", formatDurationShort(wait))
+			}
+		}
+		if rel.ProposedState != "" {
+			offer := describeCorpRelation(rel.ProposedState, rel.ProposedShared)
+			if rel.ProposedBy == p.CorpID {
+				line += fmt.Sprintf("; you proposed %s", offer)
+			} else {
+				line += fmt.Sprintf("; they propose %s", offer)
+			}
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return phase2Result{}, err
+	}
+	if len(lines) == 1 {
+		lines = append(lines, "- none")
+	}
+	return phase2Result{OK: true, Message: strings.Join(lines, "\n")}, nil
+}
@@ -0,0 +1,69 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestParseDiplomacyProposal(t *testing.T) {
+	cases := []struct {
+		in     string
+		corp   string
+		state  string
+		shared bool
+		ok     bool
+	}{
+		{"Red Fleet war", "Red Fleet", CorpRelWar, false, true},
+		{"Traders NAP", "Traders", CorpRelNAP, false, true},
+		{"Blue Sun ALLIED planets", "Blue Sun", CorpRelAllied, true, true},
+		{"Blue Sun ALLIED", "Blue Sun", CorpRelAllied, false, true},
+		{"PLANETS", "", "", false, false},
+		{"Blue Sun FRIENDS", "", "", false, false},
+	}
+	for _, c := range cases {
+		corp, state, shared, ok := parseDiplomacyProposal(strings.Fields(c.in))
+		if ok != c.ok || corp != c.corp || state != c.state || shared != c.shared {
+			t.Fatalf("%q: got %q %q %v %v", c.in, corp, state, shared, ok)
+		}
+	}
+}
+
+func TestNapBreakWait(t *testing.T) {
+	signed := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := napBreakWait(signed, signed.Add(time.Hour)); got != napBreakCooldown-time.Hour {
+		t.Fatalf("got %v", got)
+	}
+	if got := napBreakWait(signed, signed.Add(napBreakCooldown+time.Minute)); got != 0 {
+		t.Fatalf("expired cooldown: got %v", got)
+	}
+}
+
+func TestCorpPairIsOrdered(t *testing.T) {
+	a, b := corpPair("zeta", "alpha")
+	if a != "alpha" || b != "zeta" {
+		t.Fatalf("got %q %q", a, b)
+	}
+}
+
+func TestAlliedPlanetAccessIsNotOwnership(t *testing.T) {
+	ally := planetForUpdate{OwnerCorpID: pgtype.Text{String: "blue", Valid: true}}
+	own := planetForUpdate{OwnerCorpID: pgtype.Text{String: "red", Valid: true}}
+	p := Player{ID: "p1", CorpID: "red", CorpRole: CorpRoleMember, CorpPlanetAllies: map[string]bool{"blue": true}}
+
+	if !canAccessPlanet(p, ally) {
+		t.Fatalf("planet-sharing allies should have access")
+	}
+	if ownsPlanet(p, ally) {
+		t.Fatalf("an allied planet is not owned: it must not anchor gates or count as already controlled")
+	}
+	if !ownsPlanet(p, own) || !canAccessPlanet(p, own) {
+		t.Fatalf("own corp planet should be owned and accessible")
+	}
+	personal := planetForUpdate{OwnerPlayerID: pgtype.Text{String: "p1", Valid: true}}
+	if !ownsPlanet(p, personal) || ownsPlanet(Player{ID: "p2"}, personal) {
+		t.Fatalf("personal ownership wrong")
+	}
+}
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// corpSetIntelPool turns pooling of all members' port intel on or off: CORP INTEL {ON|OFF}.
+func corpSetIntelPool(ctx context.Context, tx pgx.Tx, p Player, arg string) (phase2Result, error) {
+	if res := corpRequireLeader(p, "change intel sharing"); res != nil {
+		return *res, nil
+	}
+	var on bool
+	switch strings.ToUpper(strings.TrimSpace(arg)) {
+	case "ON":
+		on = true
+	case "OFF":
+		on = false
+	default:
+		return phase2Result{OK: false, Message: "Usage: CORP INTEL {ON|OFF}", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	if _, err := tx.Exec(ctx, "UPDATE corporations SET share_intel=$2 WHERE id=$1", p.CorpID, on); err != nil {
+		return phase2Result{}, err
+	}
+	msg := fmt.Sprintf("%s turned off pooled market intel; only scans pushed with CORP SHARE INTEL are shared.", p.Username)
+	if on {
+		msg = fmt.Sprintf("%s turned on pooled market intel; MARKET and ROUTE now use every member's scans.", p.Username)
+	}
+	return corpRoleChanged(ctx, tx, p, msg)
+}
+
+// parseIntelSectors reads "ALL" or a list of sector ids.
+func parseIntelSectors(args []string) (ids []int, all bool, ok bool) {
+	if len(args) == 1 && strings.EqualFold(args[0], "ALL") {
+		return nil, true, true
+	}
+	for _, a := range args {
+		id, err := strconv.Atoi(strings.Trim(a, ","))
+		if err != nil || id < 1 {
+			return nil, false, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, false, len(ids) > 0
+}
+
+// corpShareIntel pushes the player's scans of the given sectors to the corp: CORP SHARE INTEL {sector...|ALL}.
+func corpShareIntel(ctx context.Context, tx pgx.Tx, p Player, args string) (phase2Result, error) {
+	if p.CorpID == "" {
+		return phase2Result{OK: false, Message: "You are not in a corporation.", ErrorCode: "NOT_IN_CORP"}, nil
+	}
+	fields := strings.Fields(args)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], "INTEL") {
+		return phase2Result{OK: false, Message: "Usage: CORP SHARE INTEL {sector...|ALL}", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	ids, all, ok := parseIntelSectors(fields[1:])
+	if !ok {
+		return phase2Result{OK: false, Message: "Usage: CORP SHARE INTEL {sector...|ALL}", ErrorCode: "INVALID_ARGS"}, nil
+	}
+
+	q := "UPDATE player_sector_intel SET shared=true WHERE player_id=$1 AND NOT shared AND ($2 OR sector_id = ANY($3)) RETURNING sector_id"
+	rows, err := tx.Query(ctx, q, p.ID, all, ids)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	var shared []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return phase2Result{}, err
+		}
+		shared = append(shared, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return phase2Result{}, err
+	}
+	if len(shared) == 0 {
+		return phase2Result{OK: false, Message: "No unshared port intel for those sectors. SCAN ports first.", ErrorCode: "NO_INTEL"}, nil
+	}
+
+	msg := fmt.Sprintf("%s shared port intel for %d sectors.", p.Username, len(shared))
+	if len(shared) <= 10 {
+		sort.Ints(shared)
+		parts := make([]string, 0, len(shared))
+		for _, id := range shared {
+			parts = append(parts, strconv.Itoa(id))
+		}
+		msg = fmt.Sprintf("%s shared port intel for sector(s) %s.", p.Username, strings.Join(parts, ", "))
+	}
+	return corpRoleChanged(ctx, tx, p, msg)
+}
+
+// unshareIntel withdraws a departing member's pushed scans from the corp.
+func unshareIntel(ctx context.Context, tx pgx.Tx, playerID string) error {
+	_, err := tx.Exec(ctx, "UPDATE player_sector_intel SET shared=false WHERE player_id=$1 AND shared", playerID)
+	return err
+}
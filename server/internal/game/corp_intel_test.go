@@ -0,0 +1,27 @@
+package game
+
+import "testing"
+
+func TestParseIntelSectors(t *testing.T) {
+	if ids, all, ok := parseIntelSectors([]string{"all"}); !ok || !all || ids != nil {
+		t.Fatalf("ALL: got %v %v %v", ids, all, ok)
+	}
+	ids, all, ok := parseIntelSectors([]string{"12,", "40"})
+	if !ok || all || len(ids) != 2 || ids[0] != 12 || ids[1] != 40 {
+		t.Fatalf("list: got %v %v %v", ids, all, ok)
+	}
+	for _, bad := range [][]string{nil, {"x"}, {"0"}, {"3", "ALL"}} {
+		if _, _, ok := parseIntelSectors(bad); ok {
+			t.Fatalf("%v should be rejected", bad)
+		}
+	}
+}
+
+func TestIntelSource(t *testing.T) {
+	if got := intelSource("bob", "bob"); got != "" {
+		t.Fatalf("own scan: got %q", got)
+	}
+	if got := intelSource("bob", "alice"); got != ", by alice" {
+		t.Fatalf("corp mate scan: got %q", got)
+	}
+}
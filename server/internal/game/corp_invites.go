@@ -0,0 +1,377 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Corporation membership modes.
+const (
+	CorpMembershipOpen   = "OPEN"   // anyone may CORP JOIN
+	CorpMembershipInvite = "INVITE" // only invited players may join
+	CorpMembershipApply  = "APPLY"  // players CORP APPLY and leadership accepts or rejects
+)
+
+// corp_invites.kind
+const (
+	corpInviteKindInvite      = "INVITE"      // corp -> player
+	corpInviteKindApplication = "APPLICATION" // player -> corp
+)
+
+// Invites and applications lapse after this long.
+const corpInviteTTL = 72 * time.Hour
+
+func normalizeCorpMembership(mode string) (string, bool) {
+	switch strings.ToUpper(strings.TrimSpace(mode)) {
+	case CorpMembershipOpen:
+		return CorpMembershipOpen, true
+	case CorpMembershipInvite, "INVITE-ONLY", "INVITE_ONLY":
+		return CorpMembershipInvite, true
+	case CorpMembershipApply:
+		return CorpMembershipApply, true
+	default:
+		return "", false
+	}
+}
+
+type corpRef struct {
+	ID         string
+	Name       string
+	Credits    int64
+	Membership string
+}
+
+func loadCorpByName(ctx context.Context, tx pgx.Tx, name string) (corpRef, bool, error) {
+	var c corpRef
+	err := tx.QueryRow(ctx, "SELECT id, name, credits, membership FROM corporations WHERE lower(name)=lower($1)", strings.TrimSpace(name)).
+		Scan(&c.ID, &c.Name, &c.Credits, &c.Membership)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return corpRef{}, false, nil
+	}
+	if err != nil {
+		return corpRef{}, false, err
+	}
+	return c, true, nil
+}
+
+// takePendingInvite deletes and reports a live invite or application of the given kind.
+func takePendingInvite(ctx context.Context, tx pgx.Tx, corpID, playerID, kind string) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		DELETE FROM corp_invites
+		WHERE corp_id=$1 AND player_id=$2 AND kind=$3 AND expires_at > now()
+	`, corpID, playerID, kind)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func purgeExpiredCorpInvites(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, "DELETE FROM corp_invites WHERE expires_at <= now()")
+	return err
+}
+
+func upsertCorpInvite(ctx context.Context, tx pgx.Tx, corpID, playerID, kind, createdBy string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO corp_invites(corp_id, player_id, kind, created_by, created_at, expires_at)
+		VALUES ($1,$2,$3,$4,now(),$5)
+		ON CONFLICT (corp_id, player_id) DO UPDATE SET
+			kind = EXCLUDED.kind,
+			created_by = EXCLUDED.created_by,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+	`, corpID, playerID, kind, createdBy, time.Now().UTC().Add(corpInviteTTL))
+	return err
+}
+
+// addCorpMember inserts playerID as a MEMBER. It fails with ok=false when the player already
+// belongs to a corporation.
+func addCorpMember(ctx context.Context, tx pgx.Tx, corpID, playerID string) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO corp_members(corp_id, player_id, role) VALUES ($1,$2,'MEMBER')
+		ON CONFLICT DO NOTHING
+	`, corpID, playerID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func corpSetMembership(ctx context.Context, tx pgx.Tx, p Player, mode string) (phase2Result, error) {
+	if p.CorpID == "" {
+		return phase2Result{OK: false, Message: "You are not in a corporation.", ErrorCode: "NOT_IN_CORP"}, nil
+	}
+	if strings.ToUpper(strings.TrimSpace(p.CorpRole)) != "LEADER" {
+		return phase2Result{OK: false, Message: "Only the corp leader can change membership rules.", ErrorCode: "FORBIDDEN"}, nil
+	}
+	m, ok := normalizeCorpMembership(mode)
+	if !ok {
+		return phase2Result{OK: false, Message: "Usage: CORP MODE {OPEN|INVITE|APPLY}", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	if _, err := tx.Exec(ctx, "UPDATE corporations SET membership=$2 WHERE id=$1", p.CorpID, m); err != nil {
+		return phase2Result{}, err
+	}
+	msg := fmt.Sprintf("Corporation membership is now %s.", m)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+// corpInvite invites a player by username and sends them a CORP_INVITE message.
+func corpInvite(ctx context.Context, tx pgx.Tx, p Player, username string) (phase2Result, error) {
+	if !p.corpAllows(CorpPermInvite) {
+		return phase2Result{OK: false, Message: "Your corp role does not allow sending invites.", ErrorCode: "FORBIDDEN"}, nil
+	}
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return phase2Result{OK: false, Message: "Usage: CORP INVITE {user}", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	targetID, err := LookupPlayerIDByUsername(ctx, tx, username)
+	if errors.Is(err, ErrNotFound) {
+		return phase2Result{OK: false, Message: "Player not found.", ErrorCode: "NOT_FOUND"}, nil
+	}
+	if err != nil {
+		return phase2Result{}, err
+	}
+	var inCorp bool
+	if err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM corp_members WHERE player_id=$1)", targetID).Scan(&inCorp); err != nil {
+		return phase2Result{}, err
+	}
+	if inCorp {
+		return phase2Result{OK: false, Message: "That player is already in a corporation.", ErrorCode: "ALREADY_IN_CORP"}, nil
+	}
+
+	if err := upsertCorpInvite(ctx, tx, p.CorpID, targetID, corpInviteKindInvite, p.ID); err != nil {
+		return phase2Result{}, err
+	}
+	subject := fmt.Sprintf("Invitation to join %s", p.CorpName)
+	body := fmt.Sprintf("%s invites you to join the corporation %s.\n\nUse CORP ACCEPT %s to join or CORP REJECT %s to decline. The invitation expires in %d hours.\n",
+		p.Username, p.CorpName, p.CorpName, p.CorpName, int(corpInviteTTL.Hours()))
+	if _, err := InsertDirectMessage(ctx, tx, p.ID, targetID, MessageKindCorpInvite, subject, body, nil); err != nil {
+		return phase2Result{}, err
+	}
+
+	msg := fmt.Sprintf("Invited %s to %s.", username, p.CorpName)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+// corpApply files an application with an APPLY corp and notifies its leadership.
+func corpApply(ctx context.Context, tx pgx.Tx, p Player, name string) (phase2Result, error) {
+	if p.CorpID != "" {
+		return phase2Result{OK: false, Message: "You are already in a corporation.", ErrorCode: "ALREADY_IN_CORP"}, nil
+	}
+	if strings.TrimSpace(name) == "" {
+		return phase2Result{OK: false, Message: "Usage: CORP APPLY {name}", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	c, ok, err := loadCorpByName(ctx, tx, name)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !ok {
+		return phase2Result{OK: false, Message: "Corporation not found.", ErrorCode: "NOT_FOUND"}, nil
+	}
+	switch c.Membership {
+	case CorpMembershipOpen:
+		return phase2Result{OK: false, Message: fmt.Sprintf("%s is open; use CORP JOIN %s.", c.Name, c.Name), ErrorCode: "INVALID_ARGS"}, nil
+	case CorpMembershipInvite:
+		return phase2Result{OK: false, Message: fmt.Sprintf("%s is invite-only.", c.Name), ErrorCode: "INVITE_ONLY"}, nil
+	}
+
+	if err := upsertCorpInvite(ctx, tx, c.ID, p.ID, corpInviteKindApplication, p.ID); err != nil {
+		return phase2Result{}, err
+	}
+
+	perms, err := loadCorpPermissions(ctx, tx, c.ID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	rows, err := tx.Query(ctx, "SELECT player_id, role FROM corp_members WHERE corp_id=$1", c.ID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	var leaders []string
+	for rows.Next() {
+		member := Player{CorpID: c.ID, CorpPerms: perms}
+		if err := rows.Scan(&member.ID, &member.CorpRole); err != nil {
+			rows.Close()
+			return phase2Result{}, err
+		}
+		if member.corpAllows(CorpPermInvite) {
+			leaders = append(leaders, member.ID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return phase2Result{}, err
+	}
+	subject := fmt.Sprintf("Application to join %s", c.Name)
+	body := fmt.Sprintf("%s has applied to join %s.\n\nUse CORP ACCEPT %s or CORP REJECT %s. The application expires in %d hours.\n",
+		p.Username, c.Name, p.Username, p.Username, int(corpInviteTTL.Hours()))
+	for _, id := range leaders {
+		if _, err := InsertDirectMessage(ctx, tx, p.ID, id, MessageKindCorpInvite, subject, body, nil); err != nil {
+			return phase2Result{}, err
+		}
+	}
+
+	msg := fmt.Sprintf("Applied to join %s.", c.Name)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+// corpAnswer handles CORP ACCEPT/REJECT. Corp leadership answers applications by username;
+// a player outside any corp answers an invite by corp name.
+func corpAnswer(ctx context.Context, tx pgx.Tx, p *Player, arg string, accept bool) (phase2Result, error) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return phase2Result{OK: false, Message: "Usage: CORP ACCEPT {user|corp} or CORP REJECT {user|corp}", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	if p.CorpID == "" {
+		return corpAnswerInvite(ctx, tx, p, arg, accept)
+	}
+	if !p.corpAllows(CorpPermInvite) {
+		return phase2Result{OK: false, Message: "Your corp role does not allow answering applications.", ErrorCode: "FORBIDDEN"}, nil
+	}
+
+	applicantID, err := LookupPlayerIDByUsername(ctx, tx, arg)
+	if errors.Is(err, ErrNotFound) {
+		return phase2Result{OK: false, Message: "Player not found.", ErrorCode: "NOT_FOUND"}, nil
+	}
+	if err != nil {
+		return phase2Result{}, err
+	}
+	found, err := takePendingInvite(ctx, tx, p.CorpID, applicantID, corpInviteKindApplication)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !found {
+		return phase2Result{OK: false, Message: fmt.Sprintf("No pending application from %s.", arg), ErrorCode: "NOT_FOUND"}, nil
+	}
+
+	verdict := "rejected"
+	if accept {
+		joined, err := addCorpMember(ctx, tx, p.CorpID, applicantID)
+		if err != nil {
+			return phase2Result{}, err
+		}
+		if !joined {
+			return phase2Result{OK: false, Message: fmt.Sprintf("%s has already joined another corporation.", arg), ErrorCode: "ALREADY_IN_CORP"}, nil
+		}
+		verdict = "accepted"
+	}
+	subject := fmt.Sprintf("Application to %s %s", p.CorpName, verdict)
+	body := fmt.Sprintf("%s %s your application to join %s.\n", p.Username, verdict, p.CorpName)
+	if _, err := InsertDirectMessage(ctx, tx, p.ID, applicantID, MessageKindCorpInvite, subject, body, nil); err != nil {
+		return phase2Result{}, err
+	}
+
+	msg := fmt.Sprintf("Application from %s %s.", arg, verdict)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+func corpAnswerInvite(ctx context.Context, tx pgx.Tx, p *Player, corpName string, accept bool) (phase2Result, error) {
+	c, ok, err := loadCorpByName(ctx, tx, corpName)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !ok {
+		return phase2Result{OK: false, Message: "Corporation not found.", ErrorCode: "NOT_FOUND"}, nil
+	}
+	var inviterID string
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(created_by, '') FROM corp_invites
+		WHERE corp_id=$1 AND player_id=$2 AND kind=$3 AND expires_at > now()
+	`, c.ID, p.ID, corpInviteKindInvite).Scan(&inviterID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return phase2Result{}, err
+	}
+	found, err := takePendingInvite(ctx, tx, c.ID, p.ID, corpInviteKindInvite)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !found {
+		return phase2Result{OK: false, Message: fmt.Sprintf("No pending invite from %s.", c.Name), ErrorCode: "NOT_FOUND"}, nil
+	}
+
+	if accept {
+		joined, err := addCorpMember(ctx, tx, c.ID, p.ID)
+		if err != nil {
+			return phase2Result{}, err
+		}
+		if !joined {
+			return phase2Result{OK: false, Message: "Unable to join corporation.", ErrorCode: "JOIN_FAILED"}, nil
+		}
+		p.CorpID = c.ID
+		p.CorpName = c.Name
+		p.CorpRole = "MEMBER"
+		p.CorpCredits = c.Credits
+	}
+	if inviterID != "" {
+		verdict := "declined"
+		if accept {
+			verdict = "accepted"
+		}
+		subject := fmt.Sprintf("Invitation to %s %s", c.Name, verdict)
+		body := fmt.Sprintf("%s %s your invitation to join %s.\n", p.Username, verdict, c.Name)
+		if _, err := InsertDirectMessage(ctx, tx, p.ID, inviterID, MessageKindCorpInvite, subject, body, nil); err != nil {
+			return phase2Result{}, err
+		}
+	}
+
+	msg := fmt.Sprintf("Declined the invitation from %s.", c.Name)
+	if accept {
+		msg = fmt.Sprintf("Joined corporation '%s'.", c.Name)
+	}
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+// corpListInvites shows the corp's pending invites and applications to its leadership, or a
+// player's own invites and applications when they are not in a corp.
+func corpListInvites(ctx context.Context, tx pgx.Tx, p Player) (phase2Result, error) {
+	var rows pgx.Rows
+	var err error
+	if p.CorpID != "" {
+		if !p.corpAllows(CorpPermInvite) {
+			return phase2Result{OK: false, Message: "Your corp role does not allow viewing pending invites.", ErrorCode: "FORBIDDEN"}, nil
+		}
+		rows, err = tx.Query(ctx, `
+			SELECT i.kind, u.username, i.expires_at
+			FROM corp_invites i
+			JOIN players pl ON pl.id = i.player_id
+			JOIN users u ON u.id = pl.user_id
+			WHERE i.corp_id=$1 AND i.expires_at > now()
+			ORDER BY i.kind, i.created_at
+		`, p.CorpID)
+	} else {
+		rows, err = tx.Query(ctx, `
+			SELECT i.kind, c.name, i.expires_at
+			FROM corp_invites i
+			JOIN corporations c ON c.id = i.corp_id
+			WHERE i.player_id=$1 AND i.expires_at > now()
+			ORDER BY i.kind, i.created_at
+		`, p.ID)
+	}
+	if err != nil {
+		return phase2Result{}, err
+	}
+	defer rows.Close()
+
+	lines := []string{"Pending corp invites and applications:"}
+	now := time.Now()
+	for rows.Next() {
+		var kind, who string
+		var expires time.Time
+		if err := rows.Scan(&kind, &who, &expires); err != nil {
+			return phase2Result{}, err
+		}
+		lines = append(lines, fmt.Sprintf("- %s %s (expires in %s)", kind, who, formatDurationShort(expires.Sub(now))))
+	}
+	if err := rows.Err(); err != nil {
+		return phase2Result{}, err
+	}
+	if len(lines) == 1 {
+		lines = append(lines, "- none")
+	}
+	return phase2Result{OK: true, Message: strings.Join(lines, "\n")}, nil
+}
@@ -0,0 +1,20 @@
+package game
+
+import "testing"
+
+func TestNormalizeCorpMembership(t *testing.T) {
+	cases := map[string]string{
+		"open":        CorpMembershipOpen,
+		" INVITE ":    CorpMembershipInvite,
+		"invite-only": CorpMembershipInvite,
+		"Apply":       CorpMembershipApply,
+	}
+	for in, want := range cases {
+		if got, ok := normalizeCorpMembership(in); !ok || got != want {
+			t.Fatalf("%q: got %q,%v want %q", in, got, ok, want)
+		}
+	}
+	if _, ok := normalizeCorpMembership("closed"); ok {
+		t.Fatalf("closed should be rejected")
+	}
+}
@@ -0,0 +1,343 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Corporation roles, highest first.
+const (
+	CorpRoleLeader  = "LEADER"
+	CorpRoleOfficer = "OFFICER"
+	CorpRoleMember  = "MEMBER"
+)
+
+// Corporation permissions. Each maps to the lowest role allowed to use it.
+const (
+	CorpPermWithdraw  = "WITHDRAW"  // take credits from the corp bank (including corp-funded gates)
+	CorpPermInvite    = "INVITE"    // send invites and answer applications
+	CorpPermPlanets   = "PLANETS"   // use corp-owned planets
+	CorpPermMines     = "MINES"     // deploy mines
+	CorpPermModerate  = "MODERATE"  // mute and unmute members in corp chat
+	CorpPermDiplomacy = "DIPLOMACY" // propose, accept and break treaties and declare war
+)
+
+var defaultCorpPermissions = map[string]string{
+	CorpPermWithdraw:  CorpRoleOfficer,
+	CorpPermInvite:    CorpRoleOfficer,
+	CorpPermPlanets:   CorpRoleMember,
+	CorpPermMines:     CorpRoleMember,
+	CorpPermModerate:  CorpRoleOfficer,
+	CorpPermDiplomacy: CorpRoleLeader,
+}
+
+// CorpPermissions holds a corp's overrides of defaultCorpPermissions.
+type CorpPermissions map[string]string
+
+// MinRole is the lowest role granted perm.
+func (cp CorpPermissions) MinRole(perm string) string {
+	if r, ok := cp[perm]; ok {
+		return r
+	}
+	return defaultCorpPermissions[perm]
+}
+
+func corpRoleRank(role string) int {
+	switch strings.ToUpper(strings.TrimSpace(role)) {
+	case CorpRoleLeader:
+		return 3
+	case CorpRoleOfficer:
+		return 2
+	case CorpRoleMember:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func normalizeCorpRole(role string) (string, bool) {
+	role = strings.ToUpper(strings.TrimSpace(role))
+	if corpRoleRank(role) == 0 {
+		return "", false
+	}
+	return role, true
+}
+
+// corpAllows reports whether p's corp role grants perm. The leader always has every permission.
+func (p Player) corpAllows(perm string) bool {
+	if p.CorpID == "" {
+		return false
+	}
+	rank := corpRoleRank(p.CorpRole)
+	return rank == 3 || rank >= corpRoleRank(p.CorpPerms.MinRole(perm))
+}
+
+func loadCorpPermissions(ctx context.Context, tx pgx.Tx, corpID string) (CorpPermissions, error) {
+	rows, err := tx.Query(ctx, "SELECT permission, min_role FROM corp_permissions WHERE corp_id=$1", corpID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cp := CorpPermissions{}
+	for rows.Next() {
+		var perm, role string
+		if err := rows.Scan(&perm, &role); err != nil {
+			return nil, err
+		}
+		cp[perm] = role
+	}
+	return cp, rows.Err()
+}
+
+// logToCorp writes msg to the log of every member of the corporation.
+func logToCorp(ctx context.Context, tx pgx.Tx, corpID, msg string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO logs(player_id, kind, message)
+		SELECT player_id, 'CORP', $2 FROM corp_members WHERE corp_id=$1
+	`, corpID, msg)
+	return err
+}
+
+type corpMemberRow struct {
+	PlayerID string
+	Username string
+	Role     string
+	Muted    bool
+}
+
+func loadCorpMemberByUsername(ctx context.Context, tx pgx.Tx, corpID, username string) (corpMemberRow, bool, error) {
+	var m corpMemberRow
+	err := tx.QueryRow(ctx, `
+		SELECT cm.player_id, u.username, cm.role, cm.muted
+		FROM corp_members cm
+		JOIN players pl ON pl.id = cm.player_id
+		JOIN users u ON u.id = pl.user_id
+		WHERE cm.corp_id=$1 AND lower(u.username)=lower($2)
+		FOR UPDATE OF cm
+	`, corpID, strings.TrimSpace(username)).Scan(&m.PlayerID, &m.Username, &m.Role, &m.Muted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return corpMemberRow{}, false, nil
+	}
+	if err != nil {
+		return corpMemberRow{}, false, err
+	}
+	return m, true, nil
+}
+
+// corpTargetMember resolves the member a role command acts on and rejects self-targeting.
+func corpTargetMember(ctx context.Context, tx pgx.Tx, p Player, username, usage string) (corpMemberRow, *phase2Result, error) {
+	if p.CorpID == "" {
+		return corpMemberRow{}, &phase2Result{OK: false, Message: "You are not in a corporation.", ErrorCode: "NOT_IN_CORP"}, nil
+	}
+	if strings.TrimSpace(username) == "" {
+		return corpMemberRow{}, &phase2Result{OK: false, Message: "Usage: " + usage, ErrorCode: "INVALID_ARGS"}, nil
+	}
+	m, ok, err := loadCorpMemberByUsername(ctx, tx, p.CorpID, username)
+	if err != nil {
+		return corpMemberRow{}, nil, err
+	}
+	if !ok {
+		return corpMemberRow{}, &phase2Result{OK: false, Message: "That player is not in your corporation.", ErrorCode: "NOT_FOUND"}, nil
+	}
+	if m.PlayerID == p.ID {
+		return corpMemberRow{}, &phase2Result{OK: false, Message: "You cannot target yourself.", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	return m, nil, nil
+}
+
+func corpRequireLeader(p Player, what string) *phase2Result {
+	if p.CorpID == "" {
+		return &phase2Result{OK: false, Message: "You are not in a corporation.", ErrorCode: "NOT_IN_CORP"}
+	}
+	if strings.ToUpper(strings.TrimSpace(p.CorpRole)) != CorpRoleLeader {
+		return &phase2Result{OK: false, Message: fmt.Sprintf("Only the corp leader can %s.", what), ErrorCode: "FORBIDDEN"}
+	}
+	return nil
+}
+
+func corpSetRole(ctx context.Context, tx pgx.Tx, corpID, playerID, role string) error {
+	_, err := tx.Exec(ctx, "UPDATE corp_members SET role=$3 WHERE corp_id=$1 AND player_id=$2", corpID, playerID, role)
+	return err
+}
+
+func corpPromote(ctx context.Context, tx pgx.Tx, p Player, username string) (phase2Result, error) {
+	if res := corpRequireLeader(p, "promote members"); res != nil {
+		return *res, nil
+	}
+	m, res, err := corpTargetMember(ctx, tx, p, username, "CORP PROMOTE {user}")
+	if err != nil || res != nil {
+		return derefResult(res), err
+	}
+	if m.Role != CorpRoleMember {
+		return phase2Result{OK: false, Message: fmt.Sprintf("%s is already %s.", m.Username, m.Role), ErrorCode: "INVALID_ARGS"}, nil
+	}
+	if err := corpSetRole(ctx, tx, p.CorpID, m.PlayerID, CorpRoleOfficer); err != nil {
+		return phase2Result{}, err
+	}
+	return corpRoleChanged(ctx, tx, p, fmt.Sprintf("%s promoted %s to OFFICER.", p.Username, m.Username))
+}
+
+func corpDemote(ctx context.Context, tx pgx.Tx, p Player, username string) (phase2Result, error) {
+	if res := corpRequireLeader(p, "demote officers"); res != nil {
+		return *res, nil
+	}
+	m, res, err := corpTargetMember(ctx, tx, p, username, "CORP DEMOTE {user}")
+	if err != nil || res != nil {
+		return derefResult(res), err
+	}
+	if m.Role != CorpRoleOfficer {
+		return phase2Result{OK: false, Message: fmt.Sprintf("%s is not an officer.", m.Username), ErrorCode: "INVALID_ARGS"}, nil
+	}
+	if err := corpSetRole(ctx, tx, p.CorpID, m.PlayerID, CorpRoleMember); err != nil {
+		return phase2Result{}, err
+	}
+	return corpRoleChanged(ctx, tx, p, fmt.Sprintf("%s demoted %s to MEMBER.", p.Username, m.Username))
+}
+
+// corpKick removes a member. Officers may kick members; only the leader may kick officers.
+func corpKick(ctx context.Context, tx pgx.Tx, p Player, username string) (phase2Result, error) {
+	m, res, err := corpTargetMember(ctx, tx, p, username, "CORP KICK {user}")
+	if err != nil || res != nil {
+		return derefResult(res), err
+	}
+	if corpRoleRank(p.CorpRole) < 2 || corpRoleRank(m.Role) >= corpRoleRank(p.CorpRole) {
+		return phase2Result{OK: false, Message: fmt.Sprintf("You cannot kick %s.", m.Username), ErrorCode: "FORBIDDEN"}, nil
+	}
+
+	msg := fmt.Sprintf("%s kicked %s from %s.", p.Username, m.Username, p.CorpName)
+	// Log before the delete so the kicked player sees it too.
+	if err := logToCorp(ctx, tx, p.CorpID, msg); err != nil {
+		return phase2Result{}, err
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM corp_members WHERE corp_id=$1 AND player_id=$2", p.CorpID, m.PlayerID); err != nil {
+		return phase2Result{}, err
+	}
+	if err := unshareIntel(ctx, tx, m.PlayerID); err != nil {
+		return phase2Result{}, err
+	}
+	return phase2Result{OK: true, Message: msg}, nil
+}
+
+// corpTransfer hands leadership to another member; the old leader becomes an officer.
+func corpTransfer(ctx context.Context, tx pgx.Tx, p *Player, username string) (phase2Result, error) {
+	if res := corpRequireLeader(*p, "transfer leadership"); res != nil {
+		return *res, nil
+	}
+	m, res, err := corpTargetMember(ctx, tx, *p, username, "CORP TRANSFER {user}")
+	if err != nil || res != nil {
+		return derefResult(res), err
+	}
+	if err := corpSetRole(ctx, tx, p.CorpID, p.ID, CorpRoleOfficer); err != nil {
+		return phase2Result{}, err
+	}
+	if err := corpSetRole(ctx, tx, p.CorpID, m.PlayerID, CorpRoleLeader); err != nil {
+		return phase2Result{}, err
+	}
+	p.CorpRole = CorpRoleOfficer
+	return corpRoleChanged(ctx, tx, *p, fmt.Sprintf("%s transferred leadership of %s to %s.", p.Username, p.CorpName, m.Username))
+}
+
+// corpMute silences (or restores) a member in corp chat.
+func corpMute(ctx context.Context, tx pgx.Tx, p Player, username string, mute bool) (phase2Result, error) {
+	usage := "CORP MUTE {user}"
+	if !mute {
+		usage = "CORP UNMUTE {user}"
+	}
+	m, res, err := corpTargetMember(ctx, tx, p, username, usage)
+	if err != nil || res != nil {
+		return derefResult(res), err
+	}
+	if !p.corpAllows(CorpPermModerate) || corpRoleRank(m.Role) >= corpRoleRank(p.CorpRole) {
+		return phase2Result{OK: false, Message: fmt.Sprintf("You cannot moderate %s.", m.Username), ErrorCode: "FORBIDDEN"}, nil
+	}
+	if m.Muted == mute {
+		return phase2Result{OK: false, Message: fmt.Sprintf("%s is already %s.", m.Username, mutedLabel(mute)), ErrorCode: "INVALID_ARGS"}, nil
+	}
+	if _, err := tx.Exec(ctx, "UPDATE corp_members SET muted=$3 WHERE corp_id=$1 AND player_id=$2", p.CorpID, m.PlayerID, mute); err != nil {
+		return phase2Result{}, err
+	}
+	verb := "muted"
+	if !mute {
+		verb = "unmuted"
+	}
+	return corpRoleChanged(ctx, tx, p, fmt.Sprintf("%s %s %s in corp chat.", p.Username, verb, m.Username))
+}
+
+func mutedLabel(muted bool) string {
+	if muted {
+		return "muted"
+	}
+	return "not muted"
+}
+
+// corpPermit sets the lowest role allowed a permission: CORP PERMIT {permission} {role}.
+func corpPermit(ctx context.Context, tx pgx.Tx, p *Player, args string) (phase2Result, error) {
+	if res := corpRequireLeader(*p, "change permissions"); res != nil {
+		return *res, nil
+	}
+	fields := strings.Fields(strings.ToUpper(args))
+	if len(fields) != 2 {
+		return phase2Result{OK: false, Message: "Usage: CORP PERMIT {WITHDRAW|INVITE|PLANETS|MINES|MODERATE|DIPLOMACY} {LEADER|OFFICER|MEMBER}", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	perm := fields[0]
+	if _, ok := defaultCorpPermissions[perm]; !ok {
+		return phase2Result{OK: false, Message: "Unknown permission. Use WITHDRAW, INVITE, PLANETS, MINES, MODERATE or DIPLOMACY.", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	role, ok := normalizeCorpRole(fields[1])
+	if !ok {
+		return phase2Result{OK: false, Message: "Role must be LEADER, OFFICER or MEMBER.", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO corp_permissions(corp_id, permission, min_role) VALUES ($1,$2,$3)
+		ON CONFLICT (corp_id, permission) DO UPDATE SET min_role = EXCLUDED.min_role
+	`, p.CorpID, perm, role)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if p.CorpPerms == nil {
+		p.CorpPerms = CorpPermissions{}
+	}
+	p.CorpPerms[perm] = role
+	return corpRoleChanged(ctx, tx, *p, fmt.Sprintf("%s set %s to %s and above.", p.Username, perm, role))
+}
+
+func corpPermissionLines(cp CorpPermissions) []string {
+	perms := make([]string, 0, len(defaultCorpPermissions))
+	for perm := range defaultCorpPermissions {
+		perms = append(perms, perm)
+	}
+	sort.Strings(perms)
+	lines := make([]string, 0, len(perms))
+	for _, perm := range perms {
+		lines = append(lines, fmt.Sprintf("- %s: %s and above", perm, cp.MinRole(perm)))
+	}
+	return lines
+}
+
+func corpListPermissions(p Player) (phase2Result, error) {
+	if p.CorpID == "" {
+		return phase2Result{OK: false, Message: "You are not in a corporation.", ErrorCode: "NOT_IN_CORP"}, nil
+	}
+	lines := append([]string{"Corporation permissions:"}, corpPermissionLines(p.CorpPerms)...)
+	return phase2Result{OK: true, Message: strings.Join(lines, "\n")}, nil
+}
+
+// corpRoleChanged logs a role or permission change to every member and returns it as the result.
+func corpRoleChanged(ctx context.Context, tx pgx.Tx, p Player, msg string) (phase2Result, error) {
+	if err := logToCorp(ctx, tx, p.CorpID, msg); err != nil {
+		return phase2Result{}, err
+	}
+	return phase2Result{OK: true, Message: msg}, nil
+}
+
+func derefResult(res *phase2Result) phase2Result {
+	if res == nil {
+		return phase2Result{}
+	}
+	return *res
+}
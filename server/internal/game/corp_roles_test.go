@@ -0,0 +1,34 @@
+package game
+
+import "testing"
+
+func TestCorpAllowsDefaults(t *testing.T) {
+	if (Player{CorpRole: CorpRoleLeader}).corpAllows(CorpPermWithdraw) {
+		t.Fatalf("no corp should grant nothing")
+	}
+	member := Player{CorpID: "c", CorpRole: CorpRoleMember}
+	officer := Player{CorpID: "c", CorpRole: "officer"}
+	if member.corpAllows(CorpPermWithdraw) || !officer.corpAllows(CorpPermWithdraw) {
+		t.Fatalf("withdraw should default to officers")
+	}
+	if !member.corpAllows(CorpPermPlanets) || !member.corpAllows(CorpPermMines) {
+		t.Fatalf("planets and mines should default to members")
+	}
+}
+
+func TestCorpAllowsOverrides(t *testing.T) {
+	perms := CorpPermissions{CorpPermPlanets: CorpRoleLeader, CorpPermWithdraw: CorpRoleMember}
+	member := Player{CorpID: "c", CorpRole: CorpRoleMember, CorpPerms: perms}
+	officer := Player{CorpID: "c", CorpRole: CorpRoleOfficer, CorpPerms: perms}
+	leader := Player{CorpID: "c", CorpRole: CorpRoleLeader, CorpPerms: CorpPermissions{CorpPermPlanets: "NOBODY"}}
+
+	if !member.corpAllows(CorpPermWithdraw) {
+		t.Fatalf("withdraw lowered to members")
+	}
+	if officer.corpAllows(CorpPermPlanets) {
+		t.Fatalf("planets raised to leader")
+	}
+	if !leader.corpAllows(CorpPermPlanets) {
+		t.Fatalf("leader always allowed")
+	}
+}
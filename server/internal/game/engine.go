@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"strings"
 	"time"
 
@@ -64,15 +65,21 @@ func ExecuteCommand(ctx context.Context, pool *pgxpool.Pool, playerID string, cm
 		return CommandResponse{OK: false, Error: "player not found"}, err
 	}
 
-	RegenTurns(&p, regenSeconds, time.Now().UTC())
+	RegenTurns(&p, p.Rules.TurnRegen(regenSeconds), time.Now().UTC())
 
 	// Force a password change before any gameplay actions when required.
 	// This is primarily used for the seeded initial admin account.
 	if p.MustChangePass {
 		return failWithState(ctx, pool, tx, p, "Password change required. Use the Change Password form.", "PASSWORD_CHANGE_REQUIRED")
 	}
+	if p.SeasonFrozen && !seasonFrozenAllows(cmd.Type) {
+		return failWithState(ctx, pool, tx, p, "The season is over and the galaxy is frozen until the next one starts. See SEASON.", "SEASON_FROZEN")
+	}
 
-	cost := effectiveCommandCost(p, cmd)
+	cost, err := commandTurnCost(ctx, tx, p, cmd)
+	if err != nil {
+		return CommandResponse{OK: false, Error: "db error"}, err
+	}
 	if cost > 0 && p.Turns < cost {
 		// persist regen changes
 		_ = SavePlayer(ctx, tx, p)
@@ -96,6 +103,20 @@ func ExecuteCommand(ctx context.Context, pool *pgxpool.Pool, playerID string, cm
 
 	switch cmd.Type {
 	case "SCAN":
+		if cmd.Action != "" {
+			out, execErr := executeScanCommand(ctx, tx, &p, cmd)
+			if execErr != nil {
+				return CommandResponse{OK: false, Error: "db error"}, execErr
+			}
+			if !out.OK {
+				return failWithState(ctx, pool, tx, p, out.Message, out.ErrorCode)
+			}
+			p.Turns -= cost
+			success = true
+			message = out.Message
+			logsToInsert = append(logsToInsert, out.Logs...)
+			break
+		}
 		p.Turns -= cost
 		success = true
 		message = fmt.Sprintf("Scan complete for sector %d.", p.SectorID)
@@ -104,6 +125,23 @@ func ExecuteCommand(ctx context.Context, pool *pgxpool.Pool, playerID string, cm
 		if err := CaptureScanIntel(ctx, tx, p.ID, p.SectorID); err != nil {
 			return CommandResponse{OK: false, Error: "db error"}, err
 		}
+		if err := RecordHazardIntel(ctx, tx, p, p.SectorID); err != nil {
+			return CommandResponse{OK: false, Error: "db error"}, err
+		}
+		env, err := SectorEnvironment(ctx, tx, p.SectorID)
+		if err != nil {
+			return CommandResponse{OK: false, Error: "db error"}, err
+		}
+		if env == EnvNebula {
+			message += " Nebula interference: no port or minefield readings captured."
+		}
+		if p.ShipScanner {
+			summary, err := scannerPortSummary(ctx, tx, p.SectorID)
+			if err != nil {
+				return CommandResponse{OK: false, Error: "db error"}, err
+			}
+			message = message + "\n" + summary
+		}
 
 	case "MOVE":
 		if cmd.To < 1 {
@@ -129,35 +167,36 @@ func ExecuteCommand(ctx context.Context, pool *pgxpool.Pool, playerID string, cm
 				return CommandResponse{OK: false, Error: "db error"}, err
 			}
 		}
+		gate, toll, tollErr := gateTollDue(ctx, tx, p, p.SectorID, cmd.To)
+		if tollErr != nil {
+			return CommandResponse{OK: false, Error: "db error"}, tollErr
+		}
+		if toll > p.Credits {
+			return failWithState(ctx, pool, tx, p, fmt.Sprintf("The gate to sector %d charges a %d credit toll.", cmd.To, toll), "TOLL_UNPAID")
+		}
+		tollMsg, tollErr := payGateToll(ctx, tx, &p, gate, toll)
+		if tollErr != nil {
+			return CommandResponse{OK: false, Error: "db error"}, tollErr
+		}
 		p.Turns -= cost
 		p.SectorID = cmd.To
 		success = true
 		moveMsg := fmt.Sprintf("Moved to sector %d.", p.SectorID)
 		message = moveMsg
 		logsToInsert = append(logsToInsert, logToInsert{kind: "ACTION", msg: moveMsg})
-		_ = MarkDiscovered(ctx, tx, p.ID, p.SectorID)
-
-		evtMsg, evtKind, evtLog, evtErr := applySectorEventOnEntry(ctx, tx, &p)
-		if evtErr != nil {
-			return CommandResponse{OK: false, Error: "db error"}, evtErr
-		}
-		if evtMsg != "" {
-			message = message + "\n" + evtMsg
-			if evtLog != "" {
-				logsToInsert = append(logsToInsert, logToInsert{kind: evtKind, msg: evtLog})
-			}
+		if tollMsg != "" {
+			message = message + "\n" + tollMsg
+			logsToInsert = append(logsToInsert, logToInsert{kind: "ACTION", msg: tollMsg})
 		}
 
-		strikeMsg, strikeLog, strikeErr := applyMineStrike(ctx, tx, &p)
-		if strikeErr != nil {
-			return CommandResponse{OK: false, Error: "db error"}, strikeErr
+		arrival, arrErr := enterSector(ctx, tx, &p)
+		if arrErr != nil {
+			return CommandResponse{OK: false, Error: "db error"}, arrErr
 		}
-		if strikeMsg != "" {
-			message = message + "\n" + strikeMsg
-			if strikeLog != "" {
-				logsToInsert = append(logsToInsert, logToInsert{kind: "COMBAT", msg: strikeLog})
-			}
+		for _, m := range arrival.Messages {
+			message = message + "\n" + m
 		}
+		logsToInsert = append(logsToInsert, arrival.Logs...)
 
 	case "MARKET":
 		out, execErr := executeMarketCommand(ctx, tx, p, cmd)
@@ -169,6 +208,20 @@ func ExecuteCommand(ctx context.Context, pool *pgxpool.Pool, playerID string, cm
 		logsToInsert = append(logsToInsert, logToInsert{kind: "SYSTEM", msg: out})
 
 	case "ROUTE":
+		if cmd.Action == "RUN" {
+			// The autopilot spends turns and awards XP per step itself.
+			out, execErr := executeRouteRun(ctx, tx, &p, cmd)
+			if execErr != nil {
+				return CommandResponse{OK: false, Error: "db error"}, execErr
+			}
+			if !out.OK {
+				return failWithState(ctx, pool, tx, p, out.Message, out.ErrorCode)
+			}
+			success = true
+			message = out.Message
+			logsToInsert = append(logsToInsert, out.Logs...)
+			break
+		}
 		out, execErr := executeRouteCommand(ctx, tx, p, cmd)
 		if execErr != nil {
 			return CommandResponse{OK: false, Error: "db error"}, execErr
@@ -186,6 +239,15 @@ func ExecuteCommand(ctx context.Context, pool *pgxpool.Pool, playerID string, cm
 		message = out
 		logsToInsert = append(logsToInsert, logToInsert{kind: "SYSTEM", msg: out})
 
+	case "NEWS":
+		out, execErr := executeNewsCommand(ctx, tx)
+		if execErr != nil {
+			return CommandResponse{OK: false, Error: "db error"}, execErr
+		}
+		success = true
+		message = out
+		logsToInsert = append(logsToInsert, logToInsert{kind: "SYSTEM", msg: out})
+
 	case "TRADE":
 		tradeMsg, tradeOk, tradeErr := executeTrade(ctx, tx, &p, cmd)
 		if tradeErr != nil {
@@ -259,14 +321,103 @@ func ExecuteCommand(ctx context.Context, pool *pgxpool.Pool, playerID string, cm
 			logsToInsert = append(logsToInsert, logToInsert{kind: l.kind, msg: l.msg})
 		}
 
+	case "BANK":
+		out, execErr := executeBankCommand(ctx, tx, &p, cmd)
+		if execErr != nil {
+			return CommandResponse{OK: false, Error: "db error"}, execErr
+		}
+		if !out.OK {
+			return failWithState(ctx, pool, tx, p, out.Message, out.ErrorCode)
+		}
+		success = true
+		message = out.Message
+		for _, l := range out.Logs {
+			logsToInsert = append(logsToInsert, logToInsert{kind: l.kind, msg: l.msg})
+		}
+
+	case "STARDOCK":
+		out, execErr := executeStardockCommand(ctx, tx, &p, cmd)
+		if execErr != nil {
+			return CommandResponse{OK: false, Error: "db error"}, execErr
+		}
+		if !out.OK {
+			return failWithState(ctx, pool, tx, p, out.Message, out.ErrorCode)
+		}
+		p.Turns -= cost
+		success = true
+		message = out.Message
+		for _, l := range out.Logs {
+			logsToInsert = append(logsToInsert, logToInsert{kind: l.kind, msg: l.msg})
+		}
+
+	case "FUEL":
+		out, execErr := executeFuelCommand(ctx, tx, &p, cmd)
+		if execErr != nil {
+			return CommandResponse{OK: false, Error: "db error"}, execErr
+		}
+		if !out.OK {
+			return failWithState(ctx, pool, tx, p, out.Message, out.ErrorCode)
+		}
+		p.Turns -= cost
+		success = true
+		message = out.Message
+		for _, l := range out.Logs {
+			logsToInsert = append(logsToInsert, logToInsert{kind: l.kind, msg: l.msg})
+		}
+
+	case "BLACKMARKET":
+		out, execErr := executeBlackMarketCommand(ctx, tx, &p, cmd)
+		if execErr != nil {
+			return CommandResponse{OK: false, Error: "db error"}, execErr
+		}
+		if !out.OK {
+			return failWithState(ctx, pool, tx, p, out.Message, out.ErrorCode)
+		}
+		p.Turns -= cost
+		success = true
+		message = out.Message
+		for _, l := range out.Logs {
+			logsToInsert = append(logsToInsert, logToInsert{kind: l.kind, msg: l.msg})
+		}
+
+	case "GATE":
+		out, execErr := executeGateCommand(ctx, tx, &p, cmd)
+		if execErr != nil {
+			return CommandResponse{OK: false, Error: "db error"}, execErr
+		}
+		if !out.OK {
+			return failWithState(ctx, pool, tx, p, out.Message, out.ErrorCode)
+		}
+		p.Turns -= cost
+		success = true
+		message = out.Message
+		logsToInsert = append(logsToInsert, out.Logs...)
+
+	case "BEACON":
+		out, execErr := executeBeaconCommand(ctx, tx, &p, cmd)
+		if execErr != nil {
+			return CommandResponse{OK: false, Error: "db error"}, execErr
+		}
+		if !out.OK {
+			return failWithState(ctx, pool, tx, p, out.Message, out.ErrorCode)
+		}
+		success = true
+		message = out.Message
+		for _, l := range out.Logs {
+			logsToInsert = append(logsToInsert, logToInsert{kind: l.kind, msg: l.msg})
+		}
+
 	case "RANKINGS":
-		out, execErr := executeRankingsCommand(ctx, tx, p)
+		out, execErr := executeRankingsCommand(ctx, tx, p, cmd)
 		if execErr != nil {
 			return CommandResponse{OK: false, Error: "db error"}, execErr
 		}
+		if !out.OK {
+			return failWithState(ctx, pool, tx, p, out.Message, out.ErrorCode)
+		}
 		success = true
-		message = out
-		logsToInsert = append(logsToInsert, logToInsert{kind: "SYSTEM", msg: out})
+		message = out.Message
+		logsToInsert = append(logsToInsert, out.Logs...)
 
 	case "SEASON":
 		out, execErr := executeSeasonCommand(ctx, tx, p)
@@ -277,6 +428,15 @@ func ExecuteCommand(ctx context.Context, pool *pgxpool.Pool, playerID string, cm
 		message = out
 		logsToInsert = append(logsToInsert, logToInsert{kind: "SYSTEM", msg: out})
 
+	case "HALL":
+		out, execErr := executeHallCommand(ctx, tx, cmd.Quantity)
+		if execErr != nil {
+			return CommandResponse{OK: false, Error: "db error"}, execErr
+		}
+		success = true
+		message = out
+		logsToInsert = append(logsToInsert, logToInsert{kind: "SYSTEM", msg: out})
+
 	case "HELP":
 		success = true
 		message = helpText()
@@ -331,6 +491,69 @@ func ExecuteCommand(ctx context.Context, pool *pgxpool.Pool, playerID string, cm
 	}, nil
 }
 
+type sectorArrival struct {
+	Messages []string
+	Logs     []logToInsert
+	// Hostile is set when the arrival cost the player something (invasion raid or mine strike).
+	Hostile bool
+}
+
+// enterSector applies everything that happens when a player arrives in p.SectorID:
+// discovery, sector events, mine strikes and hazard intel.
+func enterSector(ctx context.Context, tx pgx.Tx, p *Player) (sectorArrival, error) {
+	var out sectorArrival
+	_ = MarkDiscovered(ctx, tx, p.ID, p.SectorID)
+
+	evtMsg, evtKind, evtLog, err := applySectorEventOnEntry(ctx, tx, p)
+	if err != nil {
+		return sectorArrival{}, err
+	}
+	if evtMsg != "" {
+		out.Messages = append(out.Messages, evtMsg)
+		if evtLog != "" {
+			out.Logs = append(out.Logs, logToInsert{kind: evtKind, msg: evtLog})
+		}
+		if evtKind == "COMBAT" {
+			out.Hostile = true
+		}
+	}
+
+	strikeMsg, strikeLog, err := applyMineStrike(ctx, tx, p)
+	if err != nil {
+		return sectorArrival{}, err
+	}
+	if strikeMsg != "" {
+		out.Messages = append(out.Messages, strikeMsg)
+		if strikeLog != "" {
+			out.Logs = append(out.Logs, logToInsert{kind: "COMBAT", msg: strikeLog})
+		}
+		out.Hostile = true
+	}
+
+	rockMsg, err := asteroidStrike(ctx, tx, p, rand.Intn(100))
+	if err != nil {
+		return sectorArrival{}, err
+	}
+	if rockMsg != "" {
+		out.Messages = append(out.Messages, rockMsg)
+		out.Logs = append(out.Logs, logToInsert{kind: "COMBAT", msg: rockMsg})
+	}
+
+	inspectMsg, err := contrabandInspection(ctx, tx, p, rand.Intn(100))
+	if err != nil {
+		return sectorArrival{}, err
+	}
+	if inspectMsg != "" {
+		out.Messages = append(out.Messages, inspectMsg)
+		out.Logs = append(out.Logs, logToInsert{kind: "SYSTEM", msg: inspectMsg})
+	}
+
+	if err := RecordHazardIntel(ctx, tx, *p, p.SectorID); err != nil {
+		return sectorArrival{}, err
+	}
+	return out, nil
+}
+
 func failWithState(ctx context.Context, pool *pgxpool.Pool, tx pgx.Tx, p Player, msg, code string) (CommandResponse, error) {
 	// persist regen changes only
 	_ = SavePlayer(ctx, tx, p)
@@ -350,13 +573,22 @@ func failWithState(ctx context.Context, pool *pgxpool.Pool, tx pgx.Tx, p Player,
 
 func HelpLines() []string {
 	return []string{
-		"Core: SCAN | MOVE {to} | TRADE {BUY|SELL} {ORE|ORGANICS|EQUIPMENT} {qty}",
-		"Phase2: PLANET INFO | PLANET COLONIZE [name] | PLANET LOAD {commodity} {qty} | PLANET UNLOAD {commodity} {qty} | PLANET UPGRADE CITADEL",
-		"Phase2: CORP INFO | CORP CREATE {name} | CORP JOIN {name} | CORP LEAVE | CORP SAY {message} | CORP DEPOSIT {credits} | CORP WITHDRAW {credits}",
+		"Core: SCAN | SCAN DENSITY | SCAN HOLO | MOVE {to} | TRADE {BUY|SELL} {ORE|ORGANICS|EQUIPMENT} {qty} | TRADE BUY COLONISTS {qty}",
+		"Phase2: PLANET INFO | PLANET COLONIZE [name] | PLANET LOAD {commodity} {qty} | PLANET UNLOAD {commodity} {qty} | PLANET UNLOAD COLONISTS {qty} | PLANET UPGRADE CITADEL",
+		"Phase2: CORP INFO | CORP CREATE {name} | CORP JOIN {name} | CORP LEAVE | CORP SAY {message} | CORP DEPOSIT {credits} | CORP WITHDRAW {credits} | CORP INTEREST {bps}",
+		"Phase2: CORP MODE {OPEN|INVITE|APPLY} | CORP INVITE {user} | CORP APPLY {name} | CORP ACCEPT {user|corp} | CORP REJECT {user|corp} | CORP INVITES",
+		"Phase2: CORP PROMOTE {user} | CORP DEMOTE {user} | CORP KICK {user} | CORP TRANSFER {user} | CORP MUTE {user} | CORP UNMUTE {user} | CORP PERMISSIONS | CORP PERMIT {permission} {role}",
+		"Phase2: CORP DIPLOMACY | CORP DIPLOMACY PROPOSE {corp} {ALLIED [PLANETS]|NAP|NEUTRAL|WAR} | CORP DIPLOMACY ACCEPT {corp} | CORP DIPLOMACY BREAK {corp}",
+		"Phase2: CORP INTEL {ON|OFF} | CORP SHARE INTEL {sector...|ALL}",
+		"Phase2: CORP LEDGER [n] | CORP LIMIT {OFFICER|MEMBER|APPROVAL} {credits} | CORP APPROVE {request} | CORP DENY {request}",
 		"Phase2: MINE DEPLOY {qty} | MINE SWEEP",
-		"Phase2: SHIPYARD | SHIPYARD BUY {SCOUT|TRADER|FREIGHTER|INTERCEPTOR} | SHIPYARD SELL | SHIPYARD UPGRADE {CARGO|TURNS}",
-		"Phase2: RANKINGS | SEASON",
-		"Phase3: MARKET [ORE|ORGANICS|EQUIPMENT] | ROUTE [ORE|ORGANICS|EQUIPMENT] | EVENTS",
+		"Phase2: SHIPYARD | SHIPYARD BUY {SCOUT|TRADER|FREIGHTER|INTERCEPTOR} | SHIPYARD SELL | SHIPYARD UPGRADE {CARGO|TURNS|SCANNER}",
+		"Phase2: STARDOCK | STARDOCK BUY {HOLDS|MINES|SCANNER} [qty] | FUEL | FUEL BUY [turns] | BLACKMARKET | BLACKMARKET {BUY|SELL} {qty}",
+		"Phase2: BANK | BANK DEPOSIT {credits} | BANK WITHDRAW {credits} | BANK LOAN {credits} | BANK REPAY [credits]",
+		"Phase2: BEACON | BEACON SET {text} | BEACON CLEAR | BEACON REPORT",
+		"Phase2: GATE | GATE BUILD {to} [CORP] | GATE TOLL {to} {credits} | GATE DESTROY {to}",
+		"Phase2: RANKINGS [NETWORTH|XP|PLANETS|CORPS] [page] | SEASON | HALL [season]",
+		"Phase3: MARKET [ORE|ORGANICS|EQUIPMENT] | ROUTE [FAST|SAFE|PROTECTED] [ORE|ORGANICS|EQUIPMENT] | ROUTE RUN [FAST|SAFE|PROTECTED] [commodity] [trips] | EVENTS | NEWS",
 	}
 }
 
@@ -368,13 +600,16 @@ func effectiveCommandCost(p Player, cmd CommandRequest) int {
 	if p.IsAdmin {
 		return 0
 	}
+	if cmd.Type == "SCAN" {
+		return scanTurnCost(cmd.Action, p.ShipType)
+	}
 	return commandCost(cmd)
 }
 
 func commandCost(cmd CommandRequest) int {
 	switch cmd.Type {
 	case "SCAN":
-		return 1
+		return scanTurnCost(cmd.Action, "")
 	case "MOVE":
 		return 1
 	case "TRADE":
@@ -397,7 +632,23 @@ func commandCost(cmd CommandRequest) int {
 		default:
 			return 0
 		}
-	case "HELP", "CORP", "RANKINGS", "SEASON", "MARKET", "ROUTE", "EVENTS", "SHIPYARD":
+	case "GATE":
+		switch cmd.Action {
+		case "BUILD":
+			return 10
+		case "DESTROY":
+			return 5
+		default:
+			return 0
+		}
+	case "BLACKMARKET":
+		switch cmd.Action {
+		case "BUY", "SELL":
+			return 1
+		default:
+			return 0
+		}
+	case "HELP", "CORP", "RANKINGS", "SEASON", "HALL", "MARKET", "ROUTE", "EVENTS", "NEWS", "SHIPYARD", "BANK", "BEACON", "STARDOCK", "FUEL":
 		return 0
 	default:
 		return 0
@@ -0,0 +1,186 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	EnvNormal        = "NORMAL"
+	EnvNebula        = "NEBULA"
+	EnvIonStorm      = "ION_STORM"
+	EnvAsteroidField = "ASTEROID_FIELD"
+
+	// Events of this kind turn their sector into an ion storm while they last.
+	EventKindIonStorm = "ION_STORM"
+
+	// Moving into an ion storm costs this many times the normal MOVE cost.
+	ionStormMoveMultiplier = 2
+	// Chance (percent) that entering an asteroid field damages the ship, and the repair bill range.
+	asteroidHitPct       = 35
+	asteroidDamageMin    = 10
+	asteroidDamageSpread = 31 // 10..40 credits
+)
+
+// Share of non-Protectorate sectors (percent) given each environment by the generator.
+var environmentShares = []struct {
+	Env string
+	Pct int
+}{
+	{EnvNebula, 8},
+	{EnvIonStorm, 5},
+	{EnvAsteroidField, 10},
+}
+
+// pickEnvironment chooses a sector's environment deterministically from the universe seed.
+func pickEnvironment(seed int64, sectorID int) string {
+	rng := rand.New(rand.NewSource(seed + 0x454e5653 + int64(sectorID)*7919)) // "ENVS"
+	roll := rng.Intn(100)
+	for _, s := range environmentShares {
+		if roll < s.Pct {
+			return s.Env
+		}
+		roll -= s.Pct
+	}
+	return EnvNormal
+}
+
+// effectiveEnvironment combines a sector's stored environment with its active event, if any.
+func effectiveEnvironment(base, eventKind string) string {
+	if eventKind == EventKindIonStorm {
+		return EnvIonStorm
+	}
+	if base == "" {
+		return EnvNormal
+	}
+	return base
+}
+
+// EnsureSectorEnvironments assigns an environment to every sector that has none yet (new
+// universes, older databases and expansions). Protectorate space is always NORMAL. Safe to run on
+// every startup.
+func EnsureSectorEnvironments(ctx context.Context, pool *pgxpool.Pool, seed int64) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := ensureSectorEnvironmentsTx(ctx, tx, seed); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func ensureSectorEnvironmentsTx(ctx context.Context, tx pgx.Tx, seed int64) error {
+	rows, err := tx.Query(ctx, "SELECT id, is_protectorate FROM sectors WHERE environment = '' ORDER BY id")
+	if err != nil {
+		return err
+	}
+	assign := map[int]string{}
+	var ids []int
+	for rows.Next() {
+		var id int
+		var prot bool
+		if err := rows.Scan(&id, &prot); err != nil {
+			rows.Close()
+			return err
+		}
+		env := EnvNormal
+		if !prot {
+			env = pickEnvironment(seed, id)
+		}
+		assign[id] = env
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	batch := &pgx.Batch{}
+	for _, id := range ids {
+		batch.Queue("UPDATE sectors SET environment=$2 WHERE id=$1", id, assign[id])
+	}
+	return tx.SendBatch(ctx, batch).Close()
+}
+
+// SectorEnvironment returns the effective environment of a sector (NORMAL if it does not exist).
+func SectorEnvironment(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, sectorID int) (string, error) {
+	var base, eventKind string
+	err := q.QueryRow(ctx, `
+		SELECT
+			s.environment,
+			COALESCE((
+				SELECT e.kind FROM events e
+				WHERE e.sector_id = s.id AND e.kind = $2 AND e.active = true AND e.ends_at > now()
+				LIMIT 1
+			), '')
+		FROM sectors s
+		WHERE s.id = $1
+	`, sectorID, EventKindIonStorm).Scan(&base, &eventKind)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return EnvNormal, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return effectiveEnvironment(base, eventKind), nil
+}
+
+// commandTurnCost is the turn cost of cmd including terrain: moving into an ion storm costs double.
+func commandTurnCost(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, p Player, cmd CommandRequest) (int, error) {
+	cost := effectiveCommandCost(p, cmd)
+	if cmd.Type != "MOVE" || cost == 0 {
+		return cost, nil
+	}
+	env, err := SectorEnvironment(ctx, q, cmd.To)
+	if err != nil {
+		return 0, err
+	}
+	if env == EnvIonStorm {
+		cost *= ionStormMoveMultiplier
+	}
+	return cost, nil
+}
+
+// asteroidDamage is the repair bill for entering an asteroid field given a 0..99 roll.
+func asteroidDamage(roll int) int64 {
+	if roll < 0 || roll >= asteroidHitPct {
+		return 0
+	}
+	return int64(asteroidDamageMin + roll*asteroidDamageSpread/asteroidHitPct)
+}
+
+// asteroidExpectedDamage is the average repair bill per entry into an asteroid field.
+func asteroidExpectedDamage() int64 {
+	var total int64
+	for roll := 0; roll < 100; roll++ {
+		total += asteroidDamage(roll)
+	}
+	return total / 100
+}
+
+// asteroidStrike applies asteroid field damage on sector entry as a repair bill, capped at the
+// player's credits. It returns "" when the sector is clear or the ship came through unscathed.
+func asteroidStrike(ctx context.Context, tx pgx.Tx, p *Player, roll int) (string, error) {
+	env, err := SectorEnvironment(ctx, tx, p.SectorID)
+	if err != nil || env != EnvAsteroidField {
+		return "", err
+	}
+	dmg := min(asteroidDamage(roll), p.Credits)
+	if dmg <= 0 {
+		return "", nil
+	}
+	p.Credits -= dmg
+	return fmt.Sprintf("Asteroid field: debris grazes your hull. Repairs cost %d credits.", dmg), nil
+}
@@ -0,0 +1,49 @@
+package game
+
+import "testing"
+
+func TestPlanPathFastCountsIonStormTurns(t *testing.T) {
+	adj := map[int][]int{
+		1: {2, 3},
+		2: {4},
+		3: {4},
+	}
+	hazards := map[int]SectorHazard{
+		2: {Environment: EnvIonStorm},
+	}
+
+	path, ok := PlanPath(1, 4, adj, hazards, RouteModeFast)
+	if !ok {
+		t.Fatalf("expected path")
+	}
+	if got := formatPath(path); got != "1 > 3 > 4" {
+		t.Fatalf("fast path: got %q", got)
+	}
+}
+
+func TestAsteroidDamage(t *testing.T) {
+	if got := asteroidDamage(asteroidHitPct); got != 0 {
+		t.Fatalf("miss: got %d", got)
+	}
+	if got := asteroidDamage(0); got != asteroidDamageMin {
+		t.Fatalf("min hit: got %d", got)
+	}
+	if got := asteroidDamage(asteroidHitPct - 1); got < asteroidDamageMin || got > 40 {
+		t.Fatalf("max hit: got %d", got)
+	}
+	if got := asteroidExpectedDamage(); got <= 0 || got > 40 {
+		t.Fatalf("expected damage: got %d", got)
+	}
+}
+
+func TestEffectiveEnvironment(t *testing.T) {
+	if got := effectiveEnvironment("", ""); got != EnvNormal {
+		t.Fatalf("unassigned: got %q", got)
+	}
+	if got := effectiveEnvironment(EnvNebula, EventKindIonStorm); got != EnvIonStorm {
+		t.Fatalf("storm event: got %q", got)
+	}
+	if got := effectiveEnvironment(EnvAsteroidField, "INVASION"); got != EnvAsteroidField {
+		t.Fatalf("other event: got %q", got)
+	}
+}
@@ -73,6 +73,14 @@ func pricePercentForCommodity(e ActiveEvent, commodity string) int {
 	return 100
 }
 
+// invasionPenaltyCredits is the credit loss for entering a sector with an active invasion.
+func invasionPenaltyCredits(severity int) int64 {
+	if severity < 1 {
+		return 0
+	}
+	return int64(severity) * 200
+}
+
 func applySectorEventOnEntry(ctx context.Context, tx pgx.Tx, p *Player) (respMsg string, logKind string, logMsg string, err error) {
 	e, ok, err := LoadActiveEvent(ctx, tx, p.SectorID)
 	if err != nil || !ok {
@@ -87,10 +95,7 @@ func applySectorEventOnEntry(ctx context.Context, tx pgx.Tx, p *Player) (respMsg
 	switch e.Kind {
 	case "INVASION":
 		// Credits penalty on entry (capped at available credits).
-		penalty := int64(e.Severity) * 200
-		if penalty < 0 {
-			penalty = 0
-		}
+		penalty := invasionPenaltyCredits(e.Severity)
 		if penalty > p.Credits {
 			penalty = p.Credits
 		}
@@ -121,35 +126,26 @@ func StartEventTicker(ctx context.Context, pool *pgxpool.Pool, tickSeconds int)
 	}
 	// Deliberately not deterministic; events are meant to feel "alive".
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	ticker := time.NewTicker(time.Duration(tickSeconds) * time.Second)
-
-	go func() {
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				// Expire old events.
-				_, _ = pool.Exec(ctx, `UPDATE events SET active=false WHERE active=true AND ends_at <= now()`)
-
-				var activeCount int
-				if err := pool.QueryRow(ctx, `SELECT COUNT(1) FROM events WHERE active=true`).Scan(&activeCount); err != nil {
-					continue
-				}
-				if activeCount >= 5 {
-					continue
-				}
-
-				// Probabilistic creation to avoid predictable spam.
-				if rng.Float64() > 0.35 {
-					continue
-				}
-
-				_ = createRandomEvent(ctx, pool, rng)
-			}
+	pick := func(r SeasonRules) int { return r.EventTickSeconds }
+	startRulesTicker(ctx, pool, tickSeconds, 10, pick, func() {
+		// Expire old events.
+		_, _ = pool.Exec(ctx, `UPDATE events SET active=false WHERE active=true AND ends_at <= now()`)
+
+		var activeCount int
+		if err := pool.QueryRow(ctx, `SELECT COUNT(1) FROM events WHERE active=true`).Scan(&activeCount); err != nil {
+			return
+		}
+		if activeCount >= 5 {
+			return
+		}
+
+		// Probabilistic creation to avoid predictable spam.
+		if rng.Float64() > 0.35 {
+			return
 		}
-	}()
+
+		_ = createRandomEvent(ctx, pool, rng)
+	})
 }
 
 func createRandomEvent(ctx context.Context, pool *pgxpool.Pool, rng *rand.Rand) error {
@@ -159,12 +155,16 @@ func createRandomEvent(ctx context.Context, pool *pgxpool.Pool, rng *rand.Rand)
 		kind = "ANOMALY"
 	} else if roll < 85 {
 		kind = "INVASION"
-	} else {
+	} else if roll < 93 {
 		kind = "LIMITED"
+	} else {
+		kind = EventKindIonStorm
 	}
 
 	var sectorID int
-	if kind == "INVASION" {
+	if kind == EventKindIonStorm {
+		_ = pool.QueryRow(ctx, `SELECT id FROM sectors WHERE is_protectorate=false ORDER BY random() LIMIT 1`).Scan(&sectorID)
+	} else if kind == "INVASION" {
 		_ = pool.QueryRow(ctx, `SELECT id FROM sectors ORDER BY random() LIMIT 1`).Scan(&sectorID)
 	} else {
 		_ = pool.QueryRow(ctx, `SELECT sector_id FROM ports ORDER BY random() LIMIT 1`).Scan(&sectorID)
@@ -207,6 +207,12 @@ func createRandomEvent(ctx context.Context, pool *pgxpool.Pool, rng *rand.Rand)
 		severity = 1 + rng.Intn(3) // 1..3
 		title = "Raider Invasion"
 		desc = "Hostile raiders are harassing traffic in this sector. Entry may cost credits."
+	case EventKindIonStorm:
+		commodity = "ALL"
+		pricePercent = 100
+		severity = 1
+		title = "Ion Storm"
+		desc = "A passing ion storm is scrambling drives. Moving into this sector costs double turns."
 	}
 
 	durMin := 20 + rng.Intn(41) // 20..60
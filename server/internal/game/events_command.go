@@ -77,8 +77,10 @@ func executeEventsCommand(ctx context.Context, tx pgx.Tx, p Player) (string, err
 			}
 			effect = fmt.Sprintf("prices %d%% (%s)", r.PricePercent, comm)
 		case "INVASION":
-			penalty := int64(r.Severity) * 200
+			penalty := invasionPenaltyCredits(r.Severity)
 			effect = fmt.Sprintf("entry penalty ~%d credits", penalty)
+		case EventKindIonStorm:
+			effect = fmt.Sprintf("MOVE costs x%d", ionStormMoveMultiplier)
 		default:
 			effect = ""
 		}
@@ -0,0 +1,346 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	gateBuildCredits   = 150000
+	gateBuildEquipment = 500 // taken from the storage of the planet anchoring the gate
+	gateMinCitadel     = 5
+	gateMaxToll        = 10000
+)
+
+type gateRow struct {
+	ID            int64
+	SectorA       int
+	SectorB       int
+	OwnerPlayerID string
+	OwnerCorpID   string
+	OwnerName     string
+	Toll          int64
+}
+
+// other returns the far end of the gate as seen from sectorID.
+func (g gateRow) other(sectorID int) int {
+	if g.SectorA == sectorID {
+		return g.SectorB
+	}
+	return g.SectorA
+}
+
+// ownedBy reports whether p owns the gate, personally or through their corporation.
+func (g gateRow) ownedBy(p Player) bool {
+	if g.OwnerPlayerID != "" && g.OwnerPlayerID == p.ID {
+		return true
+	}
+	return g.OwnerCorpID != "" && g.OwnerCorpID == p.CorpID
+}
+
+// abandoned reports whether the gate's owner is gone (the owner FKs are ON DELETE SET NULL).
+func (g gateRow) abandoned() bool {
+	return g.OwnerPlayerID == "" && g.OwnerCorpID == ""
+}
+
+// tollFor is what p pays to jump through the gate. Owners and admins travel free, and an
+// abandoned gate has nobody to collect.
+func (g gateRow) tollFor(p Player) int64 {
+	if p.IsAdmin || g.ownedBy(p) || g.abandoned() {
+		return 0
+	}
+	return g.Toll
+}
+
+// canDestroy reports whether p may tear the gate down: its owner, an admin, or anyone once the
+// gate is abandoned.
+func (g gateRow) canDestroy(p Player) bool {
+	return p.IsAdmin || g.ownedBy(p) || g.abandoned()
+}
+
+func gatePair(x, y int) (int, int) {
+	if x > y {
+		return y, x
+	}
+	return x, y
+}
+
+const gateSelect = `
+	SELECT
+		g.id, g.sector_a, g.sector_b,
+		COALESCE(g.owner_player_id, ''), COALESCE(g.owner_corp_id, ''),
+		COALESCE(c.name, u.username, ''),
+		g.toll
+	FROM warp_gates g
+	LEFT JOIN corporations c ON c.id = g.owner_corp_id
+	LEFT JOIN players p ON p.id = g.owner_player_id
+	LEFT JOIN users u ON u.id = p.user_id
+`
+
+func scanGate(row pgx.Row) (gateRow, error) {
+	var g gateRow
+	err := row.Scan(&g.ID, &g.SectorA, &g.SectorB, &g.OwnerPlayerID, &g.OwnerCorpID, &g.OwnerName, &g.Toll)
+	return g, err
+}
+
+func loadGateBetween(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, x, y int) (gateRow, bool, error) {
+	a, b := gatePair(x, y)
+	g, err := scanGate(q.QueryRow(ctx, gateSelect+" WHERE g.sector_a=$1 AND g.sector_b=$2", a, b))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return gateRow{}, false, nil
+	}
+	if err != nil {
+		return gateRow{}, false, err
+	}
+	return g, true, nil
+}
+
+// loadSectorGates returns the gates touching sectorID as views from that sector.
+func loadSectorGates(ctx context.Context, q interface {
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+}, sectorID int) ([]GateView, error) {
+	rows, err := q.Query(ctx, gateSelect+" WHERE g.sector_a=$1 OR g.sector_b=$1 ORDER BY g.sector_a, g.sector_b", sectorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []GateView
+	for rows.Next() {
+		g, err := scanGate(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, GateView{To: g.other(sectorID), Owner: g.OwnerName, Toll: g.Toll})
+	}
+	return out, rows.Err()
+}
+
+func executeGateCommand(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRequest) (phase2Result, error) {
+	switch strings.ToUpper(strings.TrimSpace(cmd.Action)) {
+	case "", "INFO":
+		return gateInfo(ctx, tx, *p)
+	case "BUILD":
+		return gateBuild(ctx, tx, p, cmd.To, strings.EqualFold(strings.TrimSpace(cmd.Name), "CORP"))
+	case "TOLL":
+		return gateSetToll(ctx, tx, *p, cmd.To, cmd.Quantity)
+	case "DESTROY":
+		return gateDestroy(ctx, tx, p, cmd.To)
+	default:
+		return phase2Result{OK: false, Message: "Unknown GATE subcommand.", ErrorCode: "UNKNOWN_SUBCOMMAND"}, nil
+	}
+}
+
+func gateInfo(ctx context.Context, tx pgx.Tx, p Player) (phase2Result, error) {
+	gates, err := loadSectorGates(ctx, tx, p.SectorID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	lines := []string{}
+	if len(gates) == 0 {
+		lines = append(lines, "No warp gates in this sector.")
+	}
+	for _, g := range gates {
+		lines = append(lines, fmt.Sprintf("Gate to sector %d (owner: %s, toll: %d credits)", g.To, g.Owner, g.Toll))
+	}
+	lines = append(lines, fmt.Sprintf(
+		"GATE BUILD {to} [CORP] costs %d credits and %d equipment from a planet you own with citadel level %d+ at either end.",
+		gateBuildCredits, gateBuildEquipment, gateMinCitadel))
+	return phase2Result{OK: true, Message: strings.Join(lines, "\n")}, nil
+}
+
+// gateBuild opens a two-way warp between the player's sector and a discovered sector. One end must
+// hold a planet the player (or their corp) owns with a strong enough citadel; that planet supplies
+// the equipment. With CORP the corp bank pays and the corp owns the gate.
+func gateBuild(ctx context.Context, tx pgx.Tx, p *Player, to int, corpFunded bool) (phase2Result, error) {
+	if to < 1 || to == p.SectorID {
+		return phase2Result{OK: false, Message: "GATE BUILD requires another sector (e.g. GATE BUILD 42).", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	var discovered bool
+	err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM player_discoveries WHERE player_id=$1 AND sector_id=$2)", p.ID, to).Scan(&discovered)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !discovered {
+		return phase2Result{OK: false, Message: "You can only build gates to sectors you have discovered.", ErrorCode: "NOT_DISCOVERED"}, nil
+	}
+	var linked bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM warps WHERE (from_sector=$1 AND to_sector=$2) OR (from_sector=$2 AND to_sector=$1))
+	`, p.SectorID, to).Scan(&linked)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if linked {
+		return phase2Result{OK: false, Message: "Those sectors are already linked by a warp.", ErrorCode: "WARP_EXISTS"}, nil
+	}
+	if corpFunded {
+		if p.CorpID == "" {
+			return phase2Result{OK: false, Message: "You are not in a corporation.", ErrorCode: "NOT_IN_CORP"}, nil
+		}
+		if !p.corpAllows(CorpPermWithdraw) {
+			return phase2Result{OK: false, Message: "Your corp role does not allow spending corp funds.", ErrorCode: "FORBIDDEN"}, nil
+		}
+	}
+
+	var anchor planetForUpdate
+	found := false
+	for _, sid := range []int{p.SectorID, to} {
+		pl, ok, err := loadPlanet(ctx, tx, sid, true)
+		if err != nil {
+			return phase2Result{}, err
+		}
+		// Allied planets do not anchor gates: the equipment is the owner's to spend.
+		if ok && ownsPlanet(*p, pl) && canAccessPlanet(*p, pl) && pl.CitadelLevel >= gateMinCitadel {
+			anchor, found = pl, true
+			break
+		}
+	}
+	if !found {
+		msg := fmt.Sprintf("A gate needs a planet you own with citadel level %d+ in this sector or sector %d.", gateMinCitadel, to)
+		return phase2Result{OK: false, Message: msg, ErrorCode: "NO_ANCHOR"}, nil
+	}
+	if anchor.StorageEquipment < gateBuildEquipment {
+		msg := fmt.Sprintf("%s needs %d equipment in storage to build a gate (has %d).", anchor.Name, gateBuildEquipment, anchor.StorageEquipment)
+		return phase2Result{OK: false, Message: msg, ErrorCode: "INSUFFICIENT_EQUIPMENT"}, nil
+	}
+
+	var ownerPlayer, ownerCorp any
+	if corpFunded {
+		var newCredits int64
+		err := tx.QueryRow(ctx, "UPDATE corporations SET credits = credits - $2 WHERE id=$1 AND credits >= $2 RETURNING credits", p.CorpID, gateBuildCredits).Scan(&newCredits)
+		if errors.Is(err, pgx.ErrNoRows) {
+			msg := fmt.Sprintf("Corp bank needs %d credits to build a gate.", gateBuildCredits)
+			return phase2Result{OK: false, Message: msg, ErrorCode: "INSUFFICIENT_FUNDS"}, nil
+		}
+		if err != nil {
+			return phase2Result{}, err
+		}
+		p.CorpCredits = newCredits
+		ownerCorp = p.CorpID
+		note := fmt.Sprintf("gate %d <-> %d", p.SectorID, to)
+		if err := recordCorpBankTx(ctx, tx, p.CorpID, p.ID, CorpTxGateBuild, -gateBuildCredits, newCredits, note); err != nil {
+			return phase2Result{}, err
+		}
+	} else {
+		if p.Credits < gateBuildCredits {
+			msg := fmt.Sprintf("A gate costs %d credits.", gateBuildCredits)
+			return phase2Result{OK: false, Message: msg, ErrorCode: "INSUFFICIENT_CREDITS"}, nil
+		}
+		p.Credits -= gateBuildCredits
+		ownerPlayer = p.ID
+	}
+
+	anchor.StorageEquipment -= gateBuildEquipment
+	if err := savePlanetStorage(ctx, tx, anchor); err != nil {
+		return phase2Result{}, err
+	}
+
+	a, b := gatePair(p.SectorID, to)
+	var gateID int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO warp_gates(sector_a, sector_b, owner_player_id, owner_corp_id)
+		VALUES ($1,$2,$3,$4)
+		RETURNING id
+	`, a, b, ownerPlayer, ownerCorp).Scan(&gateID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO warps(from_sector, to_sector, one_way, gate_id)
+		VALUES ($1,$2,false,$3), ($2,$1,false,$3)
+	`, a, b, gateID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+
+	owner := "you"
+	if corpFunded {
+		owner = p.CorpName
+	}
+	msg := fmt.Sprintf("Warp gate built between sectors %d and %d (owner: %s). Cost: %d credits, %d equipment from %s.",
+		p.SectorID, to, owner, gateBuildCredits, gateBuildEquipment, anchor.Name)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+func gateSetToll(ctx context.Context, tx pgx.Tx, p Player, to int, toll int) (phase2Result, error) {
+	g, ok, err := loadGateBetween(ctx, tx, p.SectorID, to)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !ok {
+		return phase2Result{OK: false, Message: fmt.Sprintf("No gate between this sector and sector %d.", to), ErrorCode: "NO_GATE"}, nil
+	}
+	if !g.ownedBy(p) {
+		return phase2Result{OK: false, Message: "Only the gate's owner can set its toll.", ErrorCode: "NOT_OWNER"}, nil
+	}
+	if toll < 0 || toll > gateMaxToll {
+		return phase2Result{OK: false, Message: fmt.Sprintf("Toll must be between 0 and %d credits.", gateMaxToll), ErrorCode: "INVALID_ARGS"}, nil
+	}
+	if _, err := tx.Exec(ctx, "UPDATE warp_gates SET toll=$2 WHERE id=$1", g.ID, toll); err != nil {
+		return phase2Result{}, err
+	}
+	msg := fmt.Sprintf("Toll for the gate between sectors %d and %d set to %d credits.", g.SectorA, g.SectorB, toll)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+// gateDestroy tears down a gate from either end. Only its owner or an admin may, or anyone once
+// the gate is abandoned.
+func gateDestroy(ctx context.Context, tx pgx.Tx, p *Player, to int) (phase2Result, error) {
+	g, ok, err := loadGateBetween(ctx, tx, p.SectorID, to)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !ok {
+		return phase2Result{OK: false, Message: fmt.Sprintf("No gate between this sector and sector %d.", to), ErrorCode: "NO_GATE"}, nil
+	}
+	if !g.canDestroy(*p) {
+		msg := fmt.Sprintf("This gate belongs to %s; only its owner can destroy it.", g.OwnerName)
+		return phase2Result{OK: false, Message: msg, ErrorCode: "NOT_OWNER"}, nil
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM warp_gates WHERE id=$1", g.ID); err != nil {
+		return phase2Result{}, err
+	}
+
+	msg := fmt.Sprintf("Warp gate between sectors %d and %d destroyed.", g.SectorA, g.SectorB)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+// gateTollDue returns the gate on the lane from->to and the toll p owes to use it (0 when there is
+// no gate, no toll, the gate is abandoned, or p owns the gate or is an admin).
+func gateTollDue(ctx context.Context, tx pgx.Tx, p Player, from, to int) (gateRow, int64, error) {
+	g, ok, err := loadGateBetween(ctx, tx, from, to)
+	if err != nil || !ok {
+		return gateRow{}, 0, err
+	}
+	return g, g.tollFor(p), nil
+}
+
+// payGateToll moves the toll from p to the gate's owner (the corp bank for corp gates).
+func payGateToll(ctx context.Context, tx pgx.Tx, p *Player, g gateRow, toll int64) (string, error) {
+	if toll <= 0 || g.abandoned() {
+		return "", nil
+	}
+	p.Credits -= toll
+	var err error
+	switch {
+	case g.OwnerCorpID != "":
+		var balance int64
+		err = tx.QueryRow(ctx, "UPDATE corporations SET credits = credits + $2 WHERE id=$1 RETURNING credits", g.OwnerCorpID, toll).Scan(&balance)
+		if err == nil {
+			note := fmt.Sprintf("gate %d <-> %d", g.SectorA, g.SectorB)
+			err = recordCorpBankTx(ctx, tx, g.OwnerCorpID, p.ID, CorpTxToll, toll, balance, note)
+		}
+	case g.OwnerPlayerID != "":
+		_, err = tx.Exec(ctx, "UPDATE players SET credits = credits + $2 WHERE id=$1", g.OwnerPlayerID, toll)
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Paid a %d credit gate toll to %s.", toll, g.OwnerName), nil
+}
@@ -0,0 +1,43 @@
+package game
+
+import "testing"
+
+func TestGateOwnership(t *testing.T) {
+	personal := gateRow{SectorA: 3, SectorB: 9, OwnerPlayerID: "p1"}
+	corp := gateRow{SectorA: 3, SectorB: 9, OwnerCorpID: "c1"}
+
+	if !personal.ownedBy(Player{ID: "p1"}) || personal.ownedBy(Player{ID: "p2", CorpID: "c1"}) {
+		t.Fatalf("personal gate ownership wrong")
+	}
+	if !corp.ownedBy(Player{ID: "p2", CorpID: "c1"}) || corp.ownedBy(Player{ID: "p3"}) {
+		t.Fatalf("corp gate ownership wrong")
+	}
+	if got := personal.other(9); got != 3 {
+		t.Fatalf("other end: got %d want 3", got)
+	}
+	if a, b := gatePair(9, 3); a != 3 || b != 9 {
+		t.Fatalf("gatePair: got %d,%d", a, b)
+	}
+}
+
+func TestGateTollAndDestroy(t *testing.T) {
+	g := gateRow{SectorA: 3, SectorB: 9, OwnerPlayerID: "p1", Toll: 500}
+	owner := Player{ID: "p1"}
+	stranger := Player{ID: "p2"}
+	admin := Player{ID: "p3", IsAdmin: true}
+
+	if g.tollFor(stranger) != 500 || g.tollFor(owner) != 0 || g.tollFor(admin) != 0 {
+		t.Fatalf("toll: stranger %d owner %d admin %d", g.tollFor(stranger), g.tollFor(owner), g.tollFor(admin))
+	}
+	if g.canDestroy(stranger) || !g.canDestroy(owner) || !g.canDestroy(admin) {
+		t.Fatalf("only the owner or an admin may destroy an owned gate")
+	}
+
+	abandoned := gateRow{SectorA: 3, SectorB: 9, Toll: 500}
+	if !abandoned.abandoned() || abandoned.tollFor(stranger) != 0 {
+		t.Fatalf("abandoned gate should charge nothing")
+	}
+	if !abandoned.canDestroy(stranger) {
+		t.Fatalf("anyone may destroy an abandoned gate")
+	}
+}
@@ -0,0 +1,282 @@
+package game
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	RouteModeFast      = "FAST"
+	RouteModeSafe      = "SAFE"
+	RouteModeProtected = "PROTECTED" // SAFE, and also prefers Protectorate space
+
+	// In SAFE/PROTECTED modes, entering a known hazard costs as much as this many extra moves.
+	hazardStepPenalty = 10
+	// In PROTECTED mode, entering non-Protectorate space costs one extra move.
+	openSpaceStepPenalty = 1
+)
+
+// SectorHazard is what a player knows about the dangers of a sector.
+type SectorHazard struct {
+	HostileMines     int
+	InvasionSeverity int
+	IsProtectorate   bool
+	Environment      string
+}
+
+func (h SectorHazard) Dangerous() bool {
+	return h.HostileMines > 0 || h.InvasionSeverity > 0
+}
+
+// ExpectedLoss estimates the credits lost when entering the sector once under the season's rules.
+func (h SectorHazard) ExpectedLoss(rules SeasonRules) int64 {
+	loss := rules.mineDamageCredits(mineTriggerCount(h.HostileMines)) + invasionPenaltyCredits(h.InvasionSeverity)
+	if h.Environment == EnvAsteroidField {
+		loss += asteroidExpectedDamage()
+	}
+	return loss
+}
+
+// moveTurns is the number of turns a MOVE into the sector takes.
+func (h SectorHazard) moveTurns() int {
+	if h.Environment == EnvIonStorm {
+		return ionStormMoveMultiplier
+	}
+	return 1
+}
+
+// HazardExposure summarizes the known hazards along a path.
+type HazardExposure struct {
+	MineSectors     int
+	InvasionSectors int
+	OpenSectors     int // non-Protectorate sectors entered
+	ExpectedLoss    int64
+}
+
+func (e HazardExposure) Add(o HazardExposure) HazardExposure {
+	return HazardExposure{
+		MineSectors:     e.MineSectors + o.MineSectors,
+		InvasionSectors: e.InvasionSectors + o.InvasionSectors,
+		OpenSectors:     e.OpenSectors + o.OpenSectors,
+		ExpectedLoss:    e.ExpectedLoss + o.ExpectedLoss,
+	}
+}
+
+func (e HazardExposure) Summary() string {
+	if e.MineSectors == 0 && e.InvasionSectors == 0 {
+		return fmt.Sprintf("none known (%d non-Protectorate sector(s))", e.OpenSectors)
+	}
+	return fmt.Sprintf("%d mined, %d invaded, %d non-Protectorate sector(s); est. losses ~%d credits",
+		e.MineSectors, e.InvasionSectors, e.OpenSectors, e.ExpectedLoss)
+}
+
+func normalizeRouteMode(mode string) (string, bool) {
+	switch strings.ToUpper(strings.TrimSpace(mode)) {
+	case "", RouteModeFast:
+		return RouteModeFast, true
+	case RouteModeSafe:
+		return RouteModeSafe, true
+	case RouteModeProtected:
+		return RouteModeProtected, true
+	default:
+		return "", false
+	}
+}
+
+// stepCost is the path weight of entering sectorID under the given route mode.
+func stepCost(sectorID int, hazards map[int]SectorHazard, mode string) int {
+	h := hazards[sectorID]
+	cost := h.moveTurns()
+	if mode == RouteModeFast {
+		return cost
+	}
+	if h.Dangerous() {
+		cost += hazardStepPenalty
+	}
+	if mode == RouteModeProtected && !h.IsProtectorate {
+		cost += openSpaceStepPenalty
+	}
+	return cost
+}
+
+type pathItem struct {
+	sector int
+	cost   int
+}
+
+type pathQueue []pathItem
+
+func (q pathQueue) Len() int { return len(q) }
+func (q pathQueue) Less(i, j int) bool {
+	if q[i].cost != q[j].cost {
+		return q[i].cost < q[j].cost
+	}
+	return q[i].sector < q[j].sector
+}
+func (q pathQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x any)   { *q = append(*q, x.(pathItem)) }
+func (q *pathQueue) Pop() any {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
+
+// shortestPaths runs Dijkstra from start using mode-specific step costs.
+// It returns the weighted cost to every reachable sector and a predecessor map for path reconstruction.
+func shortestPaths(start int, adjacency map[int][]int, hazards map[int]SectorHazard, mode string) (map[int]int, map[int]int) {
+	cost := map[int]int{start: 0}
+	prev := map[int]int{}
+	q := &pathQueue{{sector: start, cost: 0}}
+
+	for q.Len() > 0 {
+		it := heap.Pop(q).(pathItem)
+		if it.cost > cost[it.sector] {
+			continue
+		}
+		for _, next := range adjacency[it.sector] {
+			c := it.cost + stepCost(next, hazards, mode)
+			if old, ok := cost[next]; ok && old <= c {
+				continue
+			}
+			cost[next] = c
+			prev[next] = it.sector
+			heap.Push(q, pathItem{sector: next, cost: c})
+		}
+	}
+	return cost, prev
+}
+
+// pathTo reconstructs the sector path from the shortestPaths start to goal (inclusive of both ends).
+func pathTo(prev map[int]int, start, goal int) []int {
+	path := []int{goal}
+	for cur := goal; cur != start; {
+		p, ok := prev[cur]
+		if !ok {
+			return nil
+		}
+		path = append(path, p)
+		cur = p
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// PlanPath returns the path from start to goal under the given mode, or false if unreachable.
+func PlanPath(start, goal int, adjacency map[int][]int, hazards map[int]SectorHazard, mode string) ([]int, bool) {
+	_, prev := shortestPaths(start, adjacency, hazards, mode)
+	path := pathTo(prev, start, goal)
+	if path == nil {
+		return nil, false
+	}
+	return path, true
+}
+
+// pathExposure sums hazards for every sector entered along path (the starting sector is excluded).
+func pathExposure(path []int, hazards map[int]SectorHazard, rules SeasonRules) HazardExposure {
+	var e HazardExposure
+	for i := 1; i < len(path); i++ {
+		h := hazards[path[i]]
+		if h.HostileMines > 0 {
+			e.MineSectors++
+		}
+		if h.InvasionSeverity > 0 {
+			e.InvasionSectors++
+		}
+		if !h.IsProtectorate {
+			e.OpenSectors++
+		}
+		e.ExpectedLoss += h.ExpectedLoss(rules)
+	}
+	return e
+}
+
+func formatPath(path []int) string {
+	parts := make([]string, 0, len(path))
+	for _, id := range path {
+		parts = append(parts, fmt.Sprintf("%d", id))
+	}
+	return strings.Join(parts, " > ")
+}
+
+// RecordHazardIntel stores the hostile mine count a player observed in a sector.
+// Sectors with no hostile mines are removed so stale warnings clear once a field is swept.
+// Nothing is observed inside a nebula, so earlier intel is kept as is.
+func RecordHazardIntel(ctx context.Context, tx pgx.Tx, p Player, sectorID int) error {
+	env, err := SectorEnvironment(ctx, tx, sectorID)
+	if err != nil || env == EnvNebula {
+		return err
+	}
+	var hostile int
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(qty),0)
+		FROM mines
+		WHERE sector_id=$1
+			AND owner_player_id <> $2
+			AND `+hostileMineOwnerSQL+`
+	`, sectorID, p.ID, p.CorpID).Scan(&hostile)
+	if err != nil {
+		return err
+	}
+	if hostile <= 0 {
+		_, err = tx.Exec(ctx, `DELETE FROM player_sector_hazards WHERE player_id=$1 AND sector_id=$2`, p.ID, sectorID)
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO player_sector_hazards(player_id, sector_id, hostile_mines, observed_at)
+		VALUES ($1,$2,$3,now())
+		ON CONFLICT (player_id, sector_id) DO UPDATE SET
+			hostile_mines = EXCLUDED.hostile_mines,
+			observed_at = EXCLUDED.observed_at
+	`, p.ID, sectorID, hostile)
+	return err
+}
+
+// LoadKnownHazards returns the hazards a player knows about in their discovered sectors:
+// observed hostile minefields, active invasions, Protectorate status and sector environment.
+func LoadKnownHazards(ctx context.Context, tx pgx.Tx, playerID string) (map[int]SectorHazard, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT
+			s.id,
+			s.is_protectorate,
+			COALESCE(h.hostile_mines, 0),
+			COALESCE((
+				SELECT MAX(e.severity)
+				FROM events e
+				WHERE e.sector_id = s.id AND e.kind = 'INVASION' AND e.active = true AND e.ends_at > now()
+			), 0),
+			s.environment,
+			COALESCE((
+				SELECT e.kind FROM events e
+				WHERE e.sector_id = s.id AND e.kind = $2 AND e.active = true AND e.ends_at > now()
+				LIMIT 1
+			), '')
+		FROM player_discoveries d
+		JOIN sectors s ON s.id = d.sector_id
+		LEFT JOIN player_sector_hazards h ON h.player_id = d.player_id AND h.sector_id = d.sector_id
+		WHERE d.player_id = $1
+	`, playerID, EventKindIonStorm)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[int]SectorHazard, 64)
+	for rows.Next() {
+		var id int
+		var h SectorHazard
+		var env, eventKind string
+		if err := rows.Scan(&id, &h.IsProtectorate, &h.HostileMines, &h.InvasionSeverity, &env, &eventKind); err != nil {
+			return nil, err
+		}
+		h.Environment = effectiveEnvironment(env, eventKind)
+		out[id] = h
+	}
+	return out, rows.Err()
+}
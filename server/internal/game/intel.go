@@ -27,12 +27,21 @@ type PortIntel struct {
 	EquipmentQty     int
 	EquipmentBaseQty int
 	EquipmentPrice   int
+
+	// ScannedBy is the username of the player whose scan this is (a corp mate's for pooled intel).
+	ScannedBy string
 }
 
 func CaptureScanIntel(ctx context.Context, tx pgx.Tx, playerID string, sectorID int) error {
+	// Nebulae scatter sensor returns; nothing is captured and older intel is left alone.
+	env, err := SectorEnvironment(ctx, tx, sectorID)
+	if err != nil || env == EnvNebula {
+		return err
+	}
+
 	// Capture a port snapshot only if the sector has a port.
 	var port portForUpdate
-	err := tx.QueryRow(ctx, `
+	err = tx.QueryRow(ctx, `
 		SELECT
 			ore_mode, ore_qty, ore_base_qty, ore_base_price,
 			organics_mode, organics_qty, organics_base_qty, organics_base_price,
@@ -106,22 +115,34 @@ func CaptureScanIntel(ctx context.Context, tx pgx.Tx, playerID string, sectorID
 	return err
 }
 
+// LoadPortIntel returns the player's port intel, newest first. With a corpID it also draws on corp
+// mates' scans (all of them when the corp pools intel, otherwise only those pushed with CORP SHARE
+// INTEL) and keeps the freshest scan per sector.
 func LoadPortIntel(ctx context.Context, q interface {
 	Query(context.Context, string, ...any) (pgx.Rows, error)
-}, playerID string) ([]PortIntel, error) {
+}, playerID, corpID string) ([]PortIntel, error) {
 	rows, err := q.Query(ctx, `
-		SELECT
-			psi.sector_id,
-			s.name,
-			psi.scanned_at,
-			psi.ore_mode, psi.ore_qty, psi.ore_base_qty, psi.ore_price,
-			psi.organics_mode, psi.organics_qty, psi.organics_base_qty, psi.organics_price,
-			psi.equipment_mode, psi.equipment_qty, psi.equipment_base_qty, psi.equipment_price
-		FROM player_sector_intel psi
-		JOIN sectors s ON s.id = psi.sector_id
-		WHERE psi.player_id = $1
-		ORDER BY psi.scanned_at DESC
-	`, playerID)
+		SELECT * FROM (
+			SELECT DISTINCT ON (psi.sector_id)
+				psi.sector_id,
+				s.name,
+				psi.scanned_at,
+				psi.ore_mode, psi.ore_qty, psi.ore_base_qty, psi.ore_price,
+				psi.organics_mode, psi.organics_qty, psi.organics_base_qty, psi.organics_price,
+				psi.equipment_mode, psi.equipment_qty, psi.equipment_base_qty, psi.equipment_price,
+				u.username
+			FROM player_sector_intel psi
+			JOIN sectors s ON s.id = psi.sector_id
+			JOIN players pl ON pl.id = psi.player_id
+			JOIN users u ON u.id = pl.user_id
+			LEFT JOIN corp_members cm ON cm.player_id = psi.player_id
+			LEFT JOIN corporations c ON c.id = cm.corp_id
+			WHERE psi.player_id = $1
+				OR ($2 <> '' AND cm.corp_id = $2 AND (c.share_intel OR psi.shared))
+			ORDER BY psi.sector_id, psi.scanned_at DESC, (psi.player_id = $1) DESC
+		) intel
+		ORDER BY scanned_at DESC
+	`, playerID, corpID)
 	if err != nil {
 		return nil, err
 	}
@@ -137,6 +158,7 @@ func LoadPortIntel(ctx context.Context, q interface {
 			&pi.OreMode, &pi.OreQty, &pi.OreBaseQty, &pi.OrePrice,
 			&pi.OrganicsMode, &pi.OrganicsQty, &pi.OrganicsBaseQty, &pi.OrganicsPrice,
 			&pi.EquipmentMode, &pi.EquipmentQty, &pi.EquipmentBaseQty, &pi.EquipmentPrice,
+			&pi.ScannedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -144,3 +166,11 @@ func LoadPortIntel(ctx context.Context, q interface {
 	}
 	return out, rows.Err()
 }
+
+// intelSource attributes a scan made by someone other than the viewer, e.g. ", by alice".
+func intelSource(viewer, scannedBy string) string {
+	if scannedBy == "" || scannedBy == viewer {
+		return ""
+	}
+	return ", by " + scannedBy
+}
@@ -15,7 +15,7 @@ func executeMarketCommand(ctx context.Context, tx pgx.Tx, p Player, cmd CommandR
 		filter = ""
 	}
 
-	intel, err := LoadPortIntel(ctx, tx, p.ID)
+	intel, err := LoadPortIntel(ctx, tx, p.ID, p.CorpID)
 	if err != nil {
 		return "", err
 	}
@@ -32,6 +32,7 @@ func executeMarketCommand(ctx context.Context, tx pgx.Tx, p Player, cmd CommandR
 		Qty        int
 		BaseQty    int
 		ScannedAt  time.Time
+		ScannedBy  string
 	}
 
 	inf := int(^uint(0) >> 1)
@@ -49,33 +50,37 @@ func executeMarketCommand(ctx context.Context, tx pgx.Tx, p Player, cmd CommandR
 	buyCount := map[string]int{"ORE": 0, "ORGANICS": 0, "EQUIPMENT": 0}
 	sellCount := map[string]int{"ORE": 0, "ORGANICS": 0, "EQUIPMENT": 0}
 
-	consider := func(comm string, mode string, price int, qty int, baseQty int, scannedAt time.Time, sectorID int, sectorName string) {
+	consider := func(comm string, mode string, price int, qty int, baseQty int, pi PortIntel) {
 		mode = strings.ToUpper(mode)
 		if mode == "SELL" {
 			buyCount[comm]++
 			q := bestBuy[comm]
 			if price > 0 && price < q.Price {
-				bestBuy[comm] = quote{SectorID: sectorID, SectorName: sectorName, Price: price, Qty: qty, BaseQty: baseQty, ScannedAt: scannedAt}
+				bestBuy[comm] = quote{SectorID: pi.SectorID, SectorName: pi.SectorName, Price: price, Qty: qty, BaseQty: baseQty, ScannedAt: pi.ScannedAt, ScannedBy: pi.ScannedBy}
 			}
 		}
 		if mode == "BUY" {
 			sellCount[comm]++
 			q := bestSell[comm]
 			if price > q.Price {
-				bestSell[comm] = quote{SectorID: sectorID, SectorName: sectorName, Price: price, Qty: qty, BaseQty: baseQty, ScannedAt: scannedAt}
+				bestSell[comm] = quote{SectorID: pi.SectorID, SectorName: pi.SectorName, Price: price, Qty: qty, BaseQty: baseQty, ScannedAt: pi.ScannedAt, ScannedBy: pi.ScannedBy}
 			}
 		}
 	}
 
 	for _, pi := range intel {
-		consider("ORE", pi.OreMode, pi.OrePrice, pi.OreQty, pi.OreBaseQty, pi.ScannedAt, pi.SectorID, pi.SectorName)
-		consider("ORGANICS", pi.OrganicsMode, pi.OrganicsPrice, pi.OrganicsQty, pi.OrganicsBaseQty, pi.ScannedAt, pi.SectorID, pi.SectorName)
-		consider("EQUIPMENT", pi.EquipmentMode, pi.EquipmentPrice, pi.EquipmentQty, pi.EquipmentBaseQty, pi.ScannedAt, pi.SectorID, pi.SectorName)
+		consider("ORE", pi.OreMode, pi.OrePrice, pi.OreQty, pi.OreBaseQty, pi)
+		consider("ORGANICS", pi.OrganicsMode, pi.OrganicsPrice, pi.OrganicsQty, pi.OrganicsBaseQty, pi)
+		consider("EQUIPMENT", pi.EquipmentMode, pi.EquipmentPrice, pi.EquipmentQty, pi.EquipmentBaseQty, pi)
 	}
 
 	lines := make([]string, 0, 12)
 	lines = append(lines, fmt.Sprintf("Market intel: %d scanned ports.", len(intel)))
-	lines = append(lines, "Note: MARKET uses your scanned intel (SCAN) only; remote prices may be stale.")
+	if p.CorpID != "" {
+		lines = append(lines, "Note: MARKET uses scanned intel (SCAN) only, yours and any your corp shares; remote prices may be stale.")
+	} else {
+		lines = append(lines, "Note: MARKET uses your scanned intel (SCAN) only; remote prices may be stale.")
+	}
 
 	appendCommodity := func(comm string) {
 		b := bestBuy[comm]
@@ -85,8 +90,8 @@ func executeMarketCommand(ctx context.Context, tx pgx.Tx, p Player, cmd CommandR
 			return
 		}
 		spread := s.Price - b.Price
-		buyStr := fmt.Sprintf("BUY @ Sector %d (%s) %d cr (qty %d/%d, age %s)", b.SectorID, b.SectorName, b.Price, b.Qty, b.BaseQty, formatAgeShort(now, b.ScannedAt))
-		sellStr := fmt.Sprintf("SELL @ Sector %d (%s) %d cr (qty %d/%d, age %s)", s.SectorID, s.SectorName, s.Price, s.Qty, s.BaseQty, formatAgeShort(now, s.ScannedAt))
+		buyStr := fmt.Sprintf("BUY @ Sector %d (%s) %d cr (qty %d/%d, age %s%s)", b.SectorID, b.SectorName, b.Price, b.Qty, b.BaseQty, formatAgeShort(now, b.ScannedAt), intelSource(p.Username, b.ScannedBy))
+		sellStr := fmt.Sprintf("SELL @ Sector %d (%s) %d cr (qty %d/%d, age %s%s)", s.SectorID, s.SectorName, s.Price, s.Qty, s.BaseQty, formatAgeShort(now, s.ScannedAt), intelSource(p.Username, s.ScannedBy))
 		lines = append(lines, fmt.Sprintf("%s: %s | %s | spread %d/unit", comm, buyStr, sellStr, spread))
 	}
 
@@ -14,6 +14,8 @@ const (
 	MessageKindUser       = "USER"
 	MessageKindBugReport  = "BUG_REPORT"
 	MessageKindSpamReport = "SPAM_REPORT"
+	MessageKindCorpInvite = "CORP_INVITE" // corp invites, applications and their answers
+	MessageKindCorpBank   = "CORP_BANK"   // large corp bank movements and withdrawals awaiting approval
 )
 
 type DirectMessageAttachmentView struct {
@@ -27,7 +27,7 @@ func executeMineCommand(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRe
 	case "SWEEP":
 		return mineSweep(ctx, tx, p)
 	case "INFO":
-		msg := "MINE DEPLOY {qty} (uses equipment cargo) | MINE SWEEP"
+		msg := "MINE DEPLOY {qty} (uses stardock mines, then equipment cargo) | MINE SWEEP"
 		return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "SYSTEM", msg: msg}}}, nil
 	default:
 		return phase2Result{OK: false, Message: "Unknown MINE subcommand.", ErrorCode: "UNKNOWN_SUBCOMMAND"}, nil
@@ -49,11 +49,18 @@ func mineDeploy(ctx context.Context, tx pgx.Tx, p *Player, qty int) (phase2Resul
 		return phase2Result{OK: false, Message: "The Galactic Protectorate forbids mine deployment in Protectorate sectors.", ErrorCode: "PROTECTORATE_PEACE"}, nil
 	}
 
-	if p.CargoEquipment < qty {
-		return phase2Result{OK: false, Message: "Not enough equipment cargo to deploy mines.", ErrorCode: "INSUFFICIENT_EQUIPMENT"}, nil
+	if p.CorpID != "" && !p.corpAllows(CorpPermMines) {
+		return phase2Result{OK: false, Message: "Your corp role does not allow deploying mines.", ErrorCode: "FORBIDDEN"}, nil
 	}
 
-	p.CargoEquipment -= qty
+	// Mines bought at a stardock are used first, then equipment cargo.
+	if p.ShipMines+p.CargoEquipment < qty {
+		return phase2Result{OK: false, Message: "Not enough mines or equipment cargo to deploy mines.", ErrorCode: "INSUFFICIENT_EQUIPMENT"}, nil
+	}
+
+	fromRacks := min(qty, p.ShipMines)
+	p.ShipMines -= fromRacks
+	p.CargoEquipment -= qty - fromRacks
 
 	var ownerCorp any = nil
 	if p.CorpID != "" {
@@ -86,7 +93,7 @@ func mineSweep(ctx context.Context, tx pgx.Tx, p *Player) (phase2Result, error)
 		FROM mines
 		WHERE sector_id=$1
 			AND owner_player_id <> $2
-			AND (owner_corp_id IS NULL OR owner_corp_id <> $3 OR $3 = '')
+			AND `+hostileMineOwnerSQL+`
 		ORDER BY qty DESC
 		FOR UPDATE
 	`, p.SectorID, p.ID, corpID)
@@ -149,7 +156,7 @@ func applyMineStrike(ctx context.Context, tx pgx.Tx, p *Player) (respMsg string,
 		FROM mines
 		WHERE sector_id=$1
 			AND owner_player_id <> $2
-			AND (owner_corp_id IS NULL OR owner_corp_id <> $3 OR $3 = '')
+			AND `+hostileMineOwnerSQL+`
 		ORDER BY created_at ASC
 		FOR UPDATE
 	`, p.SectorID, p.ID, corpID)
@@ -200,7 +207,7 @@ func applyMineStrike(ctx context.Context, tx pgx.Tx, p *Player) (respMsg string,
 		}
 	}
 
-	damage := mineDamageCredits(triggered)
+	damage := p.Rules.mineDamageCredits(triggered)
 	if damage > p.Credits {
 		damage = p.Credits
 	}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Sectors are grouped into named regions of this many consecutive IDs.
+const universeRegionSize = 25
+
+var (
+	nameStarts  = []string{"Ka", "Vel", "Or", "Thra", "Ys", "Dor", "Mir", "Sel", "Ar", "Bel", "Cor", "Eph", "Hal", "Ix", "Lun", "Nyr", "Pra", "Quel", "Rho", "Tal", "Ul", "Vex", "Zan", "Aur"}
+	nameMiddles = []string{"a", "e", "i", "o", "u", "ae", "ar", "en", "is", "or", "yl", "an"}
+	nameEnds    = []string{"th", "n", "ra", "x", "s", "dor", "mar", "lis", "via", "tis", "rion", "nar", "del", "vos", "ka", "phe"}
+
+	sectorSuffixes = []string{"Reach", "Drift", "Verge", "Crossing", "Deep", "Passage", "Hollow", "Span", "Rift", "Shoal", "Gate", "Marches"}
+	regionSuffixes = []string{"Expanse", "Cluster", "Frontier", "Nebula", "Dominion", "Belt", "Arm", "Wilds", "Veil", "Corridor"}
+	portSuffixes   = []string{"Station", "Exchange", "Depot", "Outpost", "Terminal", "Dock", "Bazaar", "Hub"}
+	planetNumerals = []string{"", " I", " II", " III", " IV", " V", " VI", " VII"}
+
+	descSights = []string{
+		"A dim red dwarf casts long shadows across the lanes",
+		"Twin blue giants wash the sector in harsh light",
+		"Ice and rock tumble through a sparse asteroid field",
+		"Faint ribbons of ionized gas glow at the edge of sensors",
+		"The wreckage of an old freighter convoy drifts here",
+		"A pulsar ticks steadily somewhere beyond the warp points",
+		"Dust lanes hide most of the background stars",
+		"Comets trail long tails through an empty system",
+		"A white dwarf sits alone among the cinders of its planets",
+		"Navigation buoys from a forgotten survey still blink here",
+	}
+	descNotes = []string{
+		"Traders pass through quickly.",
+		"Scanners report heavy background noise.",
+		"Old charts mark it as unsurveyed.",
+		"Pilots call it a quiet run.",
+		"Smugglers are said to favor it.",
+		"Few ships linger here.",
+	}
+)
+
+// nameGenerator produces seeded, unique names for sectors, regions, ports and planets.
+type nameGenerator struct {
+	rng  *rand.Rand
+	used map[string]bool
+}
+
+func newNameGenerator(seed int64) *nameGenerator {
+	return &nameGenerator{rng: rand.New(rand.NewSource(seed)), used: map[string]bool{}}
+}
+
+// reserve marks names already in use (e.g. existing sectors) so they are not generated again.
+func (g *nameGenerator) reserve(names ...string) {
+	for _, n := range names {
+		g.used[n] = true
+	}
+}
+
+func (g *nameGenerator) pick(list []string) string {
+	return list[g.rng.Intn(len(list))]
+}
+
+// word builds a pronounceable proper noun from two or three syllables.
+func (g *nameGenerator) word() string {
+	w := g.pick(nameStarts)
+	if g.rng.Intn(3) == 0 {
+		w += g.pick(nameMiddles)
+	}
+	return w + g.pick(nameEnds)
+}
+
+// unique retries build until it yields an unused name, then falls back to a numbered variant.
+func (g *nameGenerator) unique(build func() string) string {
+	var name string
+	for i := 0; i < 20; i++ {
+		name = build()
+		if !g.used[name] {
+			g.used[name] = true
+			return name
+		}
+	}
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s %d", name, n)
+		if !g.used[candidate] {
+			g.used[candidate] = true
+			return candidate
+		}
+	}
+}
+
+func (g *nameGenerator) SectorName() string {
+	return g.unique(func() string {
+		if g.rng.Intn(3) == 0 {
+			return g.word() + "'s " + g.pick(sectorSuffixes)
+		}
+		return g.word() + " " + g.pick(sectorSuffixes)
+	})
+}
+
+func (g *nameGenerator) RegionName() string {
+	return g.unique(func() string { return "The " + g.word() + " " + g.pick(regionSuffixes) })
+}
+
+func (g *nameGenerator) PortName() string {
+	return g.unique(func() string {
+		if g.rng.Intn(4) == 0 {
+			return "Port " + g.word()
+		}
+		return g.word() + " " + g.pick(portSuffixes)
+	})
+}
+
+func (g *nameGenerator) PlanetName() string {
+	return g.unique(func() string { return g.word() + g.pick(planetNumerals) })
+}
+
+// SectorDescription returns flavor text for roughly half of all sectors, "" otherwise.
+func (g *nameGenerator) SectorDescription() string {
+	if g.rng.Intn(2) == 0 {
+		return ""
+	}
+	return strings.Join([]string{g.pick(descSights) + ".", g.pick(descNotes)}, " ")
+}
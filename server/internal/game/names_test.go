@@ -0,0 +1,27 @@
+package game
+
+import "testing"
+
+func TestNameGeneratorIsSeededAndUnique(t *testing.T) {
+	a, b := newNameGenerator(42), newNameGenerator(42)
+	seen := map[string]bool{}
+	for i := 0; i < 500; i++ {
+		na, nb := a.SectorName(), b.SectorName()
+		if na != nb {
+			t.Fatalf("expected the same names for the same seed, got %q and %q", na, nb)
+		}
+		if seen[na] {
+			t.Fatalf("duplicate sector name %q", na)
+		}
+		seen[na] = true
+	}
+}
+
+func TestNameGeneratorRespectsReservedNames(t *testing.T) {
+	first := newNameGenerator(7).PlanetName()
+	g := newNameGenerator(7)
+	g.reserve(first)
+	if got := g.PlanetName(); got == first {
+		t.Fatalf("expected reserved name %q to be skipped", first)
+	}
+}
@@ -0,0 +1,176 @@
+package game
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Reference unit prices used to value goods away from a live port (roughly the middle of the
+// generated base price ranges).
+const (
+	referencePriceOre       = 11
+	referencePriceOrganics  = 22
+	referencePriceEquipment = 60
+)
+
+func referencePrice(commodity string) int64 {
+	switch commodity {
+	case "ORE":
+		return referencePriceOre
+	case "ORGANICS":
+		return referencePriceOrganics
+	case "EQUIPMENT":
+		return referencePriceEquipment
+	default:
+		return 0
+	}
+}
+
+// shipResaleValue is what the shipyard would pay for the player's current hull.
+func shipResaleValue(p Player) int64 {
+	cur, ok := findShipDef(p.ShipType)
+	if !ok {
+		return 0
+	}
+	return p.Rules.shipPrice(cur) * 70 / 100
+}
+
+func cargoValue(p Player) int64 {
+	return int64(p.CargoOre)*referencePriceOre +
+		int64(p.CargoOrganics)*referencePriceOrganics +
+		int64(p.CargoEquipment)*referencePriceEquipment
+}
+
+// estimateNetWorth values a player's liquid position for lending: credits, bank balance net of
+// loans, ship resale value and cargo at reference prices. Rankings use computeNetWorth.
+func estimateNetWorth(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, p Player) (int64, error) {
+	acct, err := loadBankAccount(ctx, q, p.ID)
+	if err != nil {
+		return 0, err
+	}
+	return netWorthOf(p, acct), nil
+}
+
+func netWorthOf(p Player, acct bankAccount) int64 {
+	return p.Credits + acct.Deposit - acct.Loan + shipResaleValue(p) + cargoValue(p)
+}
+
+// marketPrices are average unit prices across all ports, used to value cargo and planet storage.
+type marketPrices struct {
+	Ore       int64
+	Organics  int64
+	Equipment int64
+}
+
+func referenceMarketPrices() marketPrices {
+	return marketPrices{Ore: referencePriceOre, Organics: referencePriceOrganics, Equipment: referencePriceEquipment}
+}
+
+func (m marketPrices) value(ore, organics, equipment int) int64 {
+	return int64(ore)*m.Ore + int64(organics)*m.Organics + int64(equipment)*m.Equipment
+}
+
+// loadMarketPrices averages the current scarcity price of each commodity over all ports.
+// Commodities no port trades fall back to the reference prices.
+func loadMarketPrices(ctx context.Context, q interface {
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+}) (marketPrices, error) {
+	rows, err := q.Query(ctx, `
+		SELECT ore_base_price, ore_base_qty, ore_qty,
+			organics_base_price, organics_base_qty, organics_qty,
+			equipment_base_price, equipment_base_qty, equipment_qty
+		FROM ports
+	`)
+	if err != nil {
+		return marketPrices{}, err
+	}
+	defer rows.Close()
+	var sums [3]int64
+	var n int64
+	for rows.Next() {
+		var v [9]int
+		if err := rows.Scan(&v[0], &v[1], &v[2], &v[3], &v[4], &v[5], &v[6], &v[7], &v[8]); err != nil {
+			return marketPrices{}, err
+		}
+		for i := 0; i < 3; i++ {
+			sums[i] += int64(PricePerUnit(v[i*3], v[i*3+1], v[i*3+2]))
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return marketPrices{}, err
+	}
+	if n == 0 {
+		return referenceMarketPrices(), nil
+	}
+	return marketPrices{Ore: sums[0] / n, Organics: sums[1] / n, Equipment: sums[2] / n}, nil
+}
+
+// shipInvestment is what the player's hull, upgrades and racked mines cost at the season's
+// shipyard and stardock prices.
+func shipInvestment(p Player) int64 {
+	total := int64(0)
+	if d, ok := findShipDef(p.ShipType); ok {
+		total += p.Rules.shipPrice(d)
+	}
+	for i := 0; i < p.ShipCargoUpgrades; i++ {
+		total += cargoUpgradeCost(i)
+	}
+	for i := 0; i < p.ShipTurnUpgrades; i++ {
+		total += turnsUpgradeCost(i)
+	}
+	if p.ShipScanner {
+		total += scannerPrice
+	}
+	total += int64(p.ShipMines) * stardockMinePrice
+	return total
+}
+
+// citadelInvestment is what upgrading a citadel to level costs in total.
+func citadelInvestment(rules SeasonRules, level int) int64 {
+	total := int64(0)
+	for l := 1; l <= level; l++ {
+		total += rules.citadelUpgradeCost(l)
+	}
+	return total
+}
+
+// planetHolding is the part of a planet that counts towards its owner's net worth.
+type planetHolding struct {
+	Ore, Organics, Equipment int
+	CitadelLevel             int
+}
+
+// NetWorth is a player's full position, as ranked by RANKINGS NETWORTH and season victory.
+type NetWorth struct {
+	Credits   int64 `json:"credits"`
+	Bank      int64 `json:"bank"`
+	Ship      int64 `json:"ship"`
+	Cargo     int64 `json:"cargo"`
+	Planets   int64 `json:"planets"`
+	CorpShare int64 `json:"corp_share"`
+}
+
+func (n NetWorth) Total() int64 {
+	return n.Credits + n.Bank + n.Ship + n.Cargo + n.Planets + n.CorpShare
+}
+
+// computeNetWorth values credits, bank deposit net of loans, ship and upgrades at shipyard
+// prices, cargo and planet storage at average market prices, citadel upgrades, and an equal
+// share of the player's corp bank.
+func computeNetWorth(p Player, acct bankAccount, planets []planetHolding, corpShare int64, prices marketPrices) NetWorth {
+	n := NetWorth{
+		Credits:   p.Credits,
+		Bank:      acct.Deposit - acct.Loan,
+		Ship:      shipInvestment(p),
+		Cargo:     prices.value(p.CargoOre, p.CargoOrganics, p.CargoEquipment),
+		CorpShare: corpShare,
+	}
+	for _, pl := range planets {
+		n.Planets += prices.value(pl.Ore, pl.Organics, pl.Equipment) + citadelInvestment(p.Rules, pl.CitadelLevel)
+	}
+	return n
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Galaxy news kinds.
+const (
+	NewsKindWar    = "WAR"
+	NewsKindTreaty = "TREATY"
+	NewsKindSeason = "SEASON"
+)
+
+const newsFeedLimit = 20
+
+// NewsItem is one galaxy news headline.
+type NewsItem struct {
+	ID        int64     `json:"id"`
+	Kind      string    `json:"kind"`
+	Headline  string    `json:"headline"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func postGalaxyNews(ctx context.Context, tx pgx.Tx, kind, headline string) error {
+	_, err := tx.Exec(ctx, "INSERT INTO galaxy_news(kind, headline) VALUES ($1,$2)", kind, headline)
+	return err
+}
+
+// LoadGalaxyNews returns the most recent headlines, newest first.
+func LoadGalaxyNews(ctx context.Context, q interface {
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+}, limit int) ([]NewsItem, error) {
+	if limit < 1 || limit > 100 {
+		limit = newsFeedLimit
+	}
+	rows, err := q.Query(ctx, "SELECT id, kind, headline, created_at FROM galaxy_news ORDER BY created_at DESC, id DESC LIMIT $1", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []NewsItem{}
+	for rows.Next() {
+		var n NewsItem
+		if err := rows.Scan(&n.ID, &n.Kind, &n.Headline, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+func executeNewsCommand(ctx context.Context, tx pgx.Tx) (string, error) {
+	items, err := LoadGalaxyNews(ctx, tx, newsFeedLimit)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "No galaxy news yet.", nil
+	}
+	lines := []string{"Galaxy news:"}
+	now := time.Now()
+	for _, n := range items {
+		lines = append(lines, fmt.Sprintf("- [%s ago] %s", formatAgeShort(now, n.CreatedAt), n.Headline))
+	}
+	return strings.Join(lines, "\n"), nil
+}
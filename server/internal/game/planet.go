@@ -20,6 +20,9 @@ type planetForUpdate struct {
 	ID                  int64
 	SectorID            int
 	Name                string
+	Class               string
+	Shortage            string
+	Population          int
 	OwnerPlayerID       pgtype.Text
 	OwnerCorpID         pgtype.Text
 	ProductionOre       int
@@ -65,12 +68,12 @@ func planetInfo(ctx context.Context, tx pgx.Tx, p *Player) (phase2Result, error)
 
 	owner := "Unclaimed"
 	if pl.OwnerCorpID.Valid || pl.OwnerPlayerID.Valid {
-		if canAccessPlanet(*p, pl) {
-			if pl.OwnerCorpID.Valid && p.CorpID != "" && pl.OwnerCorpID.String == p.CorpID {
-				owner = fmt.Sprintf("Your corp (%s)", p.CorpName)
-			} else {
-				owner = "You"
-			}
+		if pl.OwnerCorpID.Valid && p.CorpID != "" && pl.OwnerCorpID.String == p.CorpID {
+			owner = fmt.Sprintf("Your corp (%s)", p.CorpName)
+		} else if pl.OwnerCorpID.Valid && p.CorpPlanetAllies[pl.OwnerCorpID.String] {
+			owner = "An allied corporation"
+		} else if canAccessPlanet(*p, pl) {
+			owner = "You"
 		} else if pl.OwnerCorpID.Valid {
 			owner = "Another corporation"
 		} else {
@@ -78,11 +81,32 @@ func planetInfo(ctx context.Context, tx pgx.Tx, p *Player) (phase2Result, error)
 		}
 	}
 
+	recipe := p.Rules.planetRecipe(pl.Class)
+	popCap := recipe.maxPopulation(pl.CitadelLevel)
+	workers := min(pl.Population, popCap)
+	status := "Producing"
+	if pl.Population <= 0 {
+		status = "Idle (no colonists; unload COLONISTS to start production)"
+	}
+	switch pl.Shortage {
+	case PlanetShortageOrganics:
+		status = "STALLED (out of organics)"
+	case PlanetShortageOre:
+		status = "Short of ore (equipment output reduced)"
+	}
+
 	msg := strings.Join([]string{
 		fmt.Sprintf("Planet: %s", pl.Name),
+		fmt.Sprintf("Class: %s (%s)", recipe.Class, recipe.Name),
 		fmt.Sprintf("Owner: %s", owner),
 		fmt.Sprintf("Citadel: %d", pl.CitadelLevel),
-		fmt.Sprintf("Production/tick: Ore %d, Org %d, Eq %d", pl.ProductionOre, pl.ProductionOrganics, pl.ProductionEquipment),
+		fmt.Sprintf("Population: %d/%d (full output at %d)", pl.Population, popCap, planetWorkforce),
+		fmt.Sprintf("Production/tick: Ore %d, Org %d, Eq %d",
+			recipe.output(pl.ProductionOre, recipe.OreYield, workers),
+			recipe.output(pl.ProductionOrganics, recipe.OrganicsYield, workers),
+			recipe.output(pl.ProductionEquipment, recipe.EquipmentYield, workers)),
+		fmt.Sprintf("Upkeep/tick: %d organics; equipment uses %d ore per unit", recipe.upkeep(workers), recipe.OrePerEquipment),
+		fmt.Sprintf("Status: %s", status),
 		fmt.Sprintf("Storage: Ore %d/%d, Org %d/%d, Eq %d/%d", pl.StorageOre, pl.StorageMax, pl.StorageOrganics, pl.StorageMax, pl.StorageEquipment, pl.StorageMax),
 	}, "\n")
 
@@ -90,6 +114,7 @@ func planetInfo(ctx context.Context, tx pgx.Tx, p *Player) (phase2Result, error)
 }
 
 func planetColonize(ctx context.Context, tx pgx.Tx, p *Player, name string) (phase2Result, error) {
+	cost := p.Rules.colonizeCost()
 	name = strings.TrimSpace(name)
 	if name == "" {
 		name = fmt.Sprintf("Planet %d", p.SectorID)
@@ -110,17 +135,17 @@ func planetColonize(ctx context.Context, tx pgx.Tx, p *Player, name string) (pha
 	if exists {
 		// already owned?
 		if pl.OwnerCorpID.Valid || pl.OwnerPlayerID.Valid {
-			if canAccessPlanet(*p, pl) {
+			if ownsPlanet(*p, pl) {
 				return phase2Result{OK: false, Message: "You already control this planet.", ErrorCode: "ALREADY_OWNED"}, nil
 			}
 			return phase2Result{OK: false, Message: "This planet is already controlled.", ErrorCode: "ALREADY_OWNED"}, nil
 		}
 
-		if p.Credits < planetColonizeCostCredits {
-			return phase2Result{OK: false, Message: fmt.Sprintf("Colonization requires %d credits.", planetColonizeCostCredits), ErrorCode: "INSUFFICIENT_CREDITS"}, nil
+		if p.Credits < cost {
+			return phase2Result{OK: false, Message: fmt.Sprintf("Colonization requires %d credits.", cost), ErrorCode: "INSUFFICIENT_CREDITS"}, nil
 		}
 
-		p.Credits -= planetColonizeCostCredits
+		p.Credits -= cost
 		pl.Name = name
 		pl.OwnerPlayerID = pgtype.Text{String: p.ID, Valid: true}
 		if p.CorpID != "" {
@@ -131,15 +156,15 @@ func planetColonize(ctx context.Context, tx pgx.Tx, p *Player, name string) (pha
 			return phase2Result{}, err
 		}
 
-		msg := fmt.Sprintf("Colonized existing planet '%s' for %d credits.", pl.Name, planetColonizeCostCredits)
+		msg := fmt.Sprintf("Colonized existing planet '%s' for %d credits.", pl.Name, cost)
 		return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
 	}
 
-	if p.Credits < planetColonizeCostCredits {
-		return phase2Result{OK: false, Message: fmt.Sprintf("Colonization requires %d credits.", planetColonizeCostCredits), ErrorCode: "INSUFFICIENT_CREDITS"}, nil
+	if p.Credits < cost {
+		return phase2Result{OK: false, Message: fmt.Sprintf("Colonization requires %d credits.", cost), ErrorCode: "INSUFFICIENT_CREDITS"}, nil
 	}
 
-	p.Credits -= planetColonizeCostCredits
+	p.Credits -= cost
 
 	ownerPlayerID := p.ID
 	var ownerCorpID any = nil
@@ -150,28 +175,35 @@ func planetColonize(ctx context.Context, tx pgx.Tx, p *Player, name string) (pha
 	// Default production is modest; the universe generator may create stronger unclaimed planets.
 	prodOre, prodOrg, prodEq := 10, 6, 3
 	storageMax := 2000
+	class := planetClassForSector(p.SectorID)
 
 	var planetID int64
 	err = tx.QueryRow(ctx, `
 		INSERT INTO planets(
-			sector_id, name,
+			sector_id, name, planet_class, population,
 			owner_player_id, owner_corp_id,
 			production_ore, production_organics, production_equipment,
 			storage_max
 		)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
 		RETURNING id
-	`, p.SectorID, name, ownerPlayerID, ownerCorpID, prodOre, prodOrg, prodEq, storageMax).Scan(&planetID)
+	`, p.SectorID, name, class, planetFoundingPopulation, ownerPlayerID, ownerCorpID, prodOre, prodOrg, prodEq, storageMax).Scan(&planetID)
 	if err != nil {
 		return phase2Result{}, err
 	}
 
-	msg := fmt.Sprintf("Established new planet '%s' for %d credits.", name, planetColonizeCostCredits)
+	msg := fmt.Sprintf("Established new %s planet '%s' for %d credits.", findPlanetRecipe(class).Name, name, cost)
 	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
 }
 
 func planetTransfer(ctx context.Context, tx pgx.Tx, p *Player, mode, commodity string, qty int) (phase2Result, error) {
 	commodity = strings.ToUpper(strings.TrimSpace(commodity))
+	if commodity == "COLONISTS" {
+		if mode != "UNLOAD" {
+			return phase2Result{OK: false, Message: "Colonists cannot be loaded back off a planet.", ErrorCode: "INVALID_COMMODITY"}, nil
+		}
+		return planetUnloadColonists(ctx, tx, p, qty)
+	}
 	if commodity != "ORE" && commodity != "ORGANICS" && commodity != "EQUIPMENT" {
 		return phase2Result{OK: false, Message: "Commodity must be ORE, ORGANICS, or EQUIPMENT.", ErrorCode: "INVALID_COMMODITY"}, nil
 	}
@@ -190,8 +222,7 @@ func planetTransfer(ctx context.Context, tx pgx.Tx, p *Player, mode, commodity s
 		return phase2Result{OK: false, Message: "You do not have access to this planet.", ErrorCode: "NO_ACCESS"}, nil
 	}
 
-	totalCargo := p.CargoOre + p.CargoOrganics + p.CargoEquipment
-	freeCargo := p.CargoMax - totalCargo
+	freeCargo := p.CargoMax - totalCargo(p)
 	if freeCargo < 0 {
 		freeCargo = 0
 	}
@@ -268,6 +299,40 @@ func planetTransfer(ctx context.Context, tx pgx.Tx, p *Player, mode, commodity s
 	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
 }
 
+// planetUnloadColonists settles colonists from the ship's hold on the planet, up to its housing cap.
+func planetUnloadColonists(ctx context.Context, tx pgx.Tx, p *Player, qty int) (phase2Result, error) {
+	if qty < 1 || qty > 1000000 {
+		return phase2Result{OK: false, Message: "Quantity must be at least 1.", ErrorCode: "INVALID_QTY"}, nil
+	}
+	pl, exists, err := loadPlanet(ctx, tx, p.SectorID, true)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !exists {
+		return phase2Result{OK: false, Message: "No planet in this sector.", ErrorCode: "NO_PLANET"}, nil
+	}
+	if !canAccessPlanet(*p, pl) {
+		return phase2Result{OK: false, Message: "You do not have access to this planet.", ErrorCode: "NO_ACCESS"}, nil
+	}
+	if p.CargoColonists < qty {
+		return phase2Result{OK: false, Message: "You do not have that many colonists aboard.", ErrorCode: "INSUFFICIENT_CARGO"}, nil
+	}
+	popCap := p.Rules.planetRecipe(pl.Class).maxPopulation(pl.CitadelLevel)
+	if pl.Population+qty > popCap {
+		msg := fmt.Sprintf("%s can only house %d more colonists (cap %d). Upgrade the citadel to raise it.", pl.Name, max(0, popCap-pl.Population), popCap)
+		return phase2Result{OK: false, Message: msg, ErrorCode: "POPULATION_FULL"}, nil
+	}
+
+	p.CargoColonists -= qty
+	pl.Population += qty
+	if _, err := tx.Exec(ctx, "UPDATE planets SET population=$2 WHERE id=$1", pl.ID, pl.Population); err != nil {
+		return phase2Result{}, err
+	}
+
+	msg := fmt.Sprintf("Settled %d colonists on %s. Population: %d/%d.", qty, pl.Name, pl.Population, popCap)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
 func planetUpgradeCitadel(ctx context.Context, tx pgx.Tx, p *Player) (phase2Result, error) {
 	pl, exists, err := loadPlanet(ctx, tx, p.SectorID, true)
 	if err != nil {
@@ -284,7 +349,7 @@ func planetUpgradeCitadel(ctx context.Context, tx pgx.Tx, p *Player) (phase2Resu
 	}
 
 	next := pl.CitadelLevel + 1
-	cost := citadelUpgradeCost(next)
+	cost := p.Rules.citadelUpgradeCost(next)
 	if p.Credits < cost {
 		return phase2Result{OK: false, Message: fmt.Sprintf("Citadel upgrade requires %d credits.", cost), ErrorCode: "INSUFFICIENT_CREDITS"}, nil
 	}
@@ -308,6 +373,9 @@ func loadPlanet(ctx context.Context, tx pgx.Tx, sectorID int, forUpdate bool) (p
 			id,
 			sector_id,
 			name,
+			planet_class,
+			shortage,
+			population,
 			owner_player_id,
 			owner_corp_id,
 			production_ore,
@@ -329,6 +397,9 @@ func loadPlanet(ctx context.Context, tx pgx.Tx, sectorID int, forUpdate bool) (p
 		&pl.ID,
 		&pl.SectorID,
 		&pl.Name,
+		&pl.Class,
+		&pl.Shortage,
+		&pl.Population,
 		&pl.OwnerPlayerID,
 		&pl.OwnerCorpID,
 		&pl.ProductionOre,
@@ -388,13 +459,22 @@ func savePlanetOwnershipAndName(ctx context.Context, tx pgx.Tx, pl planetForUpda
 	return err
 }
 
-func canAccessPlanet(p Player, pl planetForUpdate) bool {
+// ownsPlanet reports whether p or p's corporation owns the planet. Alliances do not count; use it
+// where a planet's owner acts (anchoring a gate, colonization), not where allies may help.
+func ownsPlanet(p Player, pl planetForUpdate) bool {
 	if pl.OwnerPlayerID.Valid && pl.OwnerPlayerID.String == p.ID {
 		return true
 	}
-	if pl.OwnerCorpID.Valid && p.CorpID != "" && pl.OwnerCorpID.String == p.CorpID {
+	return pl.OwnerCorpID.Valid && p.CorpID != "" && pl.OwnerCorpID.String == p.CorpID
+}
+
+func canAccessPlanet(p Player, pl planetForUpdate) bool {
+	if pl.OwnerPlayerID.Valid && pl.OwnerPlayerID.String == p.ID {
 		return true
 	}
+	if pl.OwnerCorpID.Valid && p.CorpID != "" && (pl.OwnerCorpID.String == p.CorpID || p.CorpPlanetAllies[pl.OwnerCorpID.String]) {
+		return p.corpAllows(CorpPermPlanets)
+	}
 	return false
 }
 
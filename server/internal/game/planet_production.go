@@ -0,0 +1,290 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	PlanetShortageNone     = ""
+	PlanetShortageOrganics = "ORGANICS"
+	PlanetShortageOre      = "ORE"
+
+	// Colonists needed for a planet to produce at 100% of its base rates.
+	planetWorkforce = 1000
+	// Each citadel level houses this many extra colonists above the class cap.
+	planetCitadelHousing = 500
+	// Colonists who settle a newly founded planet with PLANET COLONIZE.
+	planetFoundingPopulation = 200
+	// Per-tick population change, in percent, when the colony is fed or starving.
+	planetGrowthPct     = 2
+	planetStarvationPct = 5
+)
+
+// planetRecipe describes how a planet class turns its base production into goods each tick.
+// Yields are percentages applied to the planet's production_* columns at a full workforce.
+type planetRecipe struct {
+	Class          string
+	Name           string
+	OreYield       int
+	OrganicsYield  int
+	EquipmentYield int
+	// Organics eaten each tick per planetWorkforce colonists, before anything else is produced.
+	OrganicsUpkeep int
+	// Ore consumed per unit of equipment manufactured.
+	OrePerEquipment int
+	// Colonists the planet can house without a citadel.
+	PopulationCap int
+}
+
+var planetRecipes = []planetRecipe{
+	{Class: "M", Name: "Terran", OreYield: 100, OrganicsYield: 100, EquipmentYield: 100, OrganicsUpkeep: 4, OrePerEquipment: 2, PopulationCap: 2000},
+	{Class: "O", Name: "Oceanic", OreYield: 50, OrganicsYield: 160, EquipmentYield: 75, OrganicsUpkeep: 3, OrePerEquipment: 2, PopulationCap: 1600},
+	{Class: "L", Name: "Mountainous", OreYield: 160, OrganicsYield: 50, EquipmentYield: 100, OrganicsUpkeep: 5, OrePerEquipment: 2, PopulationCap: 1200},
+	{Class: "D", Name: "Forge", OreYield: 100, OrganicsYield: 90, EquipmentYield: 150, OrganicsUpkeep: 2, OrePerEquipment: 1, PopulationCap: 1000},
+}
+
+// PlanetRecipeRules overrides parts of a class recipe for a season (SeasonRules.PlanetRecipes).
+// Omitted fields keep the built-in value; 0 is a valid override.
+type PlanetRecipeRules struct {
+	OreYield        *int `json:"ore_yield,omitempty"`
+	OrganicsYield   *int `json:"organics_yield,omitempty"`
+	EquipmentYield  *int `json:"equipment_yield,omitempty"`
+	OrganicsUpkeep  *int `json:"organics_upkeep,omitempty"`
+	OrePerEquipment *int `json:"ore_per_equipment,omitempty"`
+	PopulationCap   *int `json:"population_cap,omitempty"`
+}
+
+func (o PlanetRecipeRules) validate(class string) error {
+	fields := []struct {
+		name     string
+		val      *int
+		min, max int
+	}{
+		{"ore_yield", o.OreYield, 0, 1000},
+		{"organics_yield", o.OrganicsYield, 0, 1000},
+		{"equipment_yield", o.EquipmentYield, 0, 1000},
+		{"organics_upkeep", o.OrganicsUpkeep, 0, 100},
+		{"ore_per_equipment", o.OrePerEquipment, 0, 100},
+		{"population_cap", o.PopulationCap, planetFoundingPopulation, 100000},
+	}
+	for _, f := range fields {
+		if f.val != nil && (*f.val < f.min || *f.val > f.max) {
+			return fmt.Errorf("planet_recipes: %s %s must be between %d and %d", class, f.name, f.min, f.max)
+		}
+	}
+	return nil
+}
+
+func (o PlanetRecipeRules) apply(r planetRecipe) planetRecipe {
+	set := func(dst *int, v *int) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	set(&r.OreYield, o.OreYield)
+	set(&r.OrganicsYield, o.OrganicsYield)
+	set(&r.EquipmentYield, o.EquipmentYield)
+	set(&r.OrganicsUpkeep, o.OrganicsUpkeep)
+	set(&r.OrePerEquipment, o.OrePerEquipment)
+	set(&r.PopulationCap, o.PopulationCap)
+	return r
+}
+
+// maxPopulation is how many colonists a planet of this class can house at the given citadel level.
+func (r planetRecipe) maxPopulation(citadelLevel int) int {
+	return r.PopulationCap + max(0, citadelLevel)*planetCitadelHousing
+}
+
+// output scales a base production rate by the class yield and the size of the workforce.
+func (r planetRecipe) output(base, yield, population int) int {
+	return base * yield / 100 * population / planetWorkforce
+}
+
+// upkeep is the organics a colony of the given size eats per tick (rounded up).
+func (r planetRecipe) upkeep(population int) int {
+	if population <= 0 {
+		return 0
+	}
+	return (r.OrganicsUpkeep*population + planetWorkforce - 1) / planetWorkforce
+}
+
+func findPlanetRecipe(class string) planetRecipe {
+	r, _ := lookupPlanetRecipe(class)
+	return r
+}
+
+// lookupPlanetRecipe returns the built-in recipe for class; unknown classes get Terran and false.
+func lookupPlanetRecipe(class string) (planetRecipe, bool) {
+	c := strings.ToUpper(strings.TrimSpace(class))
+	for _, r := range planetRecipes {
+		if r.Class == c {
+			return r, true
+		}
+	}
+	return planetRecipes[0], false
+}
+
+// planetClassForSector picks a deterministic class for planets founded by COLONIZE.
+func planetClassForSector(sectorID int) string {
+	if sectorID < 0 {
+		sectorID = -sectorID
+	}
+	return planetRecipes[sectorID%len(planetRecipes)].Class
+}
+
+type planetStock struct {
+	Ore       int
+	Organics  int
+	Equipment int
+}
+
+type productionTick struct {
+	Stock      planetStock
+	Produced   planetStock
+	Consumed   planetStock
+	Shortage   string
+	Population int
+}
+
+// simulateProduction runs one production tick for a planet:
+// farms grow organics, the colony eats its upkeep, mines extract ore, and factories turn ore
+// into equipment. Output scales with population. A colony that cannot be fed stalls, produces
+// nothing but food and shrinks; a fed colony grows toward its housing cap. Factories short of
+// ore produce what they can.
+func simulateProduction(pl planetForUpdate, r planetRecipe) productionTick {
+	out := productionTick{
+		Stock:      planetStock{Ore: pl.StorageOre, Organics: pl.StorageOrganics, Equipment: pl.StorageEquipment},
+		Population: min(max(0, pl.Population), r.maxPopulation(pl.CitadelLevel)),
+	}
+	if out.Population == 0 {
+		return out
+	}
+	workers := out.Population
+	capAdd := func(cur, add int) (int, int) {
+		room := pl.StorageMax - cur
+		if room < 0 {
+			room = 0
+		}
+		if add > room {
+			add = room
+		}
+		if add < 0 {
+			add = 0
+		}
+		return cur + add, add
+	}
+
+	out.Stock.Organics, out.Produced.Organics = capAdd(out.Stock.Organics, r.output(pl.ProductionOrganics, r.OrganicsYield, workers))
+
+	upkeep := r.upkeep(workers)
+	if out.Stock.Organics < upkeep {
+		out.Consumed.Organics = out.Stock.Organics
+		out.Stock.Organics = 0
+		out.Shortage = PlanetShortageOrganics
+		out.Population -= max(1, out.Population*planetStarvationPct/100)
+		return out
+	}
+	out.Stock.Organics -= upkeep
+	out.Consumed.Organics = upkeep
+	out.Population = min(r.maxPopulation(pl.CitadelLevel), out.Population+max(1, out.Population*planetGrowthPct/100))
+
+	out.Stock.Ore, out.Produced.Ore = capAdd(out.Stock.Ore, r.output(pl.ProductionOre, r.OreYield, workers))
+
+	want := r.output(pl.ProductionEquipment, r.EquipmentYield, workers)
+	if room := pl.StorageMax - out.Stock.Equipment; want > room {
+		want = max(0, room)
+	}
+	built := want
+	if r.OrePerEquipment > 0 {
+		if affordable := out.Stock.Ore / r.OrePerEquipment; affordable < built {
+			built = affordable
+			out.Shortage = PlanetShortageOre
+		}
+		out.Stock.Ore -= built * r.OrePerEquipment
+		out.Consumed.Ore = built * r.OrePerEquipment
+	}
+	out.Stock.Equipment += built
+	out.Produced.Equipment = built
+	return out
+}
+
+func shortageMessage(planetName, shortage string) string {
+	switch shortage {
+	case PlanetShortageOrganics:
+		return fmt.Sprintf("Planet %s has stalled: the colony is out of organics and starving. Unload organics to resume production.", planetName)
+	case PlanetShortageOre:
+		return fmt.Sprintf("Planet %s is short of ore: equipment production is reduced until ore is restocked.", planetName)
+	default:
+		return fmt.Sprintf("Planet %s has resumed full production.", planetName)
+	}
+}
+
+func runPlanetTick(ctx context.Context, pool *pgxpool.Pool) error {
+	rules, err := loadActiveSeasonRules(ctx, pool)
+	if err != nil {
+		return err
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, `
+		SELECT
+			id, name, owner_player_id, planet_class, shortage, population, citadel_level,
+			production_ore, production_organics, production_equipment,
+			storage_ore, storage_organics, storage_equipment, storage_max
+		FROM planets
+		ORDER BY id
+		FOR UPDATE
+	`)
+	if err != nil {
+		return err
+	}
+	var planets []planetForUpdate
+	for rows.Next() {
+		var pl planetForUpdate
+		if err := rows.Scan(
+			&pl.ID, &pl.Name, &pl.OwnerPlayerID, &pl.Class, &pl.Shortage, &pl.Population, &pl.CitadelLevel,
+			&pl.ProductionOre, &pl.ProductionOrganics, &pl.ProductionEquipment,
+			&pl.StorageOre, &pl.StorageOrganics, &pl.StorageEquipment, &pl.StorageMax,
+		); err != nil {
+			rows.Close()
+			return err
+		}
+		planets = append(planets, pl)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, pl := range planets {
+		res := simulateProduction(pl, rules.planetRecipe(pl.Class))
+		_, err := tx.Exec(ctx, `
+			UPDATE planets SET
+				storage_ore = $2,
+				storage_organics = $3,
+				storage_equipment = $4,
+				shortage = $5,
+				population = $6,
+				last_produced = now()
+			WHERE id = $1
+		`, pl.ID, res.Stock.Ore, res.Stock.Organics, res.Stock.Equipment, res.Shortage, res.Population)
+		if err != nil {
+			return err
+		}
+		// Only report changes in status so a stalled planet does not flood the owner's log.
+		if res.Shortage != pl.Shortage && pl.OwnerPlayerID.Valid {
+			if err := InsertLog(ctx, tx, pl.OwnerPlayerID.String, "SYSTEM", shortageMessage(pl.Name, res.Shortage)); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit(ctx)
+}
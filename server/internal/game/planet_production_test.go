@@ -0,0 +1,139 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimulateProductionFedColony(t *testing.T) {
+	pl := planetForUpdate{
+		ProductionOre: 10, ProductionOrganics: 6, ProductionEquipment: 3,
+		StorageOre: 0, StorageOrganics: 0, StorageEquipment: 0, StorageMax: 1000,
+		Population: 1000,
+	}
+	res := simulateProduction(pl, findPlanetRecipe("M"))
+	if res.Shortage != PlanetShortageNone {
+		t.Fatalf("expected no shortage, got %q", res.Shortage)
+	}
+	// 6 organics grown, 4 eaten.
+	if res.Stock.Organics != 2 {
+		t.Fatalf("expected 2 organics, got %d", res.Stock.Organics)
+	}
+	// 10 ore mined, 3 equipment built at 2 ore each.
+	if res.Stock.Ore != 4 || res.Stock.Equipment != 3 {
+		t.Fatalf("expected ore=4 eq=3, got ore=%d eq=%d", res.Stock.Ore, res.Stock.Equipment)
+	}
+}
+
+func TestSimulateProductionStallsWithoutOrganics(t *testing.T) {
+	pl := planetForUpdate{
+		ProductionOre: 10, ProductionOrganics: 2, ProductionEquipment: 3,
+		StorageOre: 50, StorageOrganics: 0, StorageEquipment: 0, StorageMax: 1000,
+		Population: 1000,
+	}
+	// Forge worlds grow 90% of base organics (1), short of the 2 the colony needs.
+	res := simulateProduction(pl, findPlanetRecipe("D"))
+	if res.Shortage != PlanetShortageOrganics {
+		t.Fatalf("expected organics shortage, got %q", res.Shortage)
+	}
+	if res.Stock.Ore != 50 || res.Stock.Equipment != 0 {
+		t.Fatalf("expected stalled production, got ore=%d eq=%d", res.Stock.Ore, res.Stock.Equipment)
+	}
+	// A starving colony shrinks by 5%.
+	if res.Population != 950 {
+		t.Fatalf("expected population 950, got %d", res.Population)
+	}
+}
+
+func TestSimulateProductionOreShortage(t *testing.T) {
+	pl := planetForUpdate{
+		ProductionOre: 2, ProductionOrganics: 10, ProductionEquipment: 10,
+		StorageOre: 0, StorageOrganics: 10, StorageEquipment: 0, StorageMax: 1000,
+		Population: 1000,
+	}
+	// Oceanic: 1 ore mined, 7 equipment wanted at 2 ore each.
+	res := simulateProduction(pl, findPlanetRecipe("O"))
+	if res.Shortage != PlanetShortageOre {
+		t.Fatalf("expected ore shortage, got %q", res.Shortage)
+	}
+	if res.Stock.Equipment != 0 || res.Stock.Ore != 1 {
+		t.Fatalf("expected ore=1 eq=0, got ore=%d eq=%d", res.Stock.Ore, res.Stock.Equipment)
+	}
+}
+
+func TestSimulateProductionScalesWithPopulation(t *testing.T) {
+	pl := planetForUpdate{
+		ProductionOre: 20, ProductionOrganics: 10, ProductionEquipment: 0,
+		StorageOrganics: 100, StorageMax: 1000,
+		Population: 500,
+	}
+	res := simulateProduction(pl, findPlanetRecipe("M"))
+	// Half a workforce produces half the ore and eats half the upkeep.
+	if res.Produced.Ore != 10 || res.Consumed.Organics != 2 {
+		t.Fatalf("expected ore=10 upkeep=2, got ore=%d upkeep=%d", res.Produced.Ore, res.Consumed.Organics)
+	}
+	if res.Population != 510 {
+		t.Fatalf("expected population to grow to 510, got %d", res.Population)
+	}
+
+	// Growth stops at the class cap plus citadel housing.
+	pl.Population = 2000
+	if res := simulateProduction(pl, findPlanetRecipe("M")); res.Population != 2000 {
+		t.Fatalf("expected population capped at 2000, got %d", res.Population)
+	}
+	pl.CitadelLevel = 1
+	if res := simulateProduction(pl, findPlanetRecipe("M")); res.Population != 2040 {
+		t.Fatalf("expected citadel to allow growth to 2040, got %d", res.Population)
+	}
+
+	// An empty planet neither produces nor eats.
+	pl.Population = 0
+	if res := simulateProduction(pl, findPlanetRecipe("M")); res.Produced.Organics != 0 || res.Shortage != PlanetShortageNone {
+		t.Fatalf("expected idle planet, got %+v", res)
+	}
+}
+
+func TestForgeFeedsItself(t *testing.T) {
+	forge := findPlanetRecipe("D")
+	// Generated planets grow 5..20 organics at a full workforce.
+	for org := 5; org <= 20; org++ {
+		pl := planetForUpdate{ProductionOre: 10, ProductionOrganics: org, ProductionEquipment: 5, StorageMax: 1000, Population: 1000}
+		if res := simulateProduction(pl, forge); res.Shortage == PlanetShortageOrganics {
+			t.Fatalf("forge with %d base organics stalled", org)
+		}
+	}
+	// A freshly colonized forge (6 base organics, founding population) is fed too.
+	pl := planetForUpdate{ProductionOre: 10, ProductionOrganics: 6, ProductionEquipment: 3, StorageMax: 2000, Population: planetFoundingPopulation}
+	if res := simulateProduction(pl, forge); res.Shortage == PlanetShortageOrganics {
+		t.Fatalf("newly founded forge stalled")
+	}
+}
+
+func TestSeasonPlanetRecipe(t *testing.T) {
+	r, err := ParseSeasonRules([]byte(`{"planet_recipes":{"d":{"organics_upkeep":0,"population_cap":3000}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	forge := r.planetRecipe("D")
+	if forge.OrganicsUpkeep != 0 || forge.PopulationCap != 3000 || forge.EquipmentYield != findPlanetRecipe("D").EquipmentYield {
+		t.Fatalf("override not applied field by field: %+v", forge)
+	}
+	if r.planetRecipe("M") != findPlanetRecipe("M") {
+		t.Fatalf("other classes should keep the built-in recipe")
+	}
+	if (SeasonRules{}).planetRecipe("O") != findPlanetRecipe("O") {
+		t.Fatalf("zero rules should keep the built-in recipe")
+	}
+
+	for raw, want := range map[string]string{
+		`{"planet_recipes":{"X":{"ore_yield":10}}}`:        "unknown planet class",
+		`{"planet_recipes":{"M":{"ore_yield":-1}}}`:        "M ore_yield",
+		`{"planet_recipes":{"L":{"population_cap":10}}}`:   "L population_cap",
+		`{"planet_recipes":{"O":{"organics_upkeep":500}}}`: "O organics_upkeep",
+		`{"planet_recipes":{"D":{"ore_per_widget":1}}}`:    "unknown field",
+	} {
+		if _, err := ParseSeasonRules([]byte(raw)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: got %v, want error containing %q", raw, err, want)
+		}
+	}
+}
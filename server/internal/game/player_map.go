@@ -0,0 +1,288 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PlayerMap is a player's fog-of-war view of the galaxy: the sectors they have discovered, the
+// warps leading out of them, and what their own scans and visits revealed.
+type PlayerMap struct {
+	CurrentSector int       `json:"current_sector"`
+	Nodes         []MapNode `json:"nodes"`
+	Edges         []MapEdge `json:"edges"`
+}
+
+// MapNode is one sector on a player map. Sectors reached by a known warp but never visited are
+// included with Discovered=false and no details.
+type MapNode struct {
+	ID             int      `json:"id"`
+	Name           string   `json:"name,omitempty"`
+	Region         string   `json:"region,omitempty"`
+	Discovered     bool     `json:"discovered"`
+	IsProtectorate bool     `json:"is_protectorate"`
+	Port           *MapPort `json:"port,omitempty"`
+	Planet         string   `json:"planet,omitempty"`
+}
+
+// MapPort is the port as last seen by the player's SCAN intel.
+type MapPort struct {
+	OreMode       string    `json:"ore_mode"`
+	OrganicsMode  string    `json:"organics_mode"`
+	EquipmentMode string    `json:"equipment_mode"`
+	ScannedAt     time.Time `json:"scanned_at"`
+}
+
+// Code is the classic three-letter port code, e.g. "BSB" for buying ore, selling organics and
+// buying equipment.
+func (p MapPort) Code() string {
+	letter := func(mode string) string {
+		if mode == "" {
+			return "?"
+		}
+		return mode[:1]
+	}
+	return letter(p.OreMode) + letter(p.OrganicsMode) + letter(p.EquipmentMode)
+}
+
+// MapEdge is a warp. Two-way lanes appear as two edges.
+type MapEdge struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// LoadPlayerMap builds the map from player_discoveries, warps, the player's port intel and the
+// planets in discovered sectors.
+func LoadPlayerMap(ctx context.Context, pool *pgxpool.Pool, playerID string) (PlayerMap, error) {
+	var m PlayerMap
+	if err := pool.QueryRow(ctx, "SELECT sector_id FROM players WHERE id=$1", playerID).Scan(&m.CurrentSector); err != nil {
+		return PlayerMap{}, err
+	}
+
+	nodes := map[int]*MapNode{}
+	rows, err := pool.Query(ctx, `
+		SELECT s.id, s.name, s.region, s.is_protectorate, COALESCE(pl.name, '')
+		FROM player_discoveries d
+		JOIN sectors s ON s.id = d.sector_id
+		LEFT JOIN planets pl ON pl.sector_id = s.id
+		WHERE d.player_id = $1
+	`, playerID)
+	if err != nil {
+		return PlayerMap{}, err
+	}
+	for rows.Next() {
+		n := &MapNode{Discovered: true}
+		if err := rows.Scan(&n.ID, &n.Name, &n.Region, &n.IsProtectorate, &n.Planet); err != nil {
+			rows.Close()
+			return PlayerMap{}, err
+		}
+		nodes[n.ID] = n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return PlayerMap{}, err
+	}
+
+	intelRows, err := pool.Query(ctx, `
+		SELECT sector_id, ore_mode, organics_mode, equipment_mode, scanned_at
+		FROM player_sector_intel
+		WHERE player_id = $1
+	`, playerID)
+	if err != nil {
+		return PlayerMap{}, err
+	}
+	for intelRows.Next() {
+		var sid int
+		var port MapPort
+		if err := intelRows.Scan(&sid, &port.OreMode, &port.OrganicsMode, &port.EquipmentMode, &port.ScannedAt); err != nil {
+			intelRows.Close()
+			return PlayerMap{}, err
+		}
+		if n, ok := nodes[sid]; ok {
+			n.Port = &port
+		}
+	}
+	intelRows.Close()
+	if err := intelRows.Err(); err != nil {
+		return PlayerMap{}, err
+	}
+
+	warpRows, err := pool.Query(ctx, `
+		SELECT w.from_sector, w.to_sector
+		FROM warps w
+		JOIN player_discoveries d ON d.sector_id = w.from_sector AND d.player_id = $1
+		ORDER BY w.from_sector, w.to_sector
+	`, playerID)
+	if err != nil {
+		return PlayerMap{}, err
+	}
+	for warpRows.Next() {
+		var e MapEdge
+		if err := warpRows.Scan(&e.From, &e.To); err != nil {
+			warpRows.Close()
+			return PlayerMap{}, err
+		}
+		m.Edges = append(m.Edges, e)
+		if _, ok := nodes[e.To]; !ok {
+			nodes[e.To] = &MapNode{ID: e.To}
+		}
+	}
+	warpRows.Close()
+	if err := warpRows.Err(); err != nil {
+		return PlayerMap{}, err
+	}
+
+	m.Nodes = make([]MapNode, 0, len(nodes))
+	for _, n := range nodes {
+		m.Nodes = append(m.Nodes, *n)
+	}
+	sort.Slice(m.Nodes, func(i, j int) bool { return m.Nodes[i].ID < m.Nodes[j].ID })
+	if m.Edges == nil {
+		m.Edges = []MapEdge{}
+	}
+	return m, nil
+}
+
+// mapLane is a warp drawn once; OneWay is set when only From->To is known.
+type mapLane struct {
+	From, To int
+	OneWay   bool
+}
+
+func (m PlayerMap) lanes() []mapLane {
+	known := make(map[MapEdge]bool, len(m.Edges))
+	for _, e := range m.Edges {
+		known[e] = true
+	}
+	var out []mapLane
+	for _, e := range m.Edges {
+		rev := known[MapEdge{From: e.To, To: e.From}]
+		if rev && e.From > e.To {
+			continue // drawn from the other side
+		}
+		out = append(out, mapLane{From: e.From, To: e.To, OneWay: !rev})
+	}
+	return out
+}
+
+// DOT renders the map as a GraphViz graph. Two-way lanes are drawn once with arrows at both ends.
+func (m PlayerMap) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph galaxy {\n")
+	b.WriteString("\tnode [shape=circle, fontsize=10];\n")
+	for _, n := range m.Nodes {
+		label := fmt.Sprintf("%d", n.ID)
+		attrs := []string{}
+		if !n.Discovered {
+			attrs = append(attrs, "style=dashed", "color=gray")
+		} else {
+			if n.Port != nil {
+				label += "\\n" + n.Port.Code()
+			}
+			if n.Planet != "" {
+				label += "\\n(" + dotEscape(n.Planet) + ")"
+			}
+			if n.IsProtectorate {
+				attrs = append(attrs, "style=filled", "fillcolor=palegreen")
+			}
+			if n.Name != "" {
+				attrs = append(attrs, fmt.Sprintf("tooltip=\"%s\"", dotEscape(n.Name)))
+			}
+		}
+		if n.ID == m.CurrentSector {
+			attrs = append(attrs, "penwidth=3")
+		}
+		attrs = append([]string{fmt.Sprintf("label=\"%s\"", label)}, attrs...)
+		b.WriteString(fmt.Sprintf("\t%d [%s];\n", n.ID, strings.Join(attrs, ", ")))
+	}
+	for _, l := range m.lanes() {
+		if l.OneWay {
+			b.WriteString(fmt.Sprintf("\t%d -> %d;\n", l.From, l.To))
+		} else {
+			b.WriteString(fmt.Sprintf("\t%d -> %d [dir=both];\n", l.From, l.To))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// ANSI renders the map for a terminal: one line per discovered sector with its port code, planet
+// and exits. Unvisited exits are dimmed with a "?" and one-way exits are marked ">".
+func (m PlayerMap) ANSI() string {
+	discovered := map[int]bool{}
+	count := 0
+	width := 1
+	for _, n := range m.Nodes {
+		discovered[n.ID] = n.Discovered
+		if n.Discovered {
+			count++
+		}
+		width = max(width, len(fmt.Sprintf("%d", n.ID)))
+	}
+	exits := map[int][]int{}
+	known := map[MapEdge]bool{}
+	for _, e := range m.Edges {
+		exits[e.From] = append(exits[e.From], e.To)
+		known[e] = true
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%sGalaxy map%s (%d discovered, you are in sector %d)\n", ansiBold, ansiReset, count, m.CurrentSector))
+	b.WriteString(fmt.Sprintf("Legend: %sG%s Protectorate  %sBSB%s port (your intel)  %s@%s planet  %s?%s unexplored  > one-way\n\n",
+		ansiGreen, ansiReset, ansiYellow, ansiReset, ansiCyan, ansiReset, ansiDim, ansiReset))
+	for _, n := range m.Nodes {
+		if !n.Discovered {
+			continue
+		}
+		marker := " "
+		if n.ID == m.CurrentSector {
+			marker = "*"
+		}
+		id := fmt.Sprintf("%*d", width, n.ID)
+		if n.IsProtectorate {
+			id = ansiGreen + id + "G" + ansiReset
+		} else {
+			id += " "
+		}
+		port := "   "
+		if n.Port != nil {
+			port = ansiYellow + n.Port.Code() + ansiReset
+		}
+		planet := " "
+		if n.Planet != "" {
+			planet = ansiCyan + "@" + ansiReset
+		}
+
+		outs := make([]string, 0, len(exits[n.ID]))
+		for _, to := range exits[n.ID] {
+			s := fmt.Sprintf("%d", to)
+			if !known[MapEdge{From: to, To: n.ID}] && discovered[to] {
+				s = ">" + s
+			}
+			if !discovered[to] {
+				s = ansiDim + s + "?" + ansiReset
+			}
+			outs = append(outs, s)
+		}
+		b.WriteString(fmt.Sprintf("%s%s %s %s %s -> %s\n", marker, id, port, planet, n.Name, strings.Join(outs, " ")))
+	}
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func testPlayerMap() PlayerMap {
+	return PlayerMap{
+		CurrentSector: 1,
+		Nodes: []MapNode{
+			{ID: 1, Name: "Home", Discovered: true, IsProtectorate: true},
+			{ID: 2, Name: "Ka \"Reach\"", Discovered: true, Port: &MapPort{OreMode: "BUY", OrganicsMode: "SELL", EquipmentMode: "BUY"}, Planet: "Vex II"},
+			{ID: 3},
+		},
+		Edges: []MapEdge{{From: 1, To: 2}, {From: 2, To: 1}, {From: 2, To: 3}},
+	}
+}
+
+func TestPlayerMapDOT(t *testing.T) {
+	dot := testPlayerMap().DOT()
+	for _, want := range []string{
+		"1 -> 2 [dir=both];",
+		"2 -> 3;",
+		`label="2\nBSB\n(Vex II)"`,
+		`tooltip="Ka \"Reach\""`,
+		"3 [label=\"3\", style=dashed",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Fatalf("DOT missing %q:\n%s", want, dot)
+		}
+	}
+	if strings.Contains(dot, "2 -> 1") {
+		t.Fatalf("two-way lane drawn twice:\n%s", dot)
+	}
+}
+
+func TestPlayerMapANSI(t *testing.T) {
+	out := testPlayerMap().ANSI()
+	if !strings.Contains(out, "2 discovered") {
+		t.Fatalf("header: %s", out)
+	}
+	if !strings.Contains(out, "*") || !strings.Contains(out, "3?") {
+		t.Fatalf("expected current marker and unexplored exit:\n%s", out)
+	}
+}
@@ -0,0 +1,410 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	PortClassTrading     = "TRADING"
+	PortClassStardock    = "STARDOCK"
+	PortClassBlackMarket = "BLACK_MARKET"
+	PortClassFuelDepot   = "FUEL_DEPOT"
+
+	stardockMinePrice = 150
+	// The scanner is sold at stardocks and shipyards (SHIPYARD UPGRADE SCANNER).
+	scannerPrice = 20000
+	// Fuel depots sell turns up to the ship's TurnsMax, and at most fuelDailyTankPct of a full
+	// tank per 24 hours so fuel tops up turn regen rather than replacing it.
+	fuelPricePerTurn = 40
+	fuelDailyTankPct = 50
+	fuelLimitWindow  = 24 * time.Hour
+
+	blackMarketMinPrice = 120
+	blackMarketMaxPrice = 360
+	// Black markets buy contraband back at this share of their asking price.
+	blackMarketBidPct = 70
+	// Chance that a Protectorate patrol inspects a ship carrying contraband into Protectorate space,
+	// and the fine per seized unit.
+	contrabandSeizurePct  = 40
+	contrabandFinePerUnit = 50
+)
+
+// Share of non-Protectorate ports (percent) promoted to each special class; at least one of each.
+var portClassShares = []struct {
+	Class string
+	Pct   int
+}{
+	{PortClassStardock, 4},
+	{PortClassBlackMarket, 5},
+	{PortClassFuelDepot, 6},
+}
+
+func blackMarketBid(price int) int {
+	return price * blackMarketBidPct / 100
+}
+
+// EnsurePortClasses promotes trading ports outside Protectorate space to the special classes until
+// each class reaches its share. It is deterministic for a seed and safe to run on every startup.
+func EnsurePortClasses(ctx context.Context, pool *pgxpool.Pool, seed int64) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := ensurePortClassesTx(ctx, tx, seed); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func ensurePortClassesTx(ctx context.Context, tx pgx.Tx, seed int64) (int, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT po.sector_id, po.port_class
+		FROM ports po
+		JOIN sectors s ON s.id = po.sector_id
+		WHERE s.is_protectorate = false
+		ORDER BY po.sector_id
+	`)
+	if err != nil {
+		return 0, err
+	}
+	var trading []int
+	counts := map[string]int{}
+	total := 0
+	for rows.Next() {
+		var id int
+		var class string
+		if err := rows.Scan(&id, &class); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		total++
+		counts[class]++
+		if class == PortClassTrading {
+			trading = append(trading, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	rng := rand.New(rand.NewSource(seed + 0x50434c53)) // "PCLS"
+	rng.Shuffle(len(trading), func(i, j int) { trading[i], trading[j] = trading[j], trading[i] })
+
+	promoted := 0
+	for _, share := range portClassShares {
+		need := max(1, total*share.Pct/100) - counts[share.Class]
+		for ; need > 0 && len(trading) > 0; need-- {
+			id := trading[0]
+			trading = trading[1:]
+			price := 0
+			if share.Class == PortClassBlackMarket {
+				price = blackMarketMinPrice + rng.Intn(blackMarketMaxPrice-blackMarketMinPrice+1)
+			}
+			if _, err := tx.Exec(ctx, "UPDATE ports SET port_class=$2, contraband_price=$3 WHERE sector_id=$1", id, share.Class, price); err != nil {
+				return 0, err
+			}
+			promoted++
+		}
+	}
+	return promoted, nil
+}
+
+type portClassInfo struct {
+	Class           string
+	ContrabandPrice int
+}
+
+func loadPortClass(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, sectorID int) (portClassInfo, bool, error) {
+	var pc portClassInfo
+	err := q.QueryRow(ctx, "SELECT port_class, contraband_price FROM ports WHERE sector_id=$1", sectorID).Scan(&pc.Class, &pc.ContrabandPrice)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return portClassInfo{}, false, nil
+	}
+	if err != nil {
+		return portClassInfo{}, false, err
+	}
+	return pc, true, nil
+}
+
+func wrongPortClass(label string) phase2Result {
+	msg := fmt.Sprintf("There is no %s in this sector.", label)
+	return phase2Result{OK: false, Message: msg, ErrorCode: "WRONG_PORT_CLASS"}
+}
+
+func executeStardockCommand(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRequest) (phase2Result, error) {
+	pc, ok, err := loadPortClass(ctx, tx, p.SectorID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !ok || pc.Class != PortClassStardock {
+		return wrongPortClass("stardock"), nil
+	}
+
+	action := strings.ToUpper(strings.TrimSpace(cmd.Action))
+	switch action {
+	case "", "INFO":
+		msg := strings.Join([]string{
+			"STARDOCK commands: STARDOCK BUY HOLDS [qty] | STARDOCK BUY MINES {qty} | STARDOCK BUY SCANNER",
+			fmt.Sprintf("Cargo holds: +5 per purchase, next costs %d (%d/%d bought)", cargoUpgradeCost(p.ShipCargoUpgrades), p.ShipCargoUpgrades, maxCargoUpgrades),
+			fmt.Sprintf("Mines: %d credits each, carried in the ship's mine racks (%d/%d)", stardockMinePrice, p.ShipMines, mineRacks(*p)),
+			fmt.Sprintf("Scanner: %d credits (installed: %s); SCAN lists adjacent ports and SCAN HOLO is unlocked", scannerPrice, yesNo(p.ShipScanner)),
+		}, "\n")
+		return phase2Result{OK: true, Message: msg}, nil
+	case "BUY":
+		return stardockBuy(p, cmd)
+	default:
+		return phase2Result{OK: false, Message: "Unknown STARDOCK subcommand.", ErrorCode: "UNKNOWN_SUBCOMMAND"}, nil
+	}
+}
+
+func stardockBuy(p *Player, cmd CommandRequest) (phase2Result, error) {
+	switch strings.ToUpper(strings.TrimSpace(cmd.Name)) {
+	case "HOLDS":
+		qty := max(1, cmd.Quantity)
+		var bought int
+		var spent int64
+		for i := 0; i < qty; i++ {
+			if p.ShipCargoUpgrades >= maxCargoUpgrades || p.Credits < cargoUpgradeCost(p.ShipCargoUpgrades) {
+				break
+			}
+			cost := cargoUpgradeCost(p.ShipCargoUpgrades)
+			p.Credits -= cost
+			p.ShipCargoUpgrades++
+			p.CargoMax += 5
+			bought++
+			spent += cost
+		}
+		if bought == 0 {
+			if p.ShipCargoUpgrades >= maxCargoUpgrades {
+				return phase2Result{OK: false, Message: "Cargo upgrades are already at maximum.", ErrorCode: "MAX_UPGRADES"}, nil
+			}
+			return phase2Result{OK: false, Message: "Insufficient credits for cargo holds.", ErrorCode: "INSUFFICIENT_CREDITS"}, nil
+		}
+		msg := fmt.Sprintf("Installed %d cargo hold upgrade(s) (+%d). New CargoMax=%d. Cost=%d.", bought, bought*5, p.CargoMax, spent)
+		return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+	case "MINES":
+		if cmd.Quantity < 1 {
+			return phase2Result{OK: false, Message: "Mine quantity must be at least 1.", ErrorCode: "INVALID_QTY"}, nil
+		}
+		if room := mineRacks(*p) - p.ShipMines; cmd.Quantity > room {
+			return phase2Result{OK: false, Message: fmt.Sprintf("Your mine racks hold %d more mine(s).", max(0, room)), ErrorCode: "RACKS_FULL"}, nil
+		}
+		cost := int64(cmd.Quantity) * stardockMinePrice
+		if p.Credits < cost {
+			return phase2Result{OK: false, Message: "Insufficient credits for mines.", ErrorCode: "INSUFFICIENT_CREDITS"}, nil
+		}
+		p.Credits -= cost
+		p.ShipMines += cmd.Quantity
+		msg := fmt.Sprintf("Bought %d mines for %d credits. Mine racks: %d/%d.", cmd.Quantity, cost, p.ShipMines, mineRacks(*p))
+		return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+	case "SCANNER":
+		if p.ShipScanner {
+			return phase2Result{OK: false, Message: "Your ship already has a scanner.", ErrorCode: "ALREADY_INSTALLED"}, nil
+		}
+		if p.Credits < scannerPrice {
+			return phase2Result{OK: false, Message: "Insufficient credits for a scanner.", ErrorCode: "INSUFFICIENT_CREDITS"}, nil
+		}
+		p.Credits -= scannerPrice
+		p.ShipScanner = true
+		msg := fmt.Sprintf("Scanner installed for %d credits.", scannerPrice)
+		return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+	default:
+		return phase2Result{OK: false, Message: "Stardocks sell HOLDS, MINES and SCANNER.", ErrorCode: "INVALID_ARGS"}, nil
+	}
+}
+
+func executeFuelCommand(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRequest) (phase2Result, error) {
+	pc, ok, err := loadPortClass(ctx, tx, p.SectorID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !ok || pc.Class != PortClassFuelDepot {
+		return wrongPortClass("fuel depot"), nil
+	}
+
+	bought, err := fuelBoughtToday(ctx, tx, p.ID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	room := p.TurnsMax - p.Turns
+	action := strings.ToUpper(strings.TrimSpace(cmd.Action))
+	switch action {
+	case "", "INFO":
+		msg := fmt.Sprintf("Fuel: %d credits per turn. Your tanks take %d more turn(s); you can buy %d more in the next 24h. FUEL BUY [turns] (fills up when no amount is given).", fuelPricePerTurn, room, max(0, fuelDailyTurns(*p)-bought))
+		return phase2Result{OK: true, Message: msg}, nil
+	case "BUY":
+		before := p.Turns
+		res, err := fuelBuy(p, cmd.Quantity, bought)
+		if err != nil || !res.OK {
+			return res, err
+		}
+		turns := p.Turns - before
+		if _, err := tx.Exec(ctx, `INSERT INTO fuel_purchases(player_id, turns, credits) VALUES ($1,$2,$3)`, p.ID, turns, int64(turns)*fuelPricePerTurn); err != nil {
+			return phase2Result{}, err
+		}
+		return res, nil
+	default:
+		return phase2Result{OK: false, Message: "Unknown FUEL subcommand.", ErrorCode: "UNKNOWN_SUBCOMMAND"}, nil
+	}
+}
+
+// fuelDailyTurns is how many turns a player may buy at fuel depots per 24 hours.
+func fuelDailyTurns(p Player) int {
+	return p.TurnsMax * fuelDailyTankPct / 100
+}
+
+// fuelBoughtToday sums the turns playerID bought at fuel depots in the last 24 hours.
+func fuelBoughtToday(ctx context.Context, tx pgx.Tx, playerID string) (int, error) {
+	var total int
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(turns), 0)::int FROM fuel_purchases
+		WHERE player_id=$1 AND created_at > $2
+	`, playerID, time.Now().UTC().Add(-fuelLimitWindow)).Scan(&total)
+	return total, err
+}
+
+// fuelBuy sells up to qty turns (a full tank when qty is 0), limited by tank room, credits and
+// what is left of the daily fuel allowance after boughtToday.
+func fuelBuy(p *Player, qty, boughtToday int) (phase2Result, error) {
+	room := p.TurnsMax - p.Turns
+	if room <= 0 {
+		return phase2Result{OK: false, Message: "Your tanks are full.", ErrorCode: "TURNS_FULL"}, nil
+	}
+	allowance := fuelDailyTurns(*p) - boughtToday
+	if allowance <= 0 {
+		msg := fmt.Sprintf("You have bought your daily %d turns of fuel; turns still regenerate as usual.", fuelDailyTurns(*p))
+		return phase2Result{OK: false, Message: msg, ErrorCode: "FUEL_LIMIT"}, nil
+	}
+	if qty <= 0 || qty > room {
+		qty = room
+	}
+	if qty > allowance {
+		qty = allowance
+	}
+	if affordable := int(p.Credits / fuelPricePerTurn); qty > affordable {
+		qty = affordable
+	}
+	if qty <= 0 {
+		return phase2Result{OK: false, Message: "Not enough credits for fuel.", ErrorCode: "INSUFFICIENT_CREDITS"}, nil
+	}
+	cost := int64(qty) * fuelPricePerTurn
+	p.Credits -= cost
+	p.Turns += qty
+	msg := fmt.Sprintf("Refueled %d turn(s) for %d credits. Turns: %d/%d.", qty, cost, p.Turns, p.TurnsMax)
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+}
+
+func executeBlackMarketCommand(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRequest) (phase2Result, error) {
+	pc, ok, err := loadPortClass(ctx, tx, p.SectorID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	if !ok || pc.Class != PortClassBlackMarket {
+		return wrongPortClass("black market"), nil
+	}
+	bid := blackMarketBid(pc.ContrabandPrice)
+
+	action := strings.ToUpper(strings.TrimSpace(cmd.Action))
+	if action == "" || action == "INFO" {
+		msg := fmt.Sprintf("Black market: contraband sells for %d and is bought back for %d. You carry %d. BLACKMARKET BUY {qty} | BLACKMARKET SELL {qty}. Protectorate patrols seize contraband.", pc.ContrabandPrice, bid, p.CargoContraband)
+		return phase2Result{OK: true, Message: msg}, nil
+	}
+	qty := cmd.Quantity
+	if qty < 1 {
+		return phase2Result{OK: false, Message: "Quantity must be at least 1.", ErrorCode: "INVALID_QTY"}, nil
+	}
+
+	switch action {
+	case "BUY":
+		if totalCargo(p)+qty > p.CargoMax {
+			return phase2Result{OK: false, Message: "Not enough cargo space.", ErrorCode: "CARGO_FULL"}, nil
+		}
+		cost := int64(qty) * int64(pc.ContrabandPrice)
+		if p.Credits < cost {
+			return phase2Result{OK: false, Message: "Not enough credits.", ErrorCode: "INSUFFICIENT_CREDITS"}, nil
+		}
+		p.Credits -= cost
+		p.CargoContraband += qty
+		msg := fmt.Sprintf("Bought %d contraband for %d credits.", qty, cost)
+		return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+	case "SELL":
+		if p.CargoContraband < qty {
+			return phase2Result{OK: false, Message: "You are not carrying that much contraband.", ErrorCode: "INSUFFICIENT_CARGO"}, nil
+		}
+		earned := int64(qty) * int64(bid)
+		p.Credits += earned
+		p.CargoContraband -= qty
+		msg := fmt.Sprintf("Sold %d contraband for %d credits.", qty, earned)
+		return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+	default:
+		return phase2Result{OK: false, Message: "Unknown BLACKMARKET subcommand.", ErrorCode: "UNKNOWN_SUBCOMMAND"}, nil
+	}
+}
+
+// contrabandInspection runs when a ship enters Protectorate space. Patrols inspect a share of
+// ships; contraband found is confiscated and fined per unit (up to the credits on hand).
+func contrabandInspection(ctx context.Context, tx pgx.Tx, p *Player, roll int) (string, error) {
+	if p.CargoContraband <= 0 || roll >= contrabandSeizurePct {
+		return "", nil
+	}
+	isProt, err := IsProtectorateSector(ctx, tx, p.SectorID)
+	if err != nil || !isProt {
+		return "", err
+	}
+	seized := p.CargoContraband
+	fine := min64(p.Credits, int64(seized)*contrabandFinePerUnit)
+	p.CargoContraband = 0
+	p.Credits -= fine
+	return fmt.Sprintf("Protectorate patrol inspection! %d contraband confiscated and a fine of %d credits collected.", seized, fine), nil
+}
+
+// scannerPortSummary lists the adjacent sectors that have ports, for ships with a scanner.
+func scannerPortSummary(ctx context.Context, tx pgx.Tx, sectorID int) (string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT w.to_sector, po.port_class
+		FROM warps w
+		JOIN ports po ON po.sector_id = w.to_sector
+		WHERE w.from_sector = $1
+		ORDER BY w.to_sector
+	`, sectorID)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var parts []string
+	for rows.Next() {
+		var id int
+		var class string
+		if err := rows.Scan(&id, &class); err != nil {
+			return "", err
+		}
+		parts = append(parts, fmt.Sprintf("%d (%s)", id, class))
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	if len(parts) == 0 {
+		return "Scanner: no ports in adjacent sectors.", nil
+	}
+	return "Scanner: ports in adjacent sectors: " + strings.Join(parts, ", "), nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,51 @@
+package game
+
+import "testing"
+
+func TestFuelBuyFillsTanksWithinCredits(t *testing.T) {
+	p := Player{Turns: 40, TurnsMax: 100, Credits: 1000}
+	res, _ := fuelBuy(&p, 0, 0)
+	// 1000 credits buy 25 turns at 40 each, short of the 60 needed to fill up.
+	if !res.OK || p.Turns != 65 || p.Credits != 0 {
+		t.Fatalf("unexpected refuel: ok=%v turns=%d credits=%d", res.OK, p.Turns, p.Credits)
+	}
+
+	p = Player{Turns: 100, TurnsMax: 100, Credits: 1000}
+	if res, _ := fuelBuy(&p, 5, 0); res.OK || res.ErrorCode != "TURNS_FULL" {
+		t.Fatalf("expected full tanks error, got %+v", res)
+	}
+}
+
+func TestFuelBuyStopsAtDailyAllowance(t *testing.T) {
+	// Half a 100-turn tank per day: 30 already bought leaves 20.
+	p := Player{Turns: 0, TurnsMax: 100, Credits: 100000}
+	if res, _ := fuelBuy(&p, 0, 30); !res.OK || p.Turns != 20 || p.Credits != 100000-20*fuelPricePerTurn {
+		t.Fatalf("expected 20 turns within the allowance: ok=%v turns=%d credits=%d", res.OK, p.Turns, p.Credits)
+	}
+	if res, _ := fuelBuy(&p, 10, 50); res.OK || res.ErrorCode != "FUEL_LIMIT" || p.Turns != 20 {
+		t.Fatalf("expected daily fuel limit, got %+v turns=%d", res, p.Turns)
+	}
+}
+
+func TestStardockBuyHoldsStopsAtCredits(t *testing.T) {
+	p := Player{CargoMax: 30, Credits: cargoUpgradeCost(0) + cargoUpgradeCost(1)}
+	res, _ := stardockBuy(&p, CommandRequest{Name: "HOLDS", Quantity: 5})
+	if !res.OK || p.ShipCargoUpgrades != 2 || p.CargoMax != 40 || p.Credits != 0 {
+		t.Fatalf("unexpected hold purchase: %+v upgrades=%d cargo=%d credits=%d", res, p.ShipCargoUpgrades, p.CargoMax, p.Credits)
+	}
+
+	p = Player{Credits: stardockMinePrice * 3}
+	if res, _ := stardockBuy(&p, CommandRequest{Name: "MINES", Quantity: 3}); !res.OK || p.ShipMines != 3 || p.Credits != 0 {
+		t.Fatalf("unexpected mine purchase: %+v", res)
+	}
+
+	// A SCOUT racks 10 mines; buying past that is refused before any credits are spent.
+	p = Player{ShipType: "SCOUT", ShipMines: 8, Credits: stardockMinePrice * 5}
+	if res, _ := stardockBuy(&p, CommandRequest{Name: "MINES", Quantity: 3}); res.OK || res.ErrorCode != "RACKS_FULL" || p.ShipMines != 8 || p.Credits != stardockMinePrice*5 {
+		t.Fatalf("expected full mine racks, got %+v mines=%d credits=%d", res, p.ShipMines, p.Credits)
+	}
+	p = Player{ShipType: "INTERCEPTOR", ShipMines: 30}
+	if res, _ := shipyardSell(&p); res.OK || res.ErrorCode != "MINES_TOO_MANY" {
+		t.Fatalf("expected 30 racked mines to block selling down to a SCOUT, got %+v", res)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
-	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -174,23 +173,15 @@ func StartProtectorateTicker(ctx context.Context, pool *pgxpool.Pool, tickSecond
 	if tickSeconds < 10 {
 		tickSeconds = 10
 	}
-	go func() {
-		t := time.NewTicker(time.Duration(tickSeconds) * time.Second)
-		defer t.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-t.C:
-				// Randomize fighters into [min,max] each tick.
-				_, _ = pool.Exec(ctx, `
-					UPDATE sectors
-					SET protectorate_fighters = ($1 + floor(random()*($2-$1+1)))::int
-					WHERE is_protectorate=true
-				`, protectorateMinFighters, protectorateMaxFighters)
-			}
-		}
-	}()
+	pick := func(r SeasonRules) int { return r.ProtectorateTickSeconds }
+	startRulesTicker(ctx, pool, tickSeconds, 10, pick, func() {
+		// Randomize fighters into [min,max] each tick.
+		_, _ = pool.Exec(ctx, `
+			UPDATE sectors
+			SET protectorate_fighters = ($1 + floor(random()*($2-$1+1)))::int
+			WHERE is_protectorate=true
+		`, protectorateMinFighters, protectorateMaxFighters)
+	})
 }
 
 func IsProtectorateSector(ctx context.Context, q interface {
@@ -2,66 +2,379 @@ package game
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func executeRankingsCommand(ctx context.Context, tx pgx.Tx, p Player) (string, error) {
-	rows, err := tx.Query(ctx, `
-		SELECT
-			u.username,
-			pl.credits,
-			COALESCE(c.name, '')
-		FROM players pl
-		JOIN users u ON u.id = pl.user_id
-		LEFT JOIN corp_members cm ON cm.player_id = pl.id
-		LEFT JOIN corporations c ON c.id = cm.corp_id
-		WHERE pl.season_id = $1
-		ORDER BY pl.credits DESC, u.username ASC
-		LIMIT 10
-	`, p.SeasonID)
+// Leaderboard categories for RANKINGS.
+const (
+	RankingNetWorth = "NETWORTH"
+	RankingXP       = "XP"
+	RankingPlanets  = "PLANETS"
+	RankingCorps    = "CORPS"
+)
+
+const rankingsPageSize = 10
+
+// rankingOrder is the ORDER BY for each player category, best first.
+var rankingOrder = map[string]string{
+	RankingNetWorth: "net_worth DESC, username ASC",
+	RankingXP:       "xp DESC, username ASC",
+	RankingPlanets:  "planets DESC, net_worth DESC, username ASC",
+}
+
+// StartRankingTicker refreshes the ranking snapshot at startup and then every tickSeconds.
+func StartRankingTicker(ctx context.Context, pool *pgxpool.Pool, tickSeconds int) {
+	if tickSeconds <= 0 {
+		return
+	}
+	if tickSeconds < 30 {
+		tickSeconds = 30
+	}
+	go func() {
+		_ = refreshRankings(ctx, pool)
+		t := time.NewTicker(time.Duration(tickSeconds) * time.Second)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				_ = refreshRankings(ctx, pool)
+			}
+		}
+	}()
+}
+
+// refreshRankings replaces the snapshot with the active season's current standings.
+func refreshRankings(ctx context.Context, pool *pgxpool.Pool) error {
+	tx, err := pool.Begin(ctx)
 	if err != nil {
-		return "", err
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var seasonID int
+	err = tx.QueryRow(ctx, "SELECT id FROM seasons WHERE active=true ORDER BY id DESC LIMIT 1").Scan(&seasonID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
 	}
-	defer rows.Close()
+	standings, err := loadSeasonStandings(ctx, tx, seasonID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM ranking_snapshots"); err != nil {
+		return err
+	}
+	for _, r := range standings {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO ranking_snapshots(season_id, player_id, username, corp_id, corp_name, net_worth, credits, xp, level, planets, updated_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,now())
+			ON CONFLICT (season_id, player_id) DO UPDATE SET
+				username=EXCLUDED.username, corp_id=EXCLUDED.corp_id, corp_name=EXCLUDED.corp_name,
+				net_worth=EXCLUDED.net_worth, credits=EXCLUDED.credits, xp=EXCLUDED.xp,
+				level=EXCLUDED.level, planets=EXCLUDED.planets, updated_at=EXCLUDED.updated_at
+		`, seasonID, r.PlayerID, r.Username, r.CorpID, r.CorpName, r.NetWorth, r.Credits, r.XP, r.Level, r.Planets); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
 
-	lines := []string{fmt.Sprintf("Rankings (%s)", p.SeasonName)}
-	i := 0
+// snapshotNetWorth reads the net worth leader and playerID's own figure from the ranking
+// snapshot, so SEASON and RANKINGS agree. updated is nil when nothing has been ranked yet.
+func snapshotNetWorth(ctx context.Context, tx pgx.Tx, seasonID int, playerID string) (seasonLeader, int64, *time.Time, error) {
+	var leader seasonLeader
+	var updated *time.Time
+	err := tx.QueryRow(ctx, `
+		SELECT player_id, username, net_worth, updated_at
+		FROM ranking_snapshots
+		WHERE season_id=$1
+		ORDER BY net_worth DESC, username ASC
+		LIMIT 1
+	`, seasonID).Scan(&leader.PlayerID, &leader.Username, &leader.Value, &updated)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return seasonLeader{}, 0, nil, nil
+	}
+	if err != nil {
+		return seasonLeader{}, 0, nil, err
+	}
+	var mine int64
+	err = tx.QueryRow(ctx, "SELECT net_worth FROM ranking_snapshots WHERE season_id=$1 AND player_id=$2", seasonID, playerID).Scan(&mine)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return seasonLeader{}, 0, nil, err
+	}
+	return leader, mine, updated, nil
+}
+
+// parseRankingsArgs reads RANKINGS [NETWORTH|XP|PLANETS|CORPS] [page].
+func parseRankingsArgs(category string, page int) (string, int, bool) {
+	category = strings.ToUpper(strings.TrimSpace(category))
+	switch category {
+	case "":
+		category = RankingNetWorth
+	case "NET", "NET_WORTH":
+		category = RankingNetWorth
+	case RankingNetWorth, RankingXP, RankingPlanets, RankingCorps:
+	default:
+		return "", 0, false
+	}
+	if page < 1 {
+		page = 1
+	}
+	return category, page, true
+}
+
+func rankingPages(total int) int {
+	if total <= 0 {
+		return 1
+	}
+	return (total + rankingsPageSize - 1) / rankingsPageSize
+}
+
+func executeRankingsCommand(ctx context.Context, tx pgx.Tx, p Player, cmd CommandRequest) (phase2Result, error) {
+	category, page, ok := parseRankingsArgs(cmd.Action, cmd.Quantity)
+	if !ok {
+		return phase2Result{OK: false, Message: "Usage: RANKINGS [NETWORTH|XP|PLANETS|CORPS] [page]", ErrorCode: "INVALID_ARGS"}, nil
+	}
+
+	var players int
+	var updated *time.Time
+	if err := tx.QueryRow(ctx, "SELECT COUNT(1), MAX(updated_at) FROM ranking_snapshots WHERE season_id=$1", p.SeasonID).Scan(&players, &updated); err != nil {
+		return phase2Result{}, err
+	}
+	if updated == nil {
+		msg := fmt.Sprintf("Rankings (%s): no snapshot yet; rankings refresh every few minutes.", p.SeasonName)
+		return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "SYSTEM", msg: msg}}}, nil
+	}
+
+	var lines []string
+	var err error
+	if category == RankingCorps {
+		lines, err = corpRankingLines(ctx, tx, p, page)
+	} else {
+		lines, err = playerRankingLines(ctx, tx, p, category, page, players)
+	}
+	if err != nil {
+		return phase2Result{}, err
+	}
+	lines[0] += fmt.Sprintf(", updated %s ago", formatAgeShort(time.Now(), *updated))
+	msg := strings.Join(lines, "\n")
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "SYSTEM", msg: msg}}}, nil
+}
+
+func playerRankingLines(ctx context.Context, tx pgx.Tx, p Player, category string, page, total int) ([]string, error) {
+	order := rankingOrder[category]
+	labels := map[string]string{RankingNetWorth: "net worth", RankingXP: "XP", RankingPlanets: "planets"}
+	lines := []string{fmt.Sprintf("Rankings by %s (%s) - page %d/%d", labels[category], p.SeasonName, page, rankingPages(total))}
+
+	rows, err := tx.Query(ctx, `
+		SELECT username, corp_name, net_worth, xp, level, planets
+		FROM ranking_snapshots
+		WHERE season_id=$1
+		ORDER BY `+order+`
+		LIMIT $2 OFFSET $3
+	`, p.SeasonID, rankingsPageSize, (page-1)*rankingsPageSize)
+	if err != nil {
+		return nil, err
+	}
+	pos := (page - 1) * rankingsPageSize
 	for rows.Next() {
-		var username string
-		var credits int64
-		var corp string
-		if err := rows.Scan(&username, &credits, &corp); err != nil {
-			return "", err
+		var username, corp string
+		var netWorth, xp int64
+		var level, planets int
+		if err := rows.Scan(&username, &corp, &netWorth, &xp, &level, &planets); err != nil {
+			rows.Close()
+			return nil, err
 		}
-		i++
+		pos++
 		label := username
 		if corp != "" {
 			label = fmt.Sprintf("%s [%s]", username, corp)
 		}
-		lines = append(lines, fmt.Sprintf("%d. %s - %d", i, label, credits))
+		switch category {
+		case RankingXP:
+			lines = append(lines, fmt.Sprintf("%d. %s - %d XP (level %d)", pos, label, xp, level))
+		case RankingPlanets:
+			lines = append(lines, fmt.Sprintf("%d. %s - %d planets", pos, label, planets))
+		default:
+			lines = append(lines, fmt.Sprintf("%d. %s - %d", pos, label, netWorth))
+		}
 	}
+	rows.Close()
 	if err := rows.Err(); err != nil {
-		return "", err
+		return nil, err
 	}
-	if i == 0 {
-		lines = append(lines, "No rankings yet.")
+	if pos == (page-1)*rankingsPageSize {
+		lines = append(lines, "No entries on this page.")
 	}
-	return strings.Join(lines, "\n"), nil
+
+	var mine int
+	err = tx.QueryRow(ctx, `
+		SELECT pos FROM (
+			SELECT player_id, ROW_NUMBER() OVER (ORDER BY `+order+`) AS pos
+			FROM ranking_snapshots
+			WHERE season_id=$1
+		) r WHERE player_id=$2
+	`, p.SeasonID, p.ID).Scan(&mine)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return lines, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(lines, fmt.Sprintf("Your rank: %d of %d", mine, total)), nil
+}
+
+func corpRankingLines(ctx context.Context, tx pgx.Tx, p Player, page int) ([]string, error) {
+	var total int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(DISTINCT corp_id) FROM ranking_snapshots WHERE season_id=$1 AND corp_id <> ''", p.SeasonID).Scan(&total); err != nil {
+		return nil, err
+	}
+	lines := []string{fmt.Sprintf("Corporation rankings by net worth (%s) - page %d/%d", p.SeasonName, page, rankingPages(total))}
+
+	const corpTotals = `
+		SELECT corp_id, MAX(corp_name) AS corp_name, SUM(net_worth) AS net_worth, COUNT(1) AS members, SUM(planets) AS planets
+		FROM ranking_snapshots
+		WHERE season_id=$1 AND corp_id <> ''
+		GROUP BY corp_id`
+	rows, err := tx.Query(ctx, corpTotals+`
+		ORDER BY net_worth DESC, corp_name ASC
+		LIMIT $2 OFFSET $3
+	`, p.SeasonID, rankingsPageSize, (page-1)*rankingsPageSize)
+	if err != nil {
+		return nil, err
+	}
+	pos := (page - 1) * rankingsPageSize
+	for rows.Next() {
+		var id, name string
+		var netWorth, members, planets int64
+		if err := rows.Scan(&id, &name, &netWorth, &members, &planets); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		pos++
+		lines = append(lines, fmt.Sprintf("%d. %s - %d (%d members, %d planets)", pos, name, netWorth, members, planets))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if pos == (page-1)*rankingsPageSize {
+		lines = append(lines, "No entries on this page.")
+	}
+	if p.CorpID == "" {
+		return lines, nil
+	}
+
+	var mine int
+	err = tx.QueryRow(ctx, `
+		SELECT pos FROM (
+			SELECT corp_id, ROW_NUMBER() OVER (ORDER BY net_worth DESC, corp_name ASC) AS pos
+			FROM (`+corpTotals+`) t
+		) r WHERE corp_id=$2
+	`, p.SeasonID, p.CorpID).Scan(&mine)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return lines, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(lines, fmt.Sprintf("%s rank: %d of %d", p.CorpName, mine, total)), nil
 }
 
 func executeSeasonCommand(ctx context.Context, tx pgx.Tx, p Player) (string, error) {
-	var started time.Time
-	err := tx.QueryRow(ctx, "SELECT started_at FROM seasons WHERE id=$1", p.SeasonID).Scan(&started)
+	s, err := loadSeason(ctx, tx, p.SeasonID)
 	if err != nil {
 		return "", err
 	}
+	now := time.Now().UTC()
 	lines := []string{
-		fmt.Sprintf("Current season: %s (ID %d)", p.SeasonName, p.SeasonID),
-		fmt.Sprintf("Started: %s", started.UTC().Format(time.RFC3339)),
+		fmt.Sprintf("Current season: %s (ID %d)", s.Name, s.ID),
+		fmt.Sprintf("Started: %s", s.StartedAt.UTC().Format(time.RFC3339)),
+	}
+	if s.EndsAt != nil {
+		lines = append(lines, fmt.Sprintf("Ends: %s (in %s)", s.EndsAt.UTC().Format(time.RFC3339), formatDurationShort(s.EndsAt.Sub(now))))
+	} else {
+		lines = append(lines, "Ends: no scheduled end")
+	}
+
+	if rules := s.Rules.describe(); len(rules) > 0 {
+		lines = append(lines, "Rules: "+strings.Join(rules, ", "))
+	}
+
+	if s.FrozenAt != nil {
+		next := s.FrozenAt.Add(seasonFreezeWindow)
+		lines = append(lines,
+			fmt.Sprintf("Status: FROZEN (ended by %s) - winner: %s", s.EndReason, seasonWinnerLabel(s.WinnerName)),
+			fmt.Sprintf("Next season starts in %s.", formatDurationShort(next.Sub(now))),
+		)
+		return strings.Join(lines, "\n"), nil
+	}
+
+	if s.VictoryNetWorth > 0 {
+		leader, mine, updated, err := snapshotNetWorth(ctx, tx, s.ID, p.ID)
+		if err != nil {
+			return "", err
+		}
+		if updated == nil {
+			lines = append(lines, fmt.Sprintf("Victory: net worth %d credits - not ranked yet", s.VictoryNetWorth))
+		} else {
+			lines = append(lines, fmt.Sprintf("Victory: net worth %d credits - leader %s; you %d (%d%%), as of %s ago",
+				s.VictoryNetWorth, seasonLeaderLabel(leader), mine, seasonProgressPct(mine, s.VictoryNetWorth), formatAgeShort(now, *updated)))
+		}
+	}
+	if s.VictoryPlanets > 0 {
+		leader, err := seasonPlanetLeader(ctx, tx, s.ID)
+		if err != nil {
+			return "", err
+		}
+		var mine int64
+		if err := tx.QueryRow(ctx, "SELECT COUNT(1) FROM planets WHERE owner_player_id=$1", p.ID).Scan(&mine); err != nil {
+			return "", err
+		}
+		lines = append(lines, fmt.Sprintf("Victory: %d planets - leader %s; you %d (%d%%)",
+			s.VictoryPlanets, seasonLeaderLabel(leader), mine, seasonProgressPct(mine, int64(s.VictoryPlanets))))
+	}
+	if s.VictoryNetWorth == 0 && s.VictoryPlanets == 0 {
+		if s.EndsAt != nil {
+			lines = append(lines, "Victory: highest net worth when time runs out")
+		} else {
+			lines = append(lines, "Victory: none set")
+		}
 	}
 	return strings.Join(lines, "\n"), nil
 }
+
+func seasonWinnerLabel(name string) string {
+	if name == "" {
+		return "none"
+	}
+	return name
+}
+
+func seasonLeaderLabel(l seasonLeader) string {
+	if l.PlayerID == "" {
+		return "none"
+	}
+	return fmt.Sprintf("%s %d", l.Username, l.Value)
+}
+
+// seasonProgressPct is value as a percentage of target, capped at 100.
+func seasonProgressPct(value, target int64) int64 {
+	if target <= 0 || value <= 0 {
+		return 0
+	}
+	if value >= target {
+		return 100
+	}
+	return value * 100 / target
+}
@@ -270,7 +270,14 @@ func XPGainForCommand(cmd CommandRequest, cost int) int64 {
 
 	switch t {
 	case "SCAN":
-		return 10
+		switch a {
+		case "DENSITY":
+			return 15
+		case "HOLO":
+			return 30
+		default:
+			return 10
+		}
 	case "MOVE":
 		return 10
 	case "TRADE":
@@ -310,12 +317,49 @@ func XPGainForCommand(cmd CommandRequest, cost int) int64 {
 		default:
 			return 2
 		}
-	case "MARKET", "ROUTE", "EVENTS":
+	case "MARKET", "ROUTE", "EVENTS", "NEWS":
+		if a == "RUN" {
+			// ROUTE RUN awards XP for each move and trade it performs.
+			return 0
+		}
 		return 3
-	case "RANKINGS", "SEASON":
+	case "RANKINGS", "SEASON", "HALL":
 		return 1
 	case "HELP":
 		return 1
+	case "BANK":
+		switch a {
+		case "DEPOSIT", "WITHDRAW", "LOAN", "REPAY":
+			return 5
+		default:
+			return 1
+		}
+	case "STARDOCK", "FUEL":
+		if a == "BUY" {
+			return 5
+		}
+		return 1
+	case "BLACKMARKET":
+		switch a {
+		case "BUY", "SELL":
+			return 5
+		default:
+			return 1
+		}
+	case "GATE":
+		switch a {
+		case "BUILD":
+			return 200
+		case "DESTROY":
+			return 40
+		default:
+			return 1
+		}
+	case "BEACON":
+		if a == "SET" {
+			return 3
+		}
+		return 1
 	case "SHIPYARD":
 		switch a {
 		case "BUY":
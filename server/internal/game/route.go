@@ -21,6 +21,8 @@ type RouteSuggestion struct {
 
 	BuyScannedAt  time.Time
 	SellScannedAt time.Time
+	BuyScannedBy  string
+	SellScannedBy string
 
 	StepsToBuy     int
 	StepsBuyToSell int
@@ -30,6 +32,18 @@ type RouteSuggestion struct {
 	ProfitPerUnit  int
 	ProfitPerTrip  int64
 	ScoreX1        int64 // score used for selection (scaled)
+
+	Mode          string
+	PathToBuy     []int
+	PathBuyToSell []int
+	Exposure      HazardExposure
+}
+
+type RouteOptions struct {
+	Commodity string
+	Mode      string // FAST | SAFE | PROTECTED
+	Hazards   map[int]SectorHazard
+	Rules     SeasonRules // prices the expected losses (mine damage)
 }
 
 func executeRouteCommand(ctx context.Context, tx pgx.Tx, p Player, cmd CommandRequest) (string, error) {
@@ -37,8 +51,12 @@ func executeRouteCommand(ctx context.Context, tx pgx.Tx, p Player, cmd CommandRe
 	if filter != "" && filter != "ORE" && filter != "ORGANICS" && filter != "EQUIPMENT" {
 		filter = ""
 	}
+	mode, ok := normalizeRouteMode(cmd.Action)
+	if !ok {
+		return "Unknown route mode. Use ROUTE [FAST|SAFE|PROTECTED] [commodity].", nil
+	}
 
-	intel, err := LoadPortIntel(ctx, tx, p.ID)
+	intel, err := LoadPortIntel(ctx, tx, p.ID, p.CorpID)
 	if err != nil {
 		return "", err
 	}
@@ -56,8 +74,13 @@ func executeRouteCommand(ctx context.Context, tx pgx.Tx, p Player, cmd CommandRe
 		return "", err
 	}
 
+	hazards, err := LoadKnownHazards(ctx, tx, p.ID)
+	if err != nil {
+		return "", err
+	}
+
 	now := time.Now().UTC()
-	sug, ok := BestRouteSuggestion(now, p.SectorID, p.CargoMax, adj, intel, filter)
+	sug, ok := BestRouteSuggestionWithOptions(now, p.SectorID, p.CargoMax, adj, intel, RouteOptions{Commodity: filter, Mode: mode, Hazards: hazards, Rules: p.Rules})
 	if !ok {
 		if filter != "" {
 			return fmt.Sprintf("No profitable %s route found with current scanned intel.", filter), nil
@@ -65,28 +88,46 @@ func executeRouteCommand(ctx context.Context, tx pgx.Tx, p Player, cmd CommandRe
 		return "No profitable route found with current scanned intel.", nil
 	}
 
-	lines := make([]string, 0, 12)
-	lines = append(lines, "Route suggestion (uses your scanned intel only):")
+	lines := make([]string, 0, 14)
+	source := "your scanned intel only"
+	if p.CorpID != "" {
+		source = "scanned intel only, yours and any your corp shares"
+	}
+	lines = append(lines, fmt.Sprintf("Route suggestion (%s mode, uses %s):", sug.Mode, source))
 	lines = append(lines, fmt.Sprintf("Commodity: %s", sug.Commodity))
 	lines = append(lines, fmt.Sprintf("Step 1: Travel to Sector %d (%s) in %d move(s).", sug.BuySectorID, sug.BuySectorName, sug.StepsToBuy))
-	lines = append(lines, fmt.Sprintf("        Buy at %d credits/unit (scan age %s).", sug.BuyPrice, formatAgeShort(now, sug.BuyScannedAt)))
+	lines = append(lines, fmt.Sprintf("        Buy at %d credits/unit (scan age %s%s).", sug.BuyPrice, formatAgeShort(now, sug.BuyScannedAt), intelSource(p.Username, sug.BuyScannedBy)))
 	lines = append(lines, fmt.Sprintf("Step 2: Travel to Sector %d (%s) in %d move(s).", sug.SellSectorID, sug.SellSectorName, sug.StepsBuyToSell))
-	lines = append(lines, fmt.Sprintf("        Sell at %d credits/unit (scan age %s).", sug.SellPrice, formatAgeShort(now, sug.SellScannedAt)))
+	lines = append(lines, fmt.Sprintf("        Sell at %d credits/unit (scan age %s%s).", sug.SellPrice, formatAgeShort(now, sug.SellScannedAt), intelSource(p.Username, sug.SellScannedBy)))
+	lines = append(lines, fmt.Sprintf("Path: %s | %s", formatPath(sug.PathToBuy), formatPath(sug.PathBuyToSell)))
 	lines = append(lines, fmt.Sprintf("Spread: %d/unit | Qty assumed: %d | Profit/trip: %d credits", sug.ProfitPerUnit, sug.TradeQty, sug.ProfitPerTrip))
 	lines = append(lines, fmt.Sprintf("Moves: %d | Est. turns (moves + 2 trades): %d | Profit/turn: %.2f", sug.TotalMoves, sug.TotalTurns, float64(sug.ProfitPerTrip)/float64(max(1, sug.TotalTurns))))
+	lines = append(lines, fmt.Sprintf("Hazard exposure: %s", sug.Exposure.Summary()))
 
 	return strings.Join(lines, "\n"), nil
 }
 
+// BestRouteSuggestion picks the best FAST route, ignoring hazards.
 func BestRouteSuggestion(now time.Time, currentSector int, cargoMax int, adjacency map[int][]int, intel []PortIntel, commodityFilter string) (RouteSuggestion, bool) {
+	return BestRouteSuggestionWithOptions(now, currentSector, cargoMax, adjacency, intel, RouteOptions{Commodity: commodityFilter, Mode: RouteModeFast})
+}
+
+// BestRouteSuggestionWithOptions picks the best route for the given mode.
+// FAST paths minimize moves; SAFE and PROTECTED paths detour around known hazards and
+// score routes by profit net of the expected hazard losses.
+func BestRouteSuggestionWithOptions(now time.Time, currentSector int, cargoMax int, adjacency map[int][]int, intel []PortIntel, opts RouteOptions) (RouteSuggestion, bool) {
 	if cargoMax < 1 {
 		cargoMax = 1
 	}
-	distFromCurrent := bfsDistances(currentSector, adjacency)
+	routeMode, ok := normalizeRouteMode(opts.Mode)
+	if !ok {
+		routeMode = RouteModeFast
+	}
+	_, prevFromCurrent := shortestPaths(currentSector, adjacency, opts.Hazards, routeMode)
 
 	commodities := []string{"ORE", "ORGANICS", "EQUIPMENT"}
-	if commodityFilter != "" {
-		commodities = []string{commodityFilter}
+	if opts.Commodity != "" {
+		commodities = []string{opts.Commodity}
 	}
 
 	best := RouteSuggestion{}
@@ -98,6 +139,7 @@ func BestRouteSuggestion(now time.Time, currentSector int, cargoMax int, adjacen
 		Price      int
 		MaxQty     int
 		ScannedAt  time.Time
+		ScannedBy  string
 	}
 
 	for _, comm := range commodities {
@@ -113,13 +155,13 @@ func BestRouteSuggestion(now time.Time, currentSector int, cargoMax int, adjacen
 			if mode == "SELL" {
 				maxQty := qty
 				if maxQty > 0 {
-					buys = append(buys, quote{SectorID: pi.SectorID, SectorName: pi.SectorName, Price: price, MaxQty: maxQty, ScannedAt: pi.ScannedAt})
+					buys = append(buys, quote{SectorID: pi.SectorID, SectorName: pi.SectorName, Price: price, MaxQty: maxQty, ScannedAt: pi.ScannedAt, ScannedBy: pi.ScannedBy})
 				}
 			}
 			if mode == "BUY" {
 				demand := baseQty - qty
 				if demand > 0 {
-					sells = append(sells, quote{SectorID: pi.SectorID, SectorName: pi.SectorName, Price: price, MaxQty: demand, ScannedAt: pi.ScannedAt})
+					sells = append(sells, quote{SectorID: pi.SectorID, SectorName: pi.SectorName, Price: price, MaxQty: demand, ScannedAt: pi.ScannedAt, ScannedBy: pi.ScannedBy})
 				}
 			}
 		}
@@ -129,21 +171,24 @@ func BestRouteSuggestion(now time.Time, currentSector int, cargoMax int, adjacen
 		}
 
 		for _, b := range buys {
-			toBuy, ok := distFromCurrent[b.SectorID]
-			if !ok {
+			pathToBuy := pathTo(prevFromCurrent, currentSector, b.SectorID)
+			if pathToBuy == nil {
 				continue
 			}
+			toBuy := len(pathToBuy) - 1
+			exposureToBuy := pathExposure(pathToBuy, opts.Hazards, opts.Rules)
 
-			distFromBuy := bfsDistances(b.SectorID, adjacency)
+			_, prevFromBuy := shortestPaths(b.SectorID, adjacency, opts.Hazards, routeMode)
 
 			for _, s := range sells {
 				if s.SectorID == b.SectorID {
 					continue
 				}
-				toSell, ok := distFromBuy[s.SectorID]
-				if !ok {
+				pathToSell := pathTo(prevFromBuy, b.SectorID, s.SectorID)
+				if pathToSell == nil {
 					continue
 				}
+				toSell := len(pathToSell) - 1
 				profitPerUnit := s.Price - b.Price
 				if profitPerUnit <= 0 {
 					continue
@@ -166,8 +211,16 @@ func BestRouteSuggestion(now time.Time, currentSector int, cargoMax int, adjacen
 					totalTurns = 1
 				}
 
+				exposure := exposureToBuy.Add(pathExposure(pathToSell, opts.Hazards, opts.Rules))
 				profitTrip := int64(profitPerUnit) * int64(tradeQty)
-				baseScore := (profitTrip * 1000) / int64(totalTurns)
+				scoredProfit := profitTrip
+				if routeMode != RouteModeFast {
+					scoredProfit -= exposure.ExpectedLoss
+					if scoredProfit <= 0 {
+						continue
+					}
+				}
+				baseScore := (scoredProfit * 1000) / int64(totalTurns)
 				ageBuy := now.Sub(b.ScannedAt)
 				ageSell := now.Sub(s.ScannedAt)
 				maxAge := ageBuy
@@ -188,6 +241,8 @@ func BestRouteSuggestion(now time.Time, currentSector int, cargoMax int, adjacen
 						SellPrice:      s.Price,
 						BuyScannedAt:   b.ScannedAt,
 						SellScannedAt:  s.ScannedAt,
+						BuyScannedBy:   b.ScannedBy,
+						SellScannedBy:  s.ScannedBy,
 						StepsToBuy:     toBuy,
 						StepsBuyToSell: toSell,
 						TotalMoves:     totalMoves,
@@ -196,6 +251,10 @@ func BestRouteSuggestion(now time.Time, currentSector int, cargoMax int, adjacen
 						ProfitPerUnit:  profitPerUnit,
 						ProfitPerTrip:  profitTrip,
 						ScoreX1:        weighted,
+						Mode:           routeMode,
+						PathToBuy:      pathToBuy,
+						PathBuyToSell:  pathToSell,
+						Exposure:       exposure,
 					}
 				}
 			}
@@ -237,25 +296,6 @@ func extractCommodity(pi PortIntel, commodity string) (mode string, price int, q
 	}
 }
 
-func bfsDistances(start int, adjacency map[int][]int) map[int]int {
-	dist := map[int]int{start: 0}
-	queue := make([]int, 0, 64)
-	queue = append(queue, start)
-
-	for len(queue) > 0 {
-		n := queue[0]
-		queue = queue[1:]
-		for _, next := range adjacency[n] {
-			if _, ok := dist[next]; ok {
-				continue
-			}
-			dist[next] = dist[n] + 1
-			queue = append(queue, next)
-		}
-	}
-	return dist
-}
-
 func loadDiscoveredSectors(ctx context.Context, tx pgx.Tx, playerID string) (map[int]bool, error) {
 	rows, err := tx.Query(ctx, `SELECT sector_id FROM player_discoveries WHERE player_id=$1`, playerID)
 	if err != nil {
@@ -278,7 +318,7 @@ func loadDiscoveredSectors(ctx context.Context, tx pgx.Tx, playerID string) (map
 }
 
 func loadDiscoveredAdjacency(ctx context.Context, tx pgx.Tx, discovered map[int]bool) (map[int][]int, error) {
-	rows, err := tx.Query(ctx, `SELECT from_sector, to_sector FROM warps`)
+	rows, err := tx.Query(ctx, `SELECT from_sector, to_sector FROM warps ORDER BY from_sector, to_sector`)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,386 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	routeRunDefaultTrips = 1
+	routeRunMaxTrips     = 10
+)
+
+// routeRun carries a ROUTE RUN autopilot through its steps and collects the report.
+type routeRun struct {
+	ctx context.Context
+	tx  pgx.Tx
+	p   *Player
+
+	mode      string
+	commodity string
+	buyID     int
+	sellID    int
+
+	lines        []string
+	logs         []logToInsert
+	turnsUsed    int
+	tripsDone    int
+	creditsStart int64
+}
+
+func (r *routeRun) note(kind, msg string) {
+	r.lines = append(r.lines, msg)
+	r.logs = append(r.logs, logToInsert{kind: kind, msg: msg})
+}
+
+// spend deducts the turn cost of an autopilot step, returning false when the player is out of turns.
+func (r *routeRun) spend(step CommandRequest) (bool, error) {
+	cost, ok, err := r.afford(step)
+	if err != nil || !ok {
+		return false, err
+	}
+	r.charge(step, cost)
+	return true, nil
+}
+
+// afford returns the turn cost of step and whether the player can pay it.
+func (r *routeRun) afford(step CommandRequest) (int, bool, error) {
+	cost, err := commandTurnCost(r.ctx, r.tx, *r.p, step)
+	if err != nil {
+		return 0, false, err
+	}
+	return cost, r.p.Turns >= cost, nil
+}
+
+// charge deducts cost turns for step and awards its XP.
+func (r *routeRun) charge(step CommandRequest, cost int) {
+	r.p.Turns -= cost
+	r.turnsUsed += cost
+
+	leveled, _, newLevel := AwardXP(r.p, XPGainForCommand(step, cost))
+	if leveled {
+		r.note("SYSTEM", fmt.Sprintf("Rank up! Level %d (%s).", newLevel, RankNameForLevel(newLevel)))
+	}
+}
+
+// travel moves the player to goal along the planned path. It returns a stop reason when the
+// trip cannot continue.
+func (r *routeRun) travel(goal int) (string, error) {
+	if r.p.SectorID == goal {
+		return "", nil
+	}
+	discovered, err := loadDiscoveredSectors(r.ctx, r.tx, r.p.ID)
+	if err != nil {
+		return "", err
+	}
+	adj, err := loadDiscoveredAdjacency(r.ctx, r.tx, discovered)
+	if err != nil {
+		return "", err
+	}
+	hazards, err := LoadKnownHazards(r.ctx, r.tx, r.p.ID)
+	if err != nil {
+		return "", err
+	}
+	path, ok := PlanPath(r.p.SectorID, goal, adj, hazards, r.mode)
+	if !ok {
+		return fmt.Sprintf("no known path to sector %d", goal), nil
+	}
+
+	for _, next := range path[1:] {
+		gate, toll, err := gateTollDue(r.ctx, r.tx, *r.p, r.p.SectorID, next)
+		if err != nil {
+			return "", err
+		}
+		if stop := routeTollStop(r.p.Credits, toll, next); stop != "" {
+			return stop, nil
+		}
+		ok, err := r.spend(CommandRequest{Type: "MOVE", To: next})
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "out of turns", nil
+		}
+		tollMsg, err := payGateToll(r.ctx, r.tx, r.p, gate, toll)
+		if err != nil {
+			return "", err
+		}
+		if tollMsg != "" {
+			r.note("ACTION", tollMsg)
+		}
+		r.p.SectorID = next
+		r.note("ACTION", fmt.Sprintf("Moved to sector %d.", next))
+
+		arrival, err := enterSector(r.ctx, r.tx, r.p)
+		if err != nil {
+			return "", err
+		}
+		r.lines = append(r.lines, arrival.Messages...)
+		r.logs = append(r.logs, arrival.Logs...)
+		if arrival.Hostile {
+			return fmt.Sprintf("hostile action in sector %d", next), nil
+		}
+	}
+	return "", nil
+}
+
+// trade runs one BUY or SELL at the current port after re-checking the live price against limit.
+// It returns the unit price traded at. For BUY, the live price must stay below limit; for SELL, above it.
+func (r *routeRun) trade(action string, limit int) (int, string, error) {
+	if err := CaptureScanIntel(r.ctx, r.tx, r.p.ID, r.p.SectorID); err != nil {
+		return 0, "", err
+	}
+	q, ok, err := loadLiveQuote(r.ctx, r.tx, r.p.SectorID, r.commodity)
+	if err != nil {
+		return 0, "", err
+	}
+	if !ok {
+		return 0, fmt.Sprintf("no port in sector %d", r.p.SectorID), nil
+	}
+	step, stop := planRouteTrade(*r.p, r.commodity, action, q, limit)
+	if stop != "" {
+		return 0, stop, nil
+	}
+	// Check the turns before moving goods; like a manual TRADE, a refused trade costs nothing.
+	cost, ok, err := r.afford(step)
+	if err != nil {
+		return 0, "", err
+	}
+	if !ok {
+		return 0, "out of turns", nil
+	}
+	msg, traded, err := executeTrade(r.ctx, r.tx, r.p, step)
+	if err != nil {
+		return 0, "", err
+	}
+	if !traded {
+		return 0, strings.TrimSuffix(msg, "."), nil
+	}
+	r.charge(step, cost)
+	r.note("ACTION", msg)
+	return q.Price, "", nil
+}
+
+// loop runs up to trips buy/sell cycles and returns the reason it stopped early, if any.
+// sellPrice is the last known sale price; the buy leg is skipped when the live buy price no longer beats it.
+func (r *routeRun) loop(trips int, sellPrice int) (string, error) {
+	for r.tripsDone < trips {
+		if stop, err := r.travel(r.buyID); err != nil || stop != "" {
+			return stop, err
+		}
+		paid, stop, err := r.trade("BUY", sellPrice)
+		if err != nil || stop != "" {
+			return stop, err
+		}
+
+		if stop, err := r.travel(r.sellID); err != nil || stop != "" {
+			return stop, err
+		}
+		sold, stop, err := r.trade("SELL", paid)
+		if err != nil || stop != "" {
+			return stop, err
+		}
+		sellPrice = sold
+		r.tripsDone++
+	}
+	return "", nil
+}
+
+// planRouteTrade sizes one autopilot BUY or SELL against a live quote. It returns the trade step,
+// or the reason the run has to stop: the port no longer trades the commodity, the margin is gone,
+// there is nothing to trade, or the player cannot pay the turn.
+func planRouteTrade(p Player, commodity, action string, q liveQuote, limit int) (CommandRequest, string) {
+	qty := 0
+	switch action {
+	case "BUY":
+		if q.Mode != "SELL" {
+			return CommandRequest{}, fmt.Sprintf("sector %d no longer sells %s", p.SectorID, strings.ToLower(commodity))
+		}
+		if q.Price >= limit {
+			return CommandRequest{}, fmt.Sprintf("margin gone (buy %d vs sell %d)", q.Price, limit)
+		}
+		qty = p.CargoMax - totalCargo(&p)
+		if q.Qty < qty {
+			qty = q.Qty
+		}
+		if affordable := int(p.Credits / int64(max(1, q.Price))); affordable < qty {
+			qty = affordable
+		}
+	case "SELL":
+		if q.Mode != "BUY" {
+			return CommandRequest{}, fmt.Sprintf("sector %d no longer buys %s", p.SectorID, strings.ToLower(commodity))
+		}
+		if q.Price <= limit {
+			return CommandRequest{}, fmt.Sprintf("margin gone (sell %d vs paid %d)", q.Price, limit)
+		}
+		qty = cargoOf(&p, commodity)
+		if demand := q.BaseQty - q.Qty; demand < qty {
+			qty = demand
+		}
+	}
+	if qty < 1 {
+		if action == "BUY" {
+			return CommandRequest{}, "no cargo space, stock or credits to buy"
+		}
+		return CommandRequest{}, "port demand is saturated"
+	}
+
+	step := CommandRequest{Type: "TRADE", Action: action, Commodity: commodity, Quantity: qty}
+	if effectiveCommandCost(p, step) > p.Turns {
+		return CommandRequest{}, "out of turns"
+	}
+	return step, ""
+}
+
+// routeTollStop returns the stop reason when the player cannot cover the gate toll into next.
+func routeTollStop(credits, toll int64, next int) string {
+	if toll > credits {
+		return fmt.Sprintf("cannot pay the %d credit gate toll into sector %d", toll, next)
+	}
+	return ""
+}
+
+// routeRunTrips applies the default and the per-run cap to a requested trip count.
+func routeRunTrips(requested int) (int, bool) {
+	if requested < 1 {
+		return routeRunDefaultTrips, true
+	}
+	return requested, requested <= routeRunMaxTrips
+}
+
+func executeRouteRun(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRequest) (phase2Result, error) {
+	filter := strings.ToUpper(strings.TrimSpace(cmd.Commodity))
+	if filter != "" && filter != "ORE" && filter != "ORGANICS" && filter != "EQUIPMENT" {
+		return phase2Result{OK: false, Message: "Commodity must be ORE, ORGANICS, or EQUIPMENT.", ErrorCode: "INVALID_COMMODITY"}, nil
+	}
+	mode, ok := normalizeRouteMode(cmd.Name)
+	if !ok {
+		return phase2Result{OK: false, Message: "Unknown route mode. Use FAST, SAFE or PROTECTED.", ErrorCode: "INVALID_ARGS"}, nil
+	}
+	trips, ok := routeRunTrips(cmd.Quantity)
+	if !ok {
+		return phase2Result{OK: false, Message: fmt.Sprintf("Too many trips (max %d per run).", routeRunMaxTrips), ErrorCode: "INVALID_QTY"}, nil
+	}
+
+	intel, err := LoadPortIntel(ctx, tx, p.ID, p.CorpID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	discovered, err := loadDiscoveredSectors(ctx, tx, p.ID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	adj, err := loadDiscoveredAdjacency(ctx, tx, discovered)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	hazards, err := LoadKnownHazards(ctx, tx, p.ID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	sug, ok := BestRouteSuggestionWithOptions(time.Now().UTC(), p.SectorID, p.CargoMax, adj, intel, RouteOptions{Commodity: filter, Mode: mode, Hazards: hazards, Rules: p.Rules})
+	if !ok {
+		return phase2Result{OK: false, Message: "No profitable route found with current scanned intel.", ErrorCode: "NO_ROUTE"}, nil
+	}
+
+	r := &routeRun{
+		ctx:          ctx,
+		tx:           tx,
+		p:            p,
+		mode:         sug.Mode,
+		commodity:    sug.Commodity,
+		buyID:        sug.BuySectorID,
+		sellID:       sug.SellSectorID,
+		creditsStart: p.Credits,
+	}
+	r.lines = append(r.lines, fmt.Sprintf("Route run (%s): %s from Sector %d to Sector %d, %d trip(s).", r.mode, r.commodity, r.buyID, r.sellID, trips))
+
+	stop, err := r.loop(trips, sug.SellPrice)
+	if err != nil {
+		return phase2Result{}, err
+	}
+
+	profit := p.Credits - r.creditsStart
+	summary := fmt.Sprintf("Route run complete: %d/%d trip(s), net %+d credits, %d turn(s) used.", r.tripsDone, trips, profit, r.turnsUsed)
+	if stop != "" {
+		summary = fmt.Sprintf("Route run stopped (%s): %d/%d trip(s), net %+d credits, %d turn(s) used.", stop, r.tripsDone, trips, profit, r.turnsUsed)
+	}
+	r.lines = append(r.lines, summary)
+	r.logs = append(r.logs, logToInsert{kind: "SYSTEM", msg: summary})
+
+	return phase2Result{OK: true, Message: strings.Join(r.lines, "\n"), Logs: r.logs}, nil
+}
+
+type liveQuote struct {
+	Mode    string
+	Price   int
+	Qty     int
+	BaseQty int
+}
+
+// loadLiveQuote reads the current port price for one commodity, including active event modifiers.
+func loadLiveQuote(ctx context.Context, tx pgx.Tx, sectorID int, commodity string) (liveQuote, bool, error) {
+	var port portForUpdate
+	err := tx.QueryRow(ctx, `
+		SELECT
+			ore_mode, ore_qty, ore_base_qty, ore_base_price,
+			organics_mode, organics_qty, organics_base_qty, organics_base_price,
+			equipment_mode, equipment_qty, equipment_base_qty, equipment_base_price
+		FROM ports
+		WHERE sector_id = $1
+	`, sectorID).Scan(
+		&port.OreMode, &port.OreQty, &port.OreBaseQty, &port.OreBasePrice,
+		&port.OrganicsMode, &port.OrganicsQty, &port.OrganicsBaseQty, &port.OrganicsBasePrice,
+		&port.EquipmentMode, &port.EquipmentQty, &port.EquipmentBaseQty, &port.EquipmentBasePrice,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return liveQuote{}, false, nil
+	}
+	if err != nil {
+		return liveQuote{}, false, err
+	}
+
+	pct := 100
+	if ev, ok, err := LoadActiveEvent(ctx, tx, sectorID); err != nil {
+		return liveQuote{}, false, err
+	} else if ok {
+		pct = pricePercentForCommodity(ev, commodity)
+	}
+
+	var q liveQuote
+	var basePrice int
+	switch commodity {
+	case "ORE":
+		q = liveQuote{Mode: port.OreMode, Qty: port.OreQty, BaseQty: port.OreBaseQty}
+		basePrice = port.OreBasePrice
+	case "ORGANICS":
+		q = liveQuote{Mode: port.OrganicsMode, Qty: port.OrganicsQty, BaseQty: port.OrganicsBaseQty}
+		basePrice = port.OrganicsBasePrice
+	case "EQUIPMENT":
+		q = liveQuote{Mode: port.EquipmentMode, Qty: port.EquipmentQty, BaseQty: port.EquipmentBaseQty}
+		basePrice = port.EquipmentBasePrice
+	default:
+		return liveQuote{}, false, nil
+	}
+	q.Mode = strings.ToUpper(q.Mode)
+	q.Price = PricePerUnitWithPercent(basePrice, q.BaseQty, q.Qty, pct)
+	return q, true, nil
+}
+
+func cargoOf(p *Player, commodity string) int {
+	switch commodity {
+	case "ORE":
+		return p.CargoOre
+	case "ORGANICS":
+		return p.CargoOrganics
+	case "EQUIPMENT":
+		return p.CargoEquipment
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,80 @@
+package game
+
+import "testing"
+
+func TestPlanRouteTrade(t *testing.T) {
+	trader := Player{SectorID: 7, Credits: 1000, Turns: 10, CargoMax: 50}
+	loaded := trader
+	loaded.CargoOre = 30
+	broke := trader
+	broke.Credits = 40
+	tired := trader
+	tired.Turns = 0
+
+	cases := []struct {
+		name   string
+		p      Player
+		action string
+		q      liveQuote
+		limit  int
+		qty    int
+		stop   string
+	}{
+		{name: "buy fills hold", p: trader, action: "BUY", q: liveQuote{Mode: "SELL", Price: 10, Qty: 500, BaseQty: 500}, limit: 15, qty: 50},
+		{name: "buy limited by stock", p: trader, action: "BUY", q: liveQuote{Mode: "SELL", Price: 10, Qty: 20, BaseQty: 500}, limit: 15, qty: 20},
+		{name: "buy limited by credits", p: broke, action: "BUY", q: liveQuote{Mode: "SELL", Price: 10, Qty: 500, BaseQty: 500}, limit: 15, qty: 4},
+		{name: "buy margin gone", p: trader, action: "BUY", q: liveQuote{Mode: "SELL", Price: 15, Qty: 500, BaseQty: 500}, limit: 15, stop: "margin gone (buy 15 vs sell 15)"},
+		{name: "buy port flipped", p: trader, action: "BUY", q: liveQuote{Mode: "BUY", Price: 10, Qty: 500, BaseQty: 500}, limit: 15, stop: "sector 7 no longer sells ore"},
+		{name: "buy with no credits", p: Player{SectorID: 7, Turns: 10, CargoMax: 50}, action: "BUY", q: liveQuote{Mode: "SELL", Price: 10, Qty: 500, BaseQty: 500}, limit: 15, stop: "no cargo space, stock or credits to buy"},
+		{name: "buy out of turns", p: tired, action: "BUY", q: liveQuote{Mode: "SELL", Price: 10, Qty: 500, BaseQty: 500}, limit: 15, stop: "out of turns"},
+		{name: "sell whole cargo", p: loaded, action: "SELL", q: liveQuote{Mode: "BUY", Price: 20, Qty: 0, BaseQty: 500}, limit: 10, qty: 30},
+		{name: "sell limited by demand", p: loaded, action: "SELL", q: liveQuote{Mode: "BUY", Price: 20, Qty: 480, BaseQty: 500}, limit: 10, qty: 20},
+		{name: "sell margin gone", p: loaded, action: "SELL", q: liveQuote{Mode: "BUY", Price: 10, Qty: 0, BaseQty: 500}, limit: 10, stop: "margin gone (sell 10 vs paid 10)"},
+		{name: "sell demand saturated", p: loaded, action: "SELL", q: liveQuote{Mode: "BUY", Price: 20, Qty: 500, BaseQty: 500}, limit: 10, stop: "port demand is saturated"},
+	}
+	for _, c := range cases {
+		step, stop := planRouteTrade(c.p, "ORE", c.action, c.q, c.limit)
+		if stop != c.stop {
+			t.Errorf("%s: stop = %q, want %q", c.name, stop, c.stop)
+			continue
+		}
+		if stop == "" && (step.Type != "TRADE" || step.Action != c.action || step.Commodity != "ORE" || step.Quantity != c.qty) {
+			t.Errorf("%s: step = %+v, want %s %d ORE", c.name, step, c.action, c.qty)
+		}
+	}
+}
+
+func TestRouteTollStop(t *testing.T) {
+	cases := []struct {
+		credits, toll int64
+		stop          string
+	}{
+		{credits: 500, toll: 0},
+		{credits: 500, toll: 500},
+		{credits: 499, toll: 500, stop: "cannot pay the 500 credit gate toll into sector 9"},
+	}
+	for _, c := range cases {
+		if got := routeTollStop(c.credits, c.toll, 9); got != c.stop {
+			t.Errorf("routeTollStop(%d, %d) = %q, want %q", c.credits, c.toll, got, c.stop)
+		}
+	}
+}
+
+func TestRouteRunTrips(t *testing.T) {
+	cases := []struct {
+		requested, want int
+		ok              bool
+	}{
+		{0, routeRunDefaultTrips, true},
+		{-3, routeRunDefaultTrips, true},
+		{4, 4, true},
+		{routeRunMaxTrips, routeRunMaxTrips, true},
+		{routeRunMaxTrips + 1, routeRunMaxTrips + 1, false},
+	}
+	for _, c := range cases {
+		got, ok := routeRunTrips(c.requested)
+		if got != c.want || ok != c.ok {
+			t.Errorf("routeRunTrips(%d) = %d, %v; want %d, %v", c.requested, got, ok, c.want, c.ok)
+		}
+	}
+}
@@ -70,3 +70,70 @@ func TestBestRouteSuggestionPenalizesStaleIntel(t *testing.T) {
 		t.Fatalf("expected fresh route buy 3 sell 5, got buy %d sell %d", sug.BuySectorID, sug.SellSectorID)
 	}
 }
+
+func TestBestRouteSuggestionSafeModeAvoidsKnownHazards(t *testing.T) {
+	now := time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC)
+
+	// Two ways from 2 to 5: the short way through mined sector 3, or the long way via 6 and 7.
+	adj := map[int][]int{
+		1: {2},
+		2: {1, 3, 6},
+		3: {2, 5},
+		5: {3, 7},
+		6: {2, 7},
+		7: {6, 5},
+	}
+	hazards := map[int]SectorHazard{
+		3: {HostileMines: 4},
+	}
+
+	intel := []PortIntel{
+		{SectorID: 2, SectorName: "S2", ScannedAt: now, OreMode: "SELL", OrePrice: 5, OreQty: 100, OreBaseQty: 100},
+		{SectorID: 5, SectorName: "S5", ScannedAt: now, OreMode: "BUY", OrePrice: 40, OreQty: 0, OreBaseQty: 100},
+	}
+
+	fast, ok := BestRouteSuggestionWithOptions(now, 1, 10, adj, intel, RouteOptions{Commodity: "ORE", Mode: RouteModeFast, Hazards: hazards})
+	if !ok {
+		t.Fatalf("expected fast route")
+	}
+	if got := formatPath(fast.PathBuyToSell); got != "2 > 3 > 5" {
+		t.Fatalf("fast path: got %q", got)
+	}
+	if fast.Exposure.MineSectors != 1 || fast.Exposure.ExpectedLoss != mineDamageCredits(4) {
+		t.Fatalf("fast exposure: got %+v", fast.Exposure)
+	}
+
+	safe, ok := BestRouteSuggestionWithOptions(now, 1, 10, adj, intel, RouteOptions{Commodity: "ORE", Mode: RouteModeSafe, Hazards: hazards})
+	if !ok {
+		t.Fatalf("expected safe route")
+	}
+	if got := formatPath(safe.PathBuyToSell); got != "2 > 6 > 7 > 5" {
+		t.Fatalf("safe path: got %q", got)
+	}
+	if safe.Exposure.MineSectors != 0 || safe.Exposure.ExpectedLoss != 0 {
+		t.Fatalf("safe exposure: got %+v", safe.Exposure)
+	}
+	if safe.TotalMoves != 4 {
+		t.Fatalf("safe moves: got %d want 4", safe.TotalMoves)
+	}
+}
+
+func TestPlanPathProtectedPrefersProtectorateSpace(t *testing.T) {
+	adj := map[int][]int{
+		1: {2, 3},
+		2: {4},
+		3: {4},
+	}
+	hazards := map[int]SectorHazard{
+		3: {IsProtectorate: true},
+		4: {IsProtectorate: true},
+	}
+
+	path, ok := PlanPath(1, 4, adj, hazards, RouteModeProtected)
+	if !ok {
+		t.Fatalf("expected path")
+	}
+	if got := formatPath(path); got != "1 > 3 > 4" {
+		t.Fatalf("protected path: got %q", got)
+	}
+}
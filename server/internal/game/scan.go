@@ -0,0 +1,191 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Turn costs of the long-range scans. SCOUT hulls are built for it and pay less.
+const (
+	scanDensityCost      = 2
+	scanDensityScoutCost = 1
+	scanHoloCost         = 5
+	scanHoloScoutCost    = 3
+)
+
+// scanTurnCost is the turn cost of SCAN {action} for the given hull.
+func scanTurnCost(action, shipType string) int {
+	scout := strings.EqualFold(strings.TrimSpace(shipType), "SCOUT")
+	switch strings.ToUpper(strings.TrimSpace(action)) {
+	case "DENSITY":
+		if scout {
+			return scanDensityScoutCost
+		}
+		return scanDensityCost
+	case "HOLO":
+		if scout {
+			return scanHoloScoutCost
+		}
+		return scanHoloCost
+	default:
+		return 1
+	}
+}
+
+// densityIndex is a rough presence reading for a sector: ships weigh most, then a planet,
+// then mines in blocks of five.
+func densityIndex(ships, mines int, planet bool) int {
+	idx := ships*10 + (mines+4)/5
+	if planet {
+		idx += 15
+	}
+	return idx
+}
+
+func densityLabel(idx int) string {
+	switch {
+	case idx <= 0:
+		return "empty"
+	case idx < 20:
+		return "low"
+	case idx < 50:
+		return "moderate"
+	default:
+		return "high"
+	}
+}
+
+// executeScanCommand runs the long-range scans. Plain SCAN is handled by the engine.
+func executeScanCommand(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRequest) (phase2Result, error) {
+	switch strings.ToUpper(strings.TrimSpace(cmd.Action)) {
+	case "DENSITY":
+		return scanDensity(ctx, tx, *p)
+	case "HOLO":
+		if !p.ShipScanner {
+			msg := fmt.Sprintf("SCAN HOLO needs a scanner upgrade (SHIPYARD UPGRADE SCANNER, %d credits).", scannerPrice)
+			return phase2Result{OK: false, Message: msg, ErrorCode: "NO_SCANNER"}, nil
+		}
+		return scanHolo(ctx, tx, *p)
+	default:
+		return phase2Result{OK: false, Message: "Unknown SCAN subcommand. Use SCAN, SCAN DENSITY or SCAN HOLO.", ErrorCode: "UNKNOWN_SUBCOMMAND"}, nil
+	}
+}
+
+// scanDensity reports a presence index for each adjacent warp. Nebulae return no reading.
+func scanDensity(ctx context.Context, tx pgx.Tx, p Player) (phase2Result, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT
+			w.to_sector,
+			s.environment,
+			(SELECT COUNT(*) FROM players pl WHERE pl.sector_id = w.to_sector AND pl.id <> $2),
+			(SELECT COALESCE(SUM(m.qty),0) FROM mines m WHERE m.sector_id = w.to_sector),
+			EXISTS(SELECT 1 FROM planets pn WHERE pn.sector_id = w.to_sector)
+		FROM warps w
+		JOIN sectors s ON s.id = w.to_sector
+		WHERE w.from_sector = $1
+		ORDER BY w.to_sector
+	`, p.SectorID, p.ID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	defer rows.Close()
+
+	lines := []string{fmt.Sprintf("Density scan from sector %d:", p.SectorID)}
+	for rows.Next() {
+		var id, ships, mines int
+		var env string
+		var planet bool
+		if err := rows.Scan(&id, &env, &ships, &mines, &planet); err != nil {
+			return phase2Result{}, err
+		}
+		if env == EnvNebula {
+			lines = append(lines, fmt.Sprintf("- Sector %d: no reading (nebula)", id))
+			continue
+		}
+		idx := densityIndex(ships, mines, planet)
+		lines = append(lines, fmt.Sprintf("- Sector %d: %d (%s)", id, idx, densityLabel(idx)))
+	}
+	if err := rows.Err(); err != nil {
+		return phase2Result{}, err
+	}
+	if len(lines) == 1 {
+		lines = append(lines, "- No warps to scan.")
+	}
+
+	msg := strings.Join(lines, "\n")
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: fmt.Sprintf("Density scan from sector %d.", p.SectorID)}}}, nil
+}
+
+// scanHolo fully scans every adjacent sector: it is discovered, and port and hazard intel is
+// captured as if the player had run SCAN there (nebulae still block the capture).
+func scanHolo(ctx context.Context, tx pgx.Tx, p Player) (phase2Result, error) {
+	rows, err := tx.Query(ctx, "SELECT to_sector FROM warps WHERE from_sector = $1 ORDER BY to_sector", p.SectorID)
+	if err != nil {
+		return phase2Result{}, err
+	}
+	var adjacent []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return phase2Result{}, err
+		}
+		adjacent = append(adjacent, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return phase2Result{}, err
+	}
+
+	lines := []string{fmt.Sprintf("Holo scan from sector %d:", p.SectorID)}
+	for _, id := range adjacent {
+		if err := MarkDiscovered(ctx, tx, p.ID, id); err != nil {
+			return phase2Result{}, err
+		}
+		if err := CaptureScanIntel(ctx, tx, p.ID, id); err != nil {
+			return phase2Result{}, err
+		}
+		if err := RecordHazardIntel(ctx, tx, p, id); err != nil {
+			return phase2Result{}, err
+		}
+		s, err := LoadSectorView(ctx, tx, id)
+		if err != nil {
+			return phase2Result{}, err
+		}
+		lines = append(lines, holoSummary(s))
+	}
+	if len(adjacent) == 0 {
+		lines = append(lines, "- No warps to scan.")
+	}
+
+	msg := strings.Join(lines, "\n")
+	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: fmt.Sprintf("Holo scan of %d adjacent sector(s) from sector %d.", len(adjacent), p.SectorID)}}}, nil
+}
+
+func holoSummary(s SectorView) string {
+	parts := []string{}
+	if s.Environment != "" && s.Environment != EnvNormal {
+		parts = append(parts, s.Environment)
+	}
+	if s.IsProtectorate {
+		parts = append(parts, "Protectorate")
+	}
+	if s.Port != nil {
+		parts = append(parts, fmt.Sprintf("port %s (%s)", s.Port.Name, s.Port.Class))
+	}
+	if s.Planet != nil {
+		parts = append(parts, "planet "+s.Planet.Name)
+	}
+	if s.MinesHidden {
+		parts = append(parts, "mines unknown")
+	} else if s.Mines > 0 {
+		parts = append(parts, fmt.Sprintf("%d mines", s.Mines))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "nothing of note")
+	}
+	return fmt.Sprintf("- Sector %d %s: %s", s.ID, s.Name, strings.Join(parts, ", "))
+}
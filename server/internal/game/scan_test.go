@@ -0,0 +1,46 @@
+package game
+
+import "testing"
+
+func TestScanTurnCostFavorsScouts(t *testing.T) {
+	trader := Player{ShipType: "TRADER"}
+	scout := Player{ShipType: "SCOUT"}
+
+	if got := effectiveCommandCost(trader, CommandRequest{Type: "SCAN"}); got != 1 {
+		t.Fatalf("plain scan: got %d want 1", got)
+	}
+	if got := effectiveCommandCost(trader, CommandRequest{Type: "SCAN", Action: "HOLO"}); got != scanHoloCost {
+		t.Fatalf("trader holo: got %d want %d", got, scanHoloCost)
+	}
+	if got := effectiveCommandCost(scout, CommandRequest{Type: "SCAN", Action: "HOLO"}); got != scanHoloScoutCost {
+		t.Fatalf("scout holo: got %d want %d", got, scanHoloScoutCost)
+	}
+	if got := effectiveCommandCost(scout, CommandRequest{Type: "SCAN", Action: "DENSITY"}); got != scanDensityScoutCost {
+		t.Fatalf("scout density: got %d want %d", got, scanDensityScoutCost)
+	}
+	if got := effectiveCommandCost(Player{IsAdmin: true}, CommandRequest{Type: "SCAN", Action: "HOLO"}); got != 0 {
+		t.Fatalf("admin holo: got %d want 0", got)
+	}
+}
+
+func TestDensityIndex(t *testing.T) {
+	if got := densityIndex(0, 0, false); got != 0 || densityLabel(got) != "empty" {
+		t.Fatalf("empty sector: got %d (%s)", got, densityLabel(got))
+	}
+	if got := densityIndex(0, 1, false); got != 1 {
+		t.Fatalf("one mine: got %d want 1", got)
+	}
+	if got := densityIndex(2, 10, true); got != 37 || densityLabel(got) != "moderate" {
+		t.Fatalf("busy sector: got %d (%s)", got, densityLabel(got))
+	}
+}
+
+func TestShipyardUpgradeScanner(t *testing.T) {
+	p := Player{Credits: scannerPrice}
+	if out, _ := shipyardUpgrade(&p, "SCANNER"); !out.OK || !p.ShipScanner || p.Credits != 0 {
+		t.Fatalf("upgrade: ok=%v scanner=%v credits=%d", out.OK, p.ShipScanner, p.Credits)
+	}
+	if out, _ := shipyardUpgrade(&p, "SCANNER"); out.OK || out.ErrorCode != "MAX_UPGRADES" {
+		t.Fatalf("second upgrade: got %+v", out)
+	}
+}
@@ -0,0 +1,341 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// seasonFreezeWindow is how long a finished season stays frozen before the next one starts.
+const seasonFreezeWindow = 15 * time.Minute
+
+// seasonWarnings are the lead times at which players are told the season is about to end.
+var seasonWarnings = []time.Duration{24 * time.Hour, time.Hour, 10 * time.Minute}
+
+// Season end reasons.
+const (
+	SeasonEndTime     = "TIME"
+	SeasonEndNetWorth = "NET_WORTH"
+	SeasonEndPlanets  = "PLANETS"
+	SeasonEndAdmin    = "ADMIN"
+)
+
+var ErrSeasonFrozen = errors.New("season is frozen")
+
+// SeasonSchedule is when a season ends and which victory targets end it early. Zero values
+// disable the limit; EndsIn counts from when the schedule is applied.
+type SeasonSchedule struct {
+	EndsIn          time.Duration
+	VictoryNetWorth int64
+	VictoryPlanets  int
+}
+
+// SeasonScheduleResult is the active season's schedule after an update.
+type SeasonScheduleResult struct {
+	SeasonID        int        `json:"season_id"`
+	SeasonName      string     `json:"season_name"`
+	EndsAt          *time.Time `json:"ends_at,omitempty"`
+	VictoryNetWorth int64      `json:"victory_net_worth"`
+	VictoryPlanets  int        `json:"victory_planets"`
+}
+
+type seasonInfo struct {
+	ID              int
+	Name            string
+	StartedAt       time.Time
+	EndsAt          *time.Time
+	VictoryNetWorth int64
+	VictoryPlanets  int
+	WarnStage       int
+	FrozenAt        *time.Time
+	EndReason       string
+	WinnerName      string
+	Rules           SeasonRules
+}
+
+// schedule is the season's schedule carried over to its successor: same length, same targets.
+func (s seasonInfo) schedule() SeasonSchedule {
+	sch := SeasonSchedule{VictoryNetWorth: s.VictoryNetWorth, VictoryPlanets: s.VictoryPlanets}
+	if s.EndsAt != nil {
+		sch.EndsIn = s.EndsAt.Sub(s.StartedAt)
+	}
+	return sch
+}
+
+// seasonLeader is the player currently best placed for a victory target.
+type seasonLeader struct {
+	PlayerID string
+	Username string
+	Value    int64
+}
+
+func loadSeason(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, seasonID int) (seasonInfo, error) {
+	var s seasonInfo
+	var rulesRaw []byte
+	err := q.QueryRow(ctx, `
+		SELECT id, name, started_at, ends_at, victory_net_worth, victory_planets, warn_stage, frozen_at, end_reason, winner_name, rules
+		FROM seasons
+		WHERE id=$1
+	`, seasonID).Scan(&s.ID, &s.Name, &s.StartedAt, &s.EndsAt, &s.VictoryNetWorth, &s.VictoryPlanets, &s.WarnStage, &s.FrozenAt, &s.EndReason, &s.WinnerName, &rulesRaw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return seasonInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return seasonInfo{}, err
+	}
+	s.Rules, err = decodeSeasonRules(rulesRaw)
+	return s, err
+}
+
+// seasonNetWorthLeader finds the non-admin player with the highest net worth this season.
+func seasonNetWorthLeader(ctx context.Context, q interface {
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, seasonID int) (seasonLeader, error) {
+	standings, err := loadSeasonStandings(ctx, q, seasonID)
+	if err != nil || len(standings) == 0 {
+		return seasonLeader{}, err
+	}
+	top := standings[0]
+	return seasonLeader{PlayerID: top.PlayerID, Username: top.Username, Value: top.NetWorth}, nil
+}
+
+// seasonPlanetLeader finds the non-admin player who owns the most planets this season.
+func seasonPlanetLeader(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, seasonID int) (seasonLeader, error) {
+	var l seasonLeader
+	err := q.QueryRow(ctx, `
+		SELECT pl.id, u.username, COUNT(1)
+		FROM planets pt
+		JOIN players pl ON pl.id = pt.owner_player_id
+		JOIN users u ON u.id = pl.user_id
+		WHERE pl.season_id=$1 AND NOT u.is_admin
+		GROUP BY pl.id, u.username
+		ORDER BY COUNT(1) DESC, u.username ASC
+		LIMIT 1
+	`, seasonID).Scan(&l.PlayerID, &l.Username, &l.Value)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return seasonLeader{}, nil
+	}
+	return l, err
+}
+
+// seasonEndReason reports why the season is over at now, or "" while it continues. Victory
+// targets take precedence over the time limit when both are met on the same tick.
+func seasonEndReason(s seasonInfo, now time.Time, netWorth, planets seasonLeader) string {
+	if s.VictoryNetWorth > 0 && netWorth.PlayerID != "" && netWorth.Value >= s.VictoryNetWorth {
+		return SeasonEndNetWorth
+	}
+	if s.VictoryPlanets > 0 && planets.PlayerID != "" && planets.Value >= int64(s.VictoryPlanets) {
+		return SeasonEndPlanets
+	}
+	if s.EndsAt != nil && !now.Before(*s.EndsAt) {
+		return SeasonEndTime
+	}
+	return ""
+}
+
+// seasonWarningStage is how many of seasonWarnings have been reached with remaining time left.
+func seasonWarningStage(remaining time.Duration) int {
+	stage := 0
+	for i, lead := range seasonWarnings {
+		if remaining <= lead {
+			stage = i + 1
+		}
+	}
+	return stage
+}
+
+func seasonEndHeadline(name, reason string, winner seasonLeader) string {
+	switch {
+	case reason == SeasonEndNetWorth:
+		return fmt.Sprintf("%s wins %s with a net worth of %d credits.", winner.Username, name, winner.Value)
+	case reason == SeasonEndPlanets:
+		return fmt.Sprintf("%s wins %s, controlling %d planets.", winner.Username, name, winner.Value)
+	case winner.PlayerID != "":
+		return fmt.Sprintf("%s has ended. %s finishes with the highest net worth (%d credits).", name, winner.Username, winner.Value)
+	default:
+		return fmt.Sprintf("%s has ended.", name)
+	}
+}
+
+// logToSeason writes a SEASON log line for every player in the season.
+func logToSeason(ctx context.Context, tx pgx.Tx, seasonID int, msg string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO logs(player_id, kind, message)
+		SELECT id, 'SEASON', $2 FROM players WHERE season_id=$1
+	`, seasonID, msg)
+	return err
+}
+
+// seasonFrozenAllows lists the read-only commands that still work while a season is frozen.
+func seasonFrozenAllows(cmdType string) bool {
+	switch cmdType {
+	case "HELP", "SEASON", "RANKINGS", "HALL", "NEWS", "EVENTS":
+		return true
+	default:
+		return false
+	}
+}
+
+// StartSeasonTicker ends seasons on schedule or victory and rolls over to the next one.
+func StartSeasonTicker(ctx context.Context, pool *pgxpool.Pool, tickSeconds int) {
+	if tickSeconds <= 0 {
+		return
+	}
+	if tickSeconds < 10 {
+		tickSeconds = 10
+	}
+	go func() {
+		t := time.NewTicker(time.Duration(tickSeconds) * time.Second)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				_ = runSeasonTick(ctx, pool)
+			}
+		}
+	}()
+}
+
+// runSeasonTick warns players as the end approaches, freezes the season once it is over, and
+// after seasonFreezeWindow starts the next season with the same schedule and rules via SoftWipe.
+func runSeasonTick(ctx context.Context, pool *pgxpool.Pool) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var seasonID int
+	err = tx.QueryRow(ctx, "SELECT id FROM seasons WHERE active=true ORDER BY id DESC LIMIT 1 FOR UPDATE").Scan(&seasonID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s, err := loadSeason(ctx, tx, seasonID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+
+	if s.FrozenAt != nil {
+		if now.Before(s.FrozenAt.Add(seasonFreezeWindow)) {
+			return nil
+		}
+		// SoftWipe runs its own transaction.
+		_ = tx.Rollback(ctx)
+		_, err := SoftWipe(ctx, pool, SoftWipeRequest{Schedule: s.schedule(), Rules: s.Rules})
+		return err
+	}
+
+	netWorth, err := seasonNetWorthLeader(ctx, tx, s.ID)
+	if err != nil {
+		return err
+	}
+	planets, err := seasonPlanetLeader(ctx, tx, s.ID)
+	if err != nil {
+		return err
+	}
+
+	if reason := seasonEndReason(s, now, netWorth, planets); reason != "" {
+		winner := netWorth
+		if reason == SeasonEndPlanets {
+			winner = planets
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE seasons SET frozen_at=now(), end_reason=$2, winner_player_id=NULLIF($3, ''), winner_name=$4
+			WHERE id=$1
+		`, s.ID, reason, winner.PlayerID, winner.Username); err != nil {
+			return err
+		}
+		headline := seasonEndHeadline(s.Name, reason, winner)
+		if err := postGalaxyNews(ctx, tx, NewsKindSeason, headline); err != nil {
+			return err
+		}
+		msg := fmt.Sprintf("%s The galaxy is frozen; the next season starts in %s.", headline, formatDurationShort(seasonFreezeWindow))
+		if err := logToSeason(ctx, tx, s.ID, msg); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+
+	if s.EndsAt != nil {
+		remaining := s.EndsAt.Sub(now)
+		if stage := seasonWarningStage(remaining); stage > s.WarnStage {
+			msg := fmt.Sprintf("%s ends in %s. See SEASON for the victory standings.", s.Name, formatDurationShort(remaining))
+			if err := logToSeason(ctx, tx, s.ID, msg); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, "UPDATE seasons SET warn_stage=$2 WHERE id=$1", s.ID, stage); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// SetSeasonSchedule replaces the active season's end time and victory targets.
+func SetSeasonSchedule(ctx context.Context, pool *pgxpool.Pool, sch SeasonSchedule) (SeasonScheduleResult, error) {
+	if sch.EndsIn < 0 || sch.VictoryNetWorth < 0 || sch.VictoryPlanets < 0 {
+		return SeasonScheduleResult{}, errors.New("schedule values must not be negative")
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return SeasonScheduleResult{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var seasonID int
+	err = tx.QueryRow(ctx, "SELECT id FROM seasons WHERE active=true ORDER BY id DESC LIMIT 1 FOR UPDATE").Scan(&seasonID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return SeasonScheduleResult{}, ErrNotFound
+	}
+	if err != nil {
+		return SeasonScheduleResult{}, err
+	}
+	s, err := loadSeason(ctx, tx, seasonID)
+	if err != nil {
+		return SeasonScheduleResult{}, err
+	}
+	if s.FrozenAt != nil {
+		return SeasonScheduleResult{}, ErrSeasonFrozen
+	}
+
+	endsAt := sch.endsAt(time.Now().UTC())
+	if _, err := tx.Exec(ctx, `
+		UPDATE seasons SET ends_at=$2, victory_net_worth=$3, victory_planets=$4, warn_stage=0
+		WHERE id=$1
+	`, s.ID, endsAt, sch.VictoryNetWorth, sch.VictoryPlanets); err != nil {
+		return SeasonScheduleResult{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return SeasonScheduleResult{}, err
+	}
+	return SeasonScheduleResult{
+		SeasonID:        s.ID,
+		SeasonName:      s.Name,
+		EndsAt:          endsAt,
+		VictoryNetWorth: sch.VictoryNetWorth,
+		VictoryPlanets:  sch.VictoryPlanets,
+	}, nil
+}
+
+func (sch SeasonSchedule) endsAt(from time.Time) *time.Time {
+	if sch.EndsIn <= 0 {
+		return nil
+	}
+	t := from.Add(sch.EndsIn)
+	return &t
+}
@@ -0,0 +1,377 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const hallStandingsLimit = 10
+
+// SeasonResult is one player's final standing in a season.
+type SeasonResult struct {
+	Rank     int    `json:"rank"`
+	PlayerID string `json:"-"`
+	Username string `json:"username"`
+	CorpID   string `json:"-"`
+	CorpName string `json:"corp,omitempty"`
+	Credits  int64  `json:"credits"`
+	NetWorth int64  `json:"net_worth"`
+	XP       int64  `json:"xp"`
+	Level    int    `json:"level"`
+	Planets  int    `json:"planets"`
+}
+
+// ArchivedSeason is a finished season with its recorded winner.
+type ArchivedSeason struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	EndReason string    `json:"end_reason"`
+	Winner    string    `json:"winner,omitempty"`
+	Players   int       `json:"players"`
+}
+
+// SeasonRecord is the all-time best result in one category.
+type SeasonRecord struct {
+	Category   string `json:"category"`
+	Username   string `json:"username"`
+	Value      int64  `json:"value"`
+	SeasonID   int    `json:"season_id,omitempty"`
+	SeasonName string `json:"season_name,omitempty"`
+}
+
+// loadSeasonStandings values every non-admin player in the season and returns them ranked.
+func loadSeasonStandings(ctx context.Context, q interface {
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, seasonID int) ([]SeasonResult, error) {
+	rules, err := loadSeasonRules(ctx, q, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	out, err := valueSeasonPlayers(ctx, q, rules, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	rankSeasonResults(out)
+	return out, nil
+}
+
+// valueSeasonPlayers computes the standing (unranked) of every non-admin player in the season.
+func valueSeasonPlayers(ctx context.Context, q interface {
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+}, rules SeasonRules, seasonID int) ([]SeasonResult, error) {
+	prices, err := loadMarketPrices(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	holdings := map[string][]planetHolding{}
+	rows, err := q.Query(ctx, `
+		SELECT pt.owner_player_id, pt.storage_ore, pt.storage_organics, pt.storage_equipment, pt.citadel_level
+		FROM planets pt
+		JOIN players pl ON pl.id = pt.owner_player_id
+		JOIN users u ON u.id = pl.user_id
+		WHERE pl.season_id=$1 AND NOT u.is_admin
+	`, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var owner string
+		var h planetHolding
+		if err := rows.Scan(&owner, &h.Ore, &h.Organics, &h.Equipment, &h.CitadelLevel); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		holdings[owner] = append(holdings[owner], h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = q.Query(ctx, `
+		SELECT pl.id, u.username, COALESCE(c.id, ''), COALESCE(c.name, ''), pl.credits, pl.xp, pl.level,
+			pl.ship_type, pl.ship_cargo_upgrades, pl.ship_turn_upgrades, pl.ship_scanner, pl.ship_mines,
+			pl.cargo_ore, pl.cargo_organics, pl.cargo_equipment,
+			COALESCE(ba.deposit, 0), COALESCE(ba.loan, 0),
+			COALESCE(c.credits, 0), (SELECT COUNT(1) FROM corp_members m WHERE m.corp_id = c.id)
+		FROM players pl
+		JOIN users u ON u.id = pl.user_id
+		LEFT JOIN bank_accounts ba ON ba.player_id = pl.id
+		LEFT JOIN corp_members cm ON cm.player_id = pl.id
+		LEFT JOIN corporations c ON c.id = cm.corp_id
+		WHERE pl.season_id=$1 AND NOT u.is_admin
+	`, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []SeasonResult{}
+	for rows.Next() {
+		p := Player{Rules: rules}
+		var acct bankAccount
+		var r SeasonResult
+		var corpCredits, corpMembers int64
+		if err := rows.Scan(&p.ID, &p.Username, &r.CorpID, &r.CorpName, &p.Credits, &r.XP, &r.Level,
+			&p.ShipType, &p.ShipCargoUpgrades, &p.ShipTurnUpgrades, &p.ShipScanner, &p.ShipMines,
+			&p.CargoOre, &p.CargoOrganics, &p.CargoEquipment,
+			&acct.Deposit, &acct.Loan, &corpCredits, &corpMembers); err != nil {
+			return nil, err
+		}
+		corpShare := int64(0)
+		if corpMembers > 0 {
+			corpShare = corpCredits / corpMembers
+		}
+		r.PlayerID = p.ID
+		r.Username = p.Username
+		r.Credits = p.Credits
+		r.Planets = len(holdings[p.ID])
+		r.NetWorth = computeNetWorth(p, acct, holdings[p.ID], corpShare, prices).Total()
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// rankSeasonResults orders by net worth (ties by username) and numbers the ranks from 1.
+func rankSeasonResults(rs []SeasonResult) {
+	sort.SliceStable(rs, func(i, j int) bool {
+		if rs[i].NetWorth != rs[j].NetWorth {
+			return rs[i].NetWorth > rs[j].NetWorth
+		}
+		return rs[i].Username < rs[j].Username
+	})
+	for i := range rs {
+		rs[i].Rank = i + 1
+	}
+}
+
+// archiveSeasonResults writes the season's final standings. A season ended by an admin wipe
+// (rather than the season ticker) is credited to the net worth leader.
+func archiveSeasonResults(ctx context.Context, tx pgx.Tx, seasonID int) error {
+	standings, err := loadSeasonStandings(ctx, tx, seasonID)
+	if err != nil {
+		return err
+	}
+	for _, r := range standings {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO season_results(season_id, rank, player_id, username, corp_name, credits, net_worth, xp, level, planets)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+			ON CONFLICT (season_id, rank) DO NOTHING
+		`, seasonID, r.Rank, r.PlayerID, r.Username, r.CorpName, r.Credits, r.NetWorth, r.XP, r.Level, r.Planets); err != nil {
+			return err
+		}
+	}
+	if len(standings) > 0 {
+		if _, err := tx.Exec(ctx, `
+			UPDATE seasons SET end_reason=$2, winner_player_id=$3, winner_name=$4
+			WHERE id=$1 AND end_reason=''
+		`, seasonID, SeasonEndAdmin, standings[0].PlayerID, standings[0].Username); err != nil {
+			return err
+		}
+	} else {
+		if _, err := tx.Exec(ctx, "UPDATE seasons SET end_reason=$2 WHERE id=$1 AND end_reason=''", seasonID, SeasonEndAdmin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadArchivedSeasons lists finished seasons, newest first.
+func LoadArchivedSeasons(ctx context.Context, q interface {
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+}, limit int) ([]ArchivedSeason, error) {
+	if limit < 1 || limit > 100 {
+		limit = hallStandingsLimit
+	}
+	rows, err := q.Query(ctx, `
+		SELECT s.id, s.name, s.started_at, s.ended_at, s.end_reason, s.winner_name,
+			(SELECT COUNT(1) FROM season_results r WHERE r.season_id = s.id)
+		FROM seasons s
+		WHERE NOT s.active AND s.ended_at IS NOT NULL
+		ORDER BY s.id DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []ArchivedSeason{}
+	for rows.Next() {
+		var s ArchivedSeason
+		if err := rows.Scan(&s.ID, &s.Name, &s.StartedAt, &s.EndedAt, &s.EndReason, &s.Winner, &s.Players); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// LoadSeasonResults returns a finished season and its final standings (up to limit).
+// It returns ErrNotFound for unknown or still running seasons.
+func LoadSeasonResults(ctx context.Context, q interface {
+	Query(context.Context, string, ...any) (pgx.Rows, error)
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, seasonID, limit int) (ArchivedSeason, []SeasonResult, error) {
+	var s ArchivedSeason
+	err := q.QueryRow(ctx, `
+		SELECT id, name, started_at, ended_at, end_reason, winner_name,
+			(SELECT COUNT(1) FROM season_results r WHERE r.season_id = seasons.id)
+		FROM seasons
+		WHERE id=$1 AND NOT active AND ended_at IS NOT NULL
+	`, seasonID).Scan(&s.ID, &s.Name, &s.StartedAt, &s.EndedAt, &s.EndReason, &s.Winner, &s.Players)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ArchivedSeason{}, nil, ErrNotFound
+	}
+	if err != nil {
+		return ArchivedSeason{}, nil, err
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT rank, COALESCE(player_id, ''), username, corp_name, credits, net_worth, xp, level, planets
+		FROM season_results
+		WHERE season_id=$1
+		ORDER BY rank ASC
+		LIMIT $2
+	`, seasonID, limit)
+	if err != nil {
+		return ArchivedSeason{}, nil, err
+	}
+	defer rows.Close()
+	out := []SeasonResult{}
+	for rows.Next() {
+		var r SeasonResult
+		if err := rows.Scan(&r.Rank, &r.PlayerID, &r.Username, &r.CorpName, &r.Credits, &r.NetWorth, &r.XP, &r.Level, &r.Planets); err != nil {
+			return ArchivedSeason{}, nil, err
+		}
+		out = append(out, r)
+	}
+	return s, out, rows.Err()
+}
+
+// seasonRecordColumns maps record categories to season_results columns.
+var seasonRecordColumns = []struct {
+	category string
+	column   string
+}{
+	{"NET_WORTH", "net_worth"},
+	{"CREDITS", "credits"},
+	{"XP", "xp"},
+	{"PLANETS", "planets"},
+}
+
+// LoadSeasonRecords returns the all-time bests across archived seasons, plus the most season wins.
+func LoadSeasonRecords(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}) ([]SeasonRecord, error) {
+	out := []SeasonRecord{}
+	for _, c := range seasonRecordColumns {
+		rec := SeasonRecord{Category: c.category}
+		err := q.QueryRow(ctx, fmt.Sprintf(`
+			SELECT r.username, r.%s, s.id, s.name
+			FROM season_results r
+			JOIN seasons s ON s.id = r.season_id
+			ORDER BY r.%s DESC, s.id ASC, r.rank ASC
+			LIMIT 1
+		`, c.column, c.column)).Scan(&rec.Username, &rec.Value, &rec.SeasonID, &rec.SeasonName)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+
+	wins := SeasonRecord{Category: "WINS"}
+	err := q.QueryRow(ctx, `
+		SELECT winner_name, COUNT(1)
+		FROM seasons
+		WHERE NOT active AND winner_name <> ''
+		GROUP BY winner_name
+		ORDER BY COUNT(1) DESC, MIN(id) ASC
+		LIMIT 1
+	`).Scan(&wins.Username, &wins.Value)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(out, wins), nil
+}
+
+// executeHallCommand shows past seasons and all-time records (HALL), or one season's final
+// standings (HALL {season id}).
+func executeHallCommand(ctx context.Context, tx pgx.Tx, seasonID int) (string, error) {
+	if seasonID > 0 {
+		s, results, err := LoadSeasonResults(ctx, tx, seasonID, hallStandingsLimit)
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Sprintf("No archived results for season %d.", seasonID), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		lines := []string{fmt.Sprintf("%s final standings (ended by %s; winner: %s)", s.Name, s.EndReason, seasonWinnerLabel(s.Winner))}
+		for _, r := range results {
+			lines = append(lines, formatSeasonResult(r))
+		}
+		if len(results) == 0 {
+			lines = append(lines, "No players finished this season.")
+		} else if s.Players > len(results) {
+			lines = append(lines, fmt.Sprintf("(%d more; see GET /api/seasons/%d/results)", s.Players-len(results), s.ID))
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+
+	seasons, err := LoadArchivedSeasons(ctx, tx, hallStandingsLimit)
+	if err != nil {
+		return "", err
+	}
+	if len(seasons) == 0 {
+		return "Hall of fame: no seasons have finished yet.", nil
+	}
+	lines := []string{"Hall of fame:"}
+	for _, s := range seasons {
+		lines = append(lines, fmt.Sprintf("- %s (ID %d, ended %s by %s): winner %s, %d players",
+			s.Name, s.ID, s.EndedAt.UTC().Format("2006-01-02"), s.EndReason, seasonWinnerLabel(s.Winner), s.Players))
+	}
+	records, err := LoadSeasonRecords(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+	if len(records) > 0 {
+		lines = append(lines, "All-time records:")
+		for _, r := range records {
+			lines = append(lines, formatSeasonRecord(r))
+		}
+	}
+	lines = append(lines, "HALL {season id} shows a season's final standings.")
+	return strings.Join(lines, "\n"), nil
+}
+
+func formatSeasonResult(r SeasonResult) string {
+	label := r.Username
+	if r.CorpName != "" {
+		label = fmt.Sprintf("%s [%s]", r.Username, r.CorpName)
+	}
+	return fmt.Sprintf("%d. %s - net worth %d, credits %d, XP %d (level %d), planets %d",
+		r.Rank, label, r.NetWorth, r.Credits, r.XP, r.Level, r.Planets)
+}
+
+func formatSeasonRecord(r SeasonRecord) string {
+	if r.Category == "WINS" {
+		return fmt.Sprintf("- Season wins: %s (%d)", r.Username, r.Value)
+	}
+	names := map[string]string{"NET_WORTH": "Net worth", "CREDITS": "Credits", "XP": "XP", "PLANETS": "Planets"}
+	return fmt.Sprintf("- %s: %s %d (%s)", names[r.Category], r.Username, r.Value, r.SeasonName)
+}
@@ -0,0 +1,337 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Upper bound for any credit amount in a rule set.
+const seasonRulesMaxCredits = int64(1_000_000_000)
+
+// SeasonRules are the gameplay knobs stored with a season. A zero field keeps the server
+// default: the env setting for turn regen and tick intervals, the built-in constant otherwise.
+type SeasonRules struct {
+	TurnRegenSeconds        int              `json:"turn_regen_seconds,omitempty"`
+	PortTickSeconds         int              `json:"port_tick_seconds,omitempty"`
+	PlanetTickSeconds       int              `json:"planet_tick_seconds,omitempty"`
+	EventTickSeconds        int              `json:"event_tick_seconds,omitempty"`
+	ProtectorateTickSeconds int              `json:"protectorate_tick_seconds,omitempty"`
+	BankTickSeconds         int              `json:"bank_tick_seconds,omitempty"`
+	PlanetColonizeCredits   int64            `json:"planet_colonize_credits,omitempty"`
+	CitadelUpgradeBase      int64            `json:"citadel_upgrade_base,omitempty"`
+	MineDamagePerMine       int64            `json:"mine_damage_per_mine,omitempty"`
+	ShipPrices              map[string]int64 `json:"ship_prices,omitempty"`
+	// Per planet class (M, O, L, D) recipe overrides.
+	PlanetRecipes map[string]PlanetRecipeRules `json:"planet_recipes,omitempty"`
+}
+
+// ParseSeasonRules decodes and validates a rule set. Unknown fields are rejected so typos do
+// not silently fall back to the defaults. Ship types are normalized to upper case.
+func ParseSeasonRules(raw []byte) (SeasonRules, error) {
+	var r SeasonRules
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return r, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&r); err != nil {
+		return SeasonRules{}, fmt.Errorf("invalid rules: %w", err)
+	}
+	if err := r.normalize(); err != nil {
+		return SeasonRules{}, err
+	}
+	return r, nil
+}
+
+func (r *SeasonRules) normalize() error {
+	ticks := []struct {
+		name string
+		val  int
+		min  int
+	}{
+		{"turn_regen_seconds", r.TurnRegenSeconds, 10},
+		{"port_tick_seconds", r.PortTickSeconds, 5},
+		{"planet_tick_seconds", r.PlanetTickSeconds, 5},
+		{"event_tick_seconds", r.EventTickSeconds, 10},
+		{"protectorate_tick_seconds", r.ProtectorateTickSeconds, 10},
+		{"bank_tick_seconds", r.BankTickSeconds, 10},
+	}
+	for _, t := range ticks {
+		if t.val != 0 && (t.val < t.min || t.val > 86400) {
+			return fmt.Errorf("%s must be 0 (server default) or between %d and 86400", t.name, t.min)
+		}
+	}
+	credits := []struct {
+		name string
+		val  int64
+	}{
+		{"planet_colonize_credits", r.PlanetColonizeCredits},
+		{"citadel_upgrade_base", r.CitadelUpgradeBase},
+		{"mine_damage_per_mine", r.MineDamagePerMine},
+	}
+	for _, c := range credits {
+		if c.val < 0 || c.val > seasonRulesMaxCredits {
+			return fmt.Errorf("%s must be between 0 (default) and %d", c.name, seasonRulesMaxCredits)
+		}
+	}
+	if err := r.normalizePlanetRecipes(); err != nil {
+		return err
+	}
+	if len(r.ShipPrices) == 0 {
+		r.ShipPrices = nil
+		return nil
+	}
+	prices := make(map[string]int64, len(r.ShipPrices))
+	for st, price := range r.ShipPrices {
+		d, ok := findShipDef(st)
+		if !ok {
+			return fmt.Errorf("ship_prices: unknown ship type %q", st)
+		}
+		if price < 0 || price > seasonRulesMaxCredits {
+			return fmt.Errorf("ship_prices: %s price must be between 0 and %d", d.Type, seasonRulesMaxCredits)
+		}
+		prices[d.Type] = price
+	}
+	r.ShipPrices = prices
+	return nil
+}
+
+func (r *SeasonRules) normalizePlanetRecipes() error {
+	if len(r.PlanetRecipes) == 0 {
+		r.PlanetRecipes = nil
+		return nil
+	}
+	recipes := make(map[string]PlanetRecipeRules, len(r.PlanetRecipes))
+	for class, o := range r.PlanetRecipes {
+		base, ok := lookupPlanetRecipe(class)
+		if !ok {
+			return fmt.Errorf("planet_recipes: unknown planet class %q", class)
+		}
+		if err := o.validate(base.Class); err != nil {
+			return err
+		}
+		recipes[base.Class] = o
+	}
+	r.PlanetRecipes = recipes
+	return nil
+}
+
+// decodeSeasonRules reads a rule set stored on a season row; it was validated when written.
+func decodeSeasonRules(raw []byte) (SeasonRules, error) {
+	var r SeasonRules
+	if len(raw) == 0 {
+		return r, nil
+	}
+	err := json.Unmarshal(raw, &r)
+	return r, err
+}
+
+func encodeSeasonRules(r SeasonRules) ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func pickSeconds(override, def int) int {
+	if override > 0 {
+		return override
+	}
+	return def
+}
+
+// TurnRegen is the seconds per regenerated turn, given the server default.
+func (r SeasonRules) TurnRegen(def int) int { return pickSeconds(r.TurnRegenSeconds, def) }
+
+func (r SeasonRules) colonizeCost() int64 {
+	if r.PlanetColonizeCredits > 0 {
+		return r.PlanetColonizeCredits
+	}
+	return planetColonizeCostCredits
+}
+
+func (r SeasonRules) citadelUpgradeCost(nextLevel int) int64 {
+	if r.CitadelUpgradeBase > 0 {
+		return int64(max(1, nextLevel)) * r.CitadelUpgradeBase
+	}
+	return citadelUpgradeCost(nextLevel)
+}
+
+func (r SeasonRules) mineDamageCredits(triggered int) int64 {
+	if r.MineDamagePerMine > 0 && triggered > 0 {
+		return int64(triggered) * r.MineDamagePerMine
+	}
+	return mineDamageCredits(triggered)
+}
+
+// shipPrice is the season's shipyard price for a hull.
+func (r SeasonRules) shipPrice(d shipDef) int64 {
+	if price, ok := r.ShipPrices[d.Type]; ok {
+		return price
+	}
+	return d.Price
+}
+
+// planetRecipe is the season's production recipe for a planet class.
+func (r SeasonRules) planetRecipe(class string) planetRecipe {
+	base := findPlanetRecipe(class)
+	if o, ok := r.PlanetRecipes[base.Class]; ok {
+		return o.apply(base)
+	}
+	return base
+}
+
+// describe lists the rules that differ from the server defaults.
+func (r SeasonRules) describe() []string {
+	var out []string
+	add := func(label string, v int64, unit string) {
+		if v > 0 {
+			out = append(out, fmt.Sprintf("%s %d%s", label, v, unit))
+		}
+	}
+	add("turn regen", int64(r.TurnRegenSeconds), "s")
+	add("port tick", int64(r.PortTickSeconds), "s")
+	add("planet tick", int64(r.PlanetTickSeconds), "s")
+	add("event tick", int64(r.EventTickSeconds), "s")
+	add("protectorate tick", int64(r.ProtectorateTickSeconds), "s")
+	add("bank tick", int64(r.BankTickSeconds), "s")
+	add("colonize", r.PlanetColonizeCredits, " cr")
+	add("citadel base", r.CitadelUpgradeBase, " cr")
+	add("mine damage", r.MineDamagePerMine, " cr/mine")
+	ships := make([]string, 0, len(r.ShipPrices))
+	for st := range r.ShipPrices {
+		ships = append(ships, st)
+	}
+	sort.Strings(ships)
+	for _, st := range ships {
+		out = append(out, fmt.Sprintf("%s %d cr", strings.ToLower(st), r.ShipPrices[st]))
+	}
+	classes := make([]string, 0, len(r.PlanetRecipes))
+	for class := range r.PlanetRecipes {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		out = append(out, fmt.Sprintf("custom %s recipe", findPlanetRecipe(class).Name))
+	}
+	return out
+}
+
+// loadSeasonRules reads the rule set of a season.
+func loadSeasonRules(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, seasonID int) (SeasonRules, error) {
+	var raw []byte
+	err := q.QueryRow(ctx, "SELECT rules FROM seasons WHERE id=$1", seasonID).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return SeasonRules{}, ErrNotFound
+	}
+	if err != nil {
+		return SeasonRules{}, err
+	}
+	return decodeSeasonRules(raw)
+}
+
+// loadActiveSeasonRules reads the rule set of the active season; no active season means defaults.
+func loadActiveSeasonRules(ctx context.Context, pool *pgxpool.Pool) (SeasonRules, error) {
+	var raw []byte
+	err := pool.QueryRow(ctx, "SELECT rules FROM seasons WHERE active=true ORDER BY id DESC LIMIT 1").Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return SeasonRules{}, nil
+	}
+	if err != nil {
+		return SeasonRules{}, err
+	}
+	return decodeSeasonRules(raw)
+}
+
+// SetSeasonRules replaces the active season's rule set.
+func SetSeasonRules(ctx context.Context, pool *pgxpool.Pool, r SeasonRules) (SeasonRules, error) {
+	if err := r.normalize(); err != nil {
+		return SeasonRules{}, err
+	}
+	raw, err := encodeSeasonRules(r)
+	if err != nil {
+		return SeasonRules{}, err
+	}
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return SeasonRules{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var seasonID int
+	var frozen bool
+	err = tx.QueryRow(ctx, "SELECT id, frozen_at IS NOT NULL FROM seasons WHERE active=true ORDER BY id DESC LIMIT 1 FOR UPDATE").Scan(&seasonID, &frozen)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return SeasonRules{}, ErrNotFound
+	}
+	if err != nil {
+		return SeasonRules{}, err
+	}
+	if frozen {
+		return SeasonRules{}, ErrSeasonFrozen
+	}
+	if _, err := tx.Exec(ctx, "UPDATE seasons SET rules=$2 WHERE id=$1", seasonID, raw); err != nil {
+		return SeasonRules{}, err
+	}
+	return r, tx.Commit(ctx)
+}
+
+// How often a rules ticker re-reads the active season's interval while it waits.
+const rulesTickerRecheck = time.Minute
+
+// rulesTickWait decides, at now, whether a ticker that last ran at last is due under interval,
+// and otherwise how long to sleep before checking again (at most recheck).
+func rulesTickWait(last, now time.Time, interval, recheck time.Duration) (bool, time.Duration) {
+	left := interval - now.Sub(last)
+	if left <= 0 {
+		return true, 0
+	}
+	if left > recheck {
+		return false, recheck
+	}
+	return false, left
+}
+
+// startRulesTicker calls fn every def seconds, or at the interval the active season's rules set
+// through pick (never below floor). The interval is re-read at least every rulesTickerRecheck,
+// so a new season's pace (or a rules change) applies without waiting out the old interval.
+func startRulesTicker(ctx context.Context, pool *pgxpool.Pool, def, floor int, pick func(SeasonRules) int, fn func()) {
+	interval := func() time.Duration {
+		secs := def
+		if r, err := loadActiveSeasonRules(ctx, pool); err == nil {
+			secs = pickSeconds(pick(r), def)
+		}
+		if secs < floor {
+			secs = floor
+		}
+		return time.Duration(secs) * time.Second
+	}
+	go func() {
+		last := time.Now()
+		_, wait := rulesTickWait(last, last, interval(), rulesTickerRecheck)
+		t := time.NewTimer(wait)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+			due, wait := rulesTickWait(last, time.Now(), interval(), rulesTickerRecheck)
+			if due {
+				fn()
+				last = time.Now()
+				_, wait = rulesTickWait(last, last, interval(), rulesTickerRecheck)
+			}
+			t.Reset(wait)
+		}
+	}()
+}
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSeasonRules(t *testing.T) {
+	r, err := ParseSeasonRules([]byte(`{"turn_regen_seconds":30,"planet_colonize_credits":6000,"ship_prices":{"trader":10000}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.TurnRegenSeconds != 30 || r.PlanetColonizeCredits != 6000 || r.ShipPrices["TRADER"] != 10000 {
+		t.Fatalf("got %+v", r)
+	}
+
+	if r, err := ParseSeasonRules(nil); err != nil || r.TurnRegenSeconds != 0 || r.ShipPrices != nil {
+		t.Fatalf("empty body should give defaults, got %+v, %v", r, err)
+	}
+
+	bad := map[string]string{
+		`{"turn_regen_secs":30}`:               "unknown field",
+		`{"turn_regen_seconds":5}`:             "turn_regen_seconds",
+		`{"bank_tick_seconds":100000}`:         "bank_tick_seconds",
+		`{"mine_damage_per_mine":-1}`:          "mine_damage_per_mine",
+		`{"ship_prices":{"BATTLESHIP":1}}`:     "unknown ship type",
+		`{"ship_prices":{"SCOUT":-5}}`:         "SCOUT price",
+		`{"planet_colonize_credits":"cheap"}`:  "invalid rules",
+		`{"citadel_upgrade_base":10000000000}`: "citadel_upgrade_base",
+	}
+	for raw, want := range bad {
+		if _, err := ParseSeasonRules([]byte(raw)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: got %v, want error containing %q", raw, err, want)
+		}
+	}
+}
+
+func TestSeasonRulesDefaults(t *testing.T) {
+	var r SeasonRules
+	if r.TurnRegen(120) != 120 || r.colonizeCost() != planetColonizeCostCredits {
+		t.Fatalf("zero rules should keep defaults")
+	}
+	if r.citadelUpgradeCost(3) != citadelUpgradeCost(3) || r.mineDamageCredits(2) != mineDamageCredits(2) {
+		t.Fatalf("zero rules should keep default costs")
+	}
+	trader, _ := findShipDef("TRADER")
+	if r.shipPrice(trader) != trader.Price {
+		t.Fatalf("zero rules should keep catalog prices")
+	}
+
+	hard := SeasonRules{TurnRegenSeconds: 30, CitadelUpgradeBase: 8000, MineDamagePerMine: 200, ShipPrices: map[string]int64{"TRADER": 40000}}
+	if hard.TurnRegen(120) != 30 || hard.citadelUpgradeCost(2) != 16000 || hard.mineDamageCredits(3) != 600 || hard.mineDamageCredits(0) != 0 {
+		t.Fatalf("overrides not applied: %+v", hard)
+	}
+	if hard.shipPrice(trader) != 40000 {
+		t.Fatalf("ship price override: got %d", hard.shipPrice(trader))
+	}
+	if got := shipResaleValue(Player{ShipType: "TRADER", Rules: hard}); got != 28000 {
+		t.Fatalf("resale should follow the season price, got %d", got)
+	}
+}
+
+func TestSeasonRulesDescribe(t *testing.T) {
+	if got := (SeasonRules{}).describe(); len(got) != 0 {
+		t.Fatalf("defaults should describe as nothing, got %v", got)
+	}
+	r := SeasonRules{TurnRegenSeconds: 30, PlanetColonizeCredits: 6000, ShipPrices: map[string]int64{"TRADER": 1, "FREIGHTER": 2}}
+	got := strings.Join(r.describe(), ", ")
+	want := "turn regen 30s, colonize 6000 cr, freighter 2 cr, trader 1 cr"
+	if got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestRulesTickWait(t *testing.T) {
+	last := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		elapsed, interval time.Duration
+		due               bool
+		wait              time.Duration
+	}{
+		{0, 30 * time.Second, false, 30 * time.Second},
+		{0, time.Hour, false, time.Minute},
+		{50 * time.Minute, time.Hour, false, time.Minute},
+		{59*time.Minute + 30*time.Second, time.Hour, false, 30 * time.Second},
+		{time.Hour, time.Hour, true, 0},
+		// After a rollover from an hourly tick to a 5 minute one, the next check runs it.
+		{10 * time.Minute, 5 * time.Minute, true, 0},
+	}
+	for _, c := range cases {
+		due, wait := rulesTickWait(last, last.Add(c.elapsed), c.interval, time.Minute)
+		if due != c.due || wait != c.wait {
+			t.Fatalf("elapsed %s interval %s: got %v %s, want %v %s", c.elapsed, c.interval, due, wait, c.due, c.wait)
+		}
+	}
+}
+
+func TestExpectedLossUsesSeasonMineDamage(t *testing.T) {
+	h := SectorHazard{HostileMines: 10}
+	triggered := mineTriggerCount(10)
+	if got := h.ExpectedLoss(SeasonRules{}); got != mineDamageCredits(triggered) {
+		t.Fatalf("default rules: got %d", got)
+	}
+	r := SeasonRules{MineDamagePerMine: 400}
+	if got := h.ExpectedLoss(r); got != int64(triggered)*400 {
+		t.Fatalf("season override: got %d want %d", got, int64(triggered)*400)
+	}
+	if got := pathExposure([]int{1, 2}, map[int]SectorHazard{2: h}, r); got.ExpectedLoss != int64(triggered)*400 {
+		t.Fatalf("path exposure: got %d", got.ExpectedLoss)
+	}
+}
@@ -0,0 +1,161 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeasonEndReason(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+	rich := seasonLeader{PlayerID: "p1", Username: "alice", Value: 6_000_000}
+	landlord := seasonLeader{PlayerID: "p2", Username: "bob", Value: 12}
+
+	if got := seasonEndReason(seasonInfo{}, now, rich, landlord); got != "" {
+		t.Fatalf("no schedule should never end, got %q", got)
+	}
+	if got := seasonEndReason(seasonInfo{EndsAt: &future}, now, rich, landlord); got != "" {
+		t.Fatalf("before ends_at: got %q", got)
+	}
+	if got := seasonEndReason(seasonInfo{EndsAt: &past}, now, seasonLeader{}, seasonLeader{}); got != SeasonEndTime {
+		t.Fatalf("after ends_at: got %q", got)
+	}
+	if got := seasonEndReason(seasonInfo{VictoryNetWorth: 5_000_000, EndsAt: &past}, now, rich, landlord); got != SeasonEndNetWorth {
+		t.Fatalf("net worth target should win over the time limit, got %q", got)
+	}
+	if got := seasonEndReason(seasonInfo{VictoryNetWorth: 7_000_000, VictoryPlanets: 12}, now, rich, landlord); got != SeasonEndPlanets {
+		t.Fatalf("planet target: got %q", got)
+	}
+	if got := seasonEndReason(seasonInfo{VictoryPlanets: 13}, now, rich, landlord); got != "" {
+		t.Fatalf("planet target not reached: got %q", got)
+	}
+	if got := seasonEndReason(seasonInfo{VictoryNetWorth: 1}, now, seasonLeader{}, seasonLeader{}); got != "" {
+		t.Fatalf("no players should not win, got %q", got)
+	}
+}
+
+func TestSeasonWarningStage(t *testing.T) {
+	cases := []struct {
+		remaining time.Duration
+		want      int
+	}{
+		{48 * time.Hour, 0},
+		{24 * time.Hour, 1},
+		{3 * time.Hour, 1},
+		{time.Hour, 2},
+		{10 * time.Minute, 3},
+		{-time.Minute, 3},
+	}
+	for _, c := range cases {
+		if got := seasonWarningStage(c.remaining); got != c.want {
+			t.Fatalf("remaining %s: got %d want %d", c.remaining, got, c.want)
+		}
+	}
+}
+
+func TestSeasonScheduleCarriesOver(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(14 * 24 * time.Hour)
+	s := seasonInfo{StartedAt: start, EndsAt: &end, VictoryNetWorth: 5_000_000, VictoryPlanets: 10}
+	sch := s.schedule()
+	if sch.EndsIn != 14*24*time.Hour || sch.VictoryNetWorth != 5_000_000 || sch.VictoryPlanets != 10 {
+		t.Fatalf("got %+v", sch)
+	}
+	next := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	if got := sch.endsAt(next); got == nil || !got.Equal(next.Add(14*24*time.Hour)) {
+		t.Fatalf("endsAt: got %v", got)
+	}
+	if got := (seasonInfo{StartedAt: start}).schedule().endsAt(next); got != nil {
+		t.Fatalf("open-ended season should stay open-ended, got %v", got)
+	}
+}
+
+func TestSeasonProgressPct(t *testing.T) {
+	if got := seasonProgressPct(250, 1000); got != 25 {
+		t.Fatalf("got %d", got)
+	}
+	if got := seasonProgressPct(5000, 1000); got != 100 {
+		t.Fatalf("should cap at 100, got %d", got)
+	}
+	if got := seasonProgressPct(-10, 1000); got != 0 {
+		t.Fatalf("negative net worth: got %d", got)
+	}
+}
+
+func TestRankSeasonResults(t *testing.T) {
+	rs := []SeasonResult{
+		{Username: "carol", NetWorth: 500},
+		{Username: "bob", NetWorth: 9000},
+		{Username: "alice", NetWorth: 500},
+	}
+	rankSeasonResults(rs)
+	want := []string{"bob", "alice", "carol"}
+	for i, r := range rs {
+		if r.Username != want[i] || r.Rank != i+1 {
+			t.Fatalf("position %d: got %s rank %d, want %s rank %d", i, r.Username, r.Rank, want[i], i+1)
+		}
+	}
+}
+
+func TestFormatSeasonResult(t *testing.T) {
+	got := formatSeasonResult(SeasonResult{Rank: 2, Username: "alice", CorpName: "Nova", NetWorth: 1200, Credits: 800, XP: 450, Level: 3, Planets: 2})
+	want := "2. alice [Nova] - net worth 1200, credits 800, XP 450 (level 3), planets 2"
+	if got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestParseRankingsArgs(t *testing.T) {
+	cases := []struct {
+		category string
+		page     int
+		want     string
+		wantPage int
+		ok       bool
+	}{
+		{"", 0, RankingNetWorth, 1, true},
+		{"xp", 3, RankingXP, 3, true},
+		{"net_worth", -1, RankingNetWorth, 1, true},
+		{"CORPS", 2, RankingCorps, 2, true},
+		{"gold", 1, "", 0, false},
+	}
+	for _, c := range cases {
+		got, page, ok := parseRankingsArgs(c.category, c.page)
+		if got != c.want || page != c.wantPage || ok != c.ok {
+			t.Fatalf("%q %d: got %q %d %v", c.category, c.page, got, page, ok)
+		}
+	}
+	if got := rankingPages(0); got != 1 {
+		t.Fatalf("empty snapshot: got %d pages", got)
+	}
+	if got := rankingPages(21); got != 3 {
+		t.Fatalf("21 entries: got %d pages", got)
+	}
+}
+
+func TestComputeNetWorth(t *testing.T) {
+	d, ok := findShipDef("TRADER")
+	if !ok {
+		t.Fatal("no TRADER hull")
+	}
+	p := Player{ShipType: d.Type, Credits: 1000, CargoOre: 10, ShipScanner: true}
+	p.Rules.ShipPrices = map[string]int64{d.Type: 7000}
+	if got := shipInvestment(p); got != 7000+scannerPrice {
+		t.Fatalf("ship: got %d", got)
+	}
+	if got := citadelInvestment(SeasonRules{CitadelUpgradeBase: 100}, 3); got != 100+200+300 {
+		t.Fatalf("citadel: got %d", got)
+	}
+
+	prices := marketPrices{Ore: 20, Organics: 30, Equipment: 40}
+	planets := []planetHolding{{Ore: 5, Equipment: 1}, {CitadelLevel: 1}}
+	n := computeNetWorth(p, bankAccount{Deposit: 500, Loan: 200}, planets, 250, prices)
+	want := NetWorth{Credits: 1000, Bank: 300, Ship: 7000 + scannerPrice, Cargo: 200, Planets: 140 + citadelUpgradeCost(1), CorpShare: 250}
+	if n != want {
+		t.Fatalf("got %+v want %+v", n, want)
+	}
+	if n.Total() != 1000+300+7000+scannerPrice+200+140+citadelUpgradeCost(1)+250 {
+		t.Fatalf("total: got %d", n.Total())
+	}
+}
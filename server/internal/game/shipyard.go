@@ -14,17 +14,27 @@ const (
 )
 
 type shipDef struct {
-	Type     string
-	CargoMax int
-	TurnsMax int
-	Price    int64
+	Type      string
+	CargoMax  int
+	TurnsMax  int
+	MineRacks int
+	Price     int64
 }
 
 var shipCatalog = []shipDef{
-	{Type: "SCOUT", CargoMax: 30, TurnsMax: 100, Price: 0},
-	{Type: "TRADER", CargoMax: 60, TurnsMax: 110, Price: 25000},
-	{Type: "FREIGHTER", CargoMax: 90, TurnsMax: 110, Price: 60000},
-	{Type: "INTERCEPTOR", CargoMax: 40, TurnsMax: 140, Price: 50000},
+	{Type: "SCOUT", CargoMax: 30, TurnsMax: 100, MineRacks: 10, Price: 0},
+	{Type: "TRADER", CargoMax: 60, TurnsMax: 110, MineRacks: 20, Price: 25000},
+	{Type: "FREIGHTER", CargoMax: 90, TurnsMax: 110, MineRacks: 20, Price: 60000},
+	{Type: "INTERCEPTOR", CargoMax: 40, TurnsMax: 140, MineRacks: 50, Price: 50000},
+}
+
+// mineRacks is how many stardock mines the player's hull can carry outside the cargo hold.
+func mineRacks(p Player) int {
+	d, ok := findShipDef(p.ShipType)
+	if !ok {
+		d, _ = findShipDef("SCOUT")
+	}
+	return d.MineRacks
 }
 
 func findShipDef(shipType string) (shipDef, bool) {
@@ -77,7 +87,7 @@ func executeShipyardCommand(ctx context.Context, tx pgx.Tx, p *Player, cmd Comma
 		return shipyardSell(p)
 	case "UPGRADE":
 		if name == "" {
-			return phase2Result{OK: false, Message: "SHIPYARD UPGRADE requires CARGO or TURNS.", ErrorCode: "INVALID_UPGRADE"}, nil
+			return phase2Result{OK: false, Message: "SHIPYARD UPGRADE requires CARGO, TURNS or SCANNER.", ErrorCode: "INVALID_UPGRADE"}, nil
 		}
 		return shipyardUpgrade(p, name)
 	default:
@@ -92,15 +102,16 @@ func shipyardInfo(p Player) string {
 	}
 
 	lines := []string{}
-	lines = append(lines, "SHIPYARD commands: SHIPYARD BUY {type} | SHIPYARD SELL | SHIPYARD UPGRADE {CARGO|TURNS}")
+	lines = append(lines, "SHIPYARD commands: SHIPYARD BUY {type} | SHIPYARD SELL | SHIPYARD UPGRADE {CARGO|TURNS|SCANNER}")
 	lines = append(lines, fmt.Sprintf("Current ship: %s (CargoMax=%d, TurnsMax=%d)", ship.Type, p.CargoMax, p.TurnsMax))
 	lines = append(lines, fmt.Sprintf("Upgrades: Cargo +%d (%d/%d), Turns +%d (%d/%d)", p.ShipCargoUpgrades*5, p.ShipCargoUpgrades, maxCargoUpgrades, p.ShipTurnUpgrades*10, p.ShipTurnUpgrades, maxTurnUpgrades))
 	lines = append(lines, "Available ships:")
 	for _, d := range shipCatalog {
-		lines = append(lines, fmt.Sprintf("- %s: CargoMax=%d TurnsMax=%d Price=%d", d.Type, d.CargoMax, d.TurnsMax, d.Price))
+		lines = append(lines, fmt.Sprintf("- %s: CargoMax=%d TurnsMax=%d Price=%d", d.Type, d.CargoMax, d.TurnsMax, p.Rules.shipPrice(d)))
 	}
 	lines = append(lines, fmt.Sprintf("Next cargo upgrade cost: %d", cargoUpgradeCost(p.ShipCargoUpgrades)))
 	lines = append(lines, fmt.Sprintf("Next turns upgrade cost: %d", turnsUpgradeCost(p.ShipTurnUpgrades)))
+	lines = append(lines, fmt.Sprintf("Scanner upgrade cost: %d (installed: %s; unlocks SCAN HOLO)", scannerPrice, yesNo(p.ShipScanner)))
 	return strings.Join(lines, "\n")
 }
 
@@ -116,7 +127,7 @@ func totalCargo(p *Player) int {
 	if p == nil {
 		return 0
 	}
-	return p.CargoOre + p.CargoOrganics + p.CargoEquipment
+	return p.CargoOre + p.CargoOrganics + p.CargoEquipment + p.CargoColonists + p.CargoContraband
 }
 
 func shipyardBuy(p *Player, shipType string) (phase2Result, error) {
@@ -127,14 +138,18 @@ func shipyardBuy(p *Player, shipType string) (phase2Result, error) {
 	if strings.EqualFold(p.ShipType, d.Type) {
 		return phase2Result{OK: false, Message: "You already own this ship type.", ErrorCode: "INVALID_SHIP"}, nil
 	}
-	if p.Credits < d.Price {
+	price := p.Rules.shipPrice(d)
+	if p.Credits < price {
 		return phase2Result{OK: false, Message: "Insufficient credits to buy that ship.", ErrorCode: "INSUFFICIENT_CREDITS"}, nil
 	}
 	if totalCargo(p) > d.CargoMax {
 		return phase2Result{OK: false, Message: "Your current cargo exceeds the capacity of that ship. Reduce cargo before buying.", ErrorCode: "CARGO_TOO_LARGE"}, nil
 	}
+	if p.ShipMines > d.MineRacks {
+		return phase2Result{OK: false, Message: fmt.Sprintf("That ship racks only %d mines. Deploy mines before buying.", d.MineRacks), ErrorCode: "MINES_TOO_MANY"}, nil
+	}
 
-	p.Credits -= d.Price
+	p.Credits -= price
 	p.ShipType = d.Type
 	p.ShipCargoUpgrades = 0
 	p.ShipTurnUpgrades = 0
@@ -144,7 +159,7 @@ func shipyardBuy(p *Player, shipType string) (phase2Result, error) {
 		p.Turns = p.TurnsMax
 	}
 
-	msg := fmt.Sprintf("Purchased %s for %d credits. CargoMax=%d, TurnsMax=%d.", d.Type, d.Price, p.CargoMax, p.TurnsMax)
+	msg := fmt.Sprintf("Purchased %s for %d credits. CargoMax=%d, TurnsMax=%d.", d.Type, price, p.CargoMax, p.TurnsMax)
 	return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
 }
 
@@ -161,8 +176,11 @@ func shipyardSell(p *Player) (phase2Result, error) {
 	if totalCargo(p) > scout.CargoMax {
 		return phase2Result{OK: false, Message: "Your cargo exceeds SCOUT capacity. Reduce cargo before selling.", ErrorCode: "CARGO_TOO_LARGE"}, nil
 	}
+	if p.ShipMines > scout.MineRacks {
+		return phase2Result{OK: false, Message: fmt.Sprintf("A SCOUT racks only %d mines. Deploy mines before selling.", scout.MineRacks), ErrorCode: "MINES_TOO_MANY"}, nil
+	}
 
-	resale := cur.Price * 70 / 100
+	resale := shipResaleValue(*p)
 	p.Credits += resale
 	p.ShipType = scout.Type
 	p.ShipCargoUpgrades = 0
@@ -207,7 +225,18 @@ func shipyardUpgrade(p *Player, which string) (phase2Result, error) {
 		p.TurnsMax += 10
 		msg := fmt.Sprintf("Turns capacity upgraded (+10). New TurnsMax=%d. Cost=%d.", p.TurnsMax, cost)
 		return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
+	case "SCANNER":
+		if p.ShipScanner {
+			return phase2Result{OK: false, Message: "A scanner is already installed.", ErrorCode: "MAX_UPGRADES"}, nil
+		}
+		if p.Credits < scannerPrice {
+			return phase2Result{OK: false, Message: "Insufficient credits for scanner upgrade.", ErrorCode: "INSUFFICIENT_CREDITS"}, nil
+		}
+		p.Credits -= scannerPrice
+		p.ShipScanner = true
+		msg := fmt.Sprintf("Scanner installed. SCAN HOLO is now available. Cost=%d.", scannerPrice)
+		return phase2Result{OK: true, Message: msg, Logs: []logToInsert{{kind: "ACTION", msg: msg}}}, nil
 	default:
-		return phase2Result{OK: false, Message: "Unknown upgrade type. Use CARGO or TURNS.", ErrorCode: "INVALID_UPGRADE"}, nil
+		return phase2Result{OK: false, Message: "Unknown upgrade type. Use CARGO, TURNS or SCANNER.", ErrorCode: "INVALID_UPGRADE"}, nil
 	}
 }
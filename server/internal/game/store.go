@@ -14,6 +14,7 @@ var ErrNotFound = errors.New("not found")
 
 func LoadPlayerForUpdate(ctx context.Context, tx pgx.Tx, playerID string) (Player, error) {
 	var p Player
+	var rulesRaw []byte
 	err := tx.QueryRow(ctx, `
 		SELECT
 			p.id,
@@ -27,6 +28,8 @@ func LoadPlayerForUpdate(ctx context.Context, tx pgx.Tx, playerID string) (Playe
 			p.ship_type,
 			p.ship_cargo_upgrades,
 			p.ship_turn_upgrades,
+			p.ship_mines,
+			p.ship_scanner,
 			p.turns,
 			p.turns_max,
 			p.sector_id,
@@ -34,9 +37,13 @@ func LoadPlayerForUpdate(ctx context.Context, tx pgx.Tx, playerID string) (Playe
 			p.cargo_ore,
 			p.cargo_organics,
 			p.cargo_equipment,
+			p.cargo_colonists,
+			p.cargo_contraband,
 			p.last_turn_regen,
 			p.season_id,
 			s.name,
+			s.frozen_at IS NOT NULL,
+			s.rules,
 			COALESCE(cm.corp_id, ''),
 			COALESCE(c.name, ''),
 			COALESCE(cm.role, ''),
@@ -62,6 +69,8 @@ func LoadPlayerForUpdate(ctx context.Context, tx pgx.Tx, playerID string) (Playe
 		&p.ShipType,
 		&p.ShipCargoUpgrades,
 		&p.ShipTurnUpgrades,
+		&p.ShipMines,
+		&p.ShipScanner,
 		&p.Turns,
 		&p.TurnsMax,
 		&p.SectorID,
@@ -69,9 +78,13 @@ func LoadPlayerForUpdate(ctx context.Context, tx pgx.Tx, playerID string) (Playe
 		&p.CargoOre,
 		&p.CargoOrganics,
 		&p.CargoEquipment,
+		&p.CargoColonists,
+		&p.CargoContraband,
 		&p.LastTurnRegen,
 		&p.SeasonID,
 		&p.SeasonName,
+		&p.SeasonFrozen,
+		&rulesRaw,
 		&p.CorpID,
 		&p.CorpName,
 		&p.CorpRole,
@@ -83,6 +96,17 @@ func LoadPlayerForUpdate(ctx context.Context, tx pgx.Tx, playerID string) (Playe
 	if err != nil {
 		return Player{}, err
 	}
+	if p.Rules, err = decodeSeasonRules(rulesRaw); err != nil {
+		return Player{}, err
+	}
+	if p.CorpID != "" {
+		if p.CorpPerms, err = loadCorpPermissions(ctx, tx, p.CorpID); err != nil {
+			return Player{}, err
+		}
+		if p.CorpPlanetAllies, err = loadPlanetAllies(ctx, tx, p.CorpID); err != nil {
+			return Player{}, err
+		}
+	}
 
 	// Keep the stored level consistent with XP for older rows or future formula adjustments.
 	if computed := LevelForXP(p.XP); computed > 0 && computed != p.Level {
@@ -111,10 +135,14 @@ func SavePlayer(ctx context.Context, tx pgx.Tx, p Player) error {
 			cargo_ore = $12,
 			cargo_organics = $13,
 			cargo_equipment = $14,
-			last_turn_regen = $15,
-			season_id = $16
+			cargo_colonists = $15,
+			last_turn_regen = $16,
+			season_id = $17,
+			cargo_contraband = $18,
+			ship_mines = $19,
+			ship_scanner = $20
 		WHERE id = $1
-	`, p.ID, p.Credits, p.XP, p.Level, p.ShipType, p.ShipCargoUpgrades, p.ShipTurnUpgrades, p.Turns, p.TurnsMax, p.SectorID, p.CargoMax, p.CargoOre, p.CargoOrganics, p.CargoEquipment, p.LastTurnRegen, p.SeasonID)
+	`, p.ID, p.Credits, p.XP, p.Level, p.ShipType, p.ShipCargoUpgrades, p.ShipTurnUpgrades, p.Turns, p.TurnsMax, p.SectorID, p.CargoMax, p.CargoOre, p.CargoOrganics, p.CargoEquipment, p.CargoColonists, p.LastTurnRegen, p.SeasonID, p.CargoContraband, p.ShipMines, p.ShipScanner)
 	return err
 }
 
@@ -185,7 +213,7 @@ func LoadSectorView(ctx context.Context, q interface {
 	QueryRow(context.Context, string, ...any) pgx.Row
 }, sectorID int) (SectorView, error) {
 	var s SectorView
-	if err := q.QueryRow(ctx, "SELECT id, name, is_protectorate, protectorate_fighters FROM sectors WHERE id = $1", sectorID).Scan(&s.ID, &s.Name, &s.IsProtectorate, &s.ProtectorateFighters); err != nil {
+	if err := q.QueryRow(ctx, "SELECT id, name, region, description, environment, is_protectorate, protectorate_fighters FROM sectors WHERE id = $1", sectorID).Scan(&s.ID, &s.Name, &s.Region, &s.Description, &s.Environment, &s.IsProtectorate, &s.ProtectorateFighters); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return SectorView{}, ErrNotFound
 		}
@@ -209,6 +237,12 @@ func LoadSectorView(ctx context.Context, q interface {
 		return SectorView{}, err
 	}
 
+	gates, err := loadSectorGates(ctx, q, sectorID)
+	if err != nil {
+		return SectorView{}, err
+	}
+	s.Gates = gates
+
 	// Event (optional)
 	var activeEv *ActiveEvent
 	if ev, ok, err := LoadActiveEvent(ctx, q, sectorID); err != nil {
@@ -218,17 +252,26 @@ func LoadSectorView(ctx context.Context, q interface {
 		s.Event = &v
 		activeEv = &ev
 	}
+	var eventKind string
+	if activeEv != nil {
+		eventKind = activeEv.Kind
+	}
+	s.Environment = effectiveEnvironment(s.Environment, eventKind)
 
 	// Port (optional)
 	var pr PortRow
+	var portName, portClass string
+	var contrabandPrice int
 	perr := q.QueryRow(ctx, `
 		SELECT
+			name, port_class, contraband_price,
 			ore_mode, ore_qty, ore_base_qty, ore_base_price,
 			organics_mode, organics_qty, organics_base_qty, organics_base_price,
 			equipment_mode, equipment_qty, equipment_base_qty, equipment_base_price
 		FROM ports
 		WHERE sector_id = $1
 	`, sectorID).Scan(
+		&portName, &portClass, &contrabandPrice,
 		&pr.OreMode, &pr.OreQty, &pr.OreBaseQty, &pr.OreBasePrice,
 		&pr.OrganicsMode, &pr.OrganicsQty, &pr.OrganicsBaseQty, &pr.OrganicsBasePrice,
 		&pr.EquipmentMode, &pr.EquipmentQty, &pr.EquipmentBaseQty, &pr.EquipmentBasePrice,
@@ -243,6 +286,8 @@ func LoadSectorView(ctx context.Context, q interface {
 			eqPct = pricePercentForCommodity(*activeEv, "EQUIPMENT")
 		}
 		pv := &PortView{
+			Name:             portName,
+			Class:            portClass,
 			OreMode:          pr.OreMode,
 			OreQty:           pr.OreQty,
 			OreBaseQty:       pr.OreBaseQty,
@@ -256,6 +301,10 @@ func LoadSectorView(ctx context.Context, q interface {
 			EquipmentBaseQty: pr.EquipmentBaseQty,
 			EquipmentPrice:   PricePerUnitWithPercent(pr.EquipmentBasePrice, pr.EquipmentBaseQty, pr.EquipmentQty, eqPct),
 		}
+		if portClass == PortClassBlackMarket {
+			pv.ContrabandPrice = contrabandPrice
+			pv.ContrabandBid = blackMarketBid(contrabandPrice)
+		}
 		s.Port = pv
 	} else if !errors.Is(perr, pgx.ErrNoRows) {
 		return SectorView{}, perr
@@ -282,7 +331,10 @@ func LoadSectorView(ctx context.Context, q interface {
 			pl.storage_organics,
 			pl.storage_equipment,
 			pl.storage_max,
-			pl.citadel_level
+			pl.citadel_level,
+			pl.planet_class,
+			pl.population,
+			pl.shortage
 		FROM planets pl
 		LEFT JOIN players op ON op.id = pl.owner_player_id
 		LEFT JOIN users u ON u.id = op.user_id
@@ -303,6 +355,9 @@ func LoadSectorView(ctx context.Context, q interface {
 		&planet.StorageEquipment,
 		&planet.StorageMax,
 		&planet.CitadelLevel,
+		&planet.Class,
+		&planet.Population,
+		&planet.Shortage,
 	)
 	if plErr == nil {
 		if ownerCorpID.Valid && ownerCorpName != "" {
@@ -317,8 +372,19 @@ func LoadSectorView(ctx context.Context, q interface {
 		return SectorView{}, plErr
 	}
 
-	// Mines (sum)
-	_ = q.QueryRow(ctx, "SELECT COALESCE(SUM(qty),0) FROM mines WHERE sector_id=$1", sectorID).Scan(&s.Mines)
+	// Beacon (optional)
+	if b, ok, err := loadBeacon(ctx, q, sectorID); err != nil {
+		return SectorView{}, err
+	} else if ok {
+		s.Beacon = &b
+	}
+
+	// Mines (sum); hidden inside a nebula.
+	if s.Environment == EnvNebula {
+		s.MinesHidden = true
+	} else {
+		_ = q.QueryRow(ctx, "SELECT COALESCE(SUM(qty),0) FROM mines WHERE sector_id=$1", sectorID).Scan(&s.Mines)
+	}
 
 	return s, nil
 }
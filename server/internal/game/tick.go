@@ -2,7 +2,6 @@ package game
 
 import (
 	"context"
-	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -11,47 +10,22 @@ func StartPortTicker(ctx context.Context, pool *pgxpool.Pool, tickSeconds int) {
 	if tickSeconds < 5 {
 		tickSeconds = 5
 	}
-	ticker := time.NewTicker(time.Duration(tickSeconds) * time.Second)
-
-	go func() {
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				_, _ = pool.Exec(ctx, `
-					UPDATE ports SET
-						ore_qty = LEAST(ore_base_qty, ore_qty + ore_regen),
-						organics_qty = LEAST(organics_base_qty, organics_qty + organics_regen),
-						equipment_qty = LEAST(equipment_base_qty, equipment_qty + equipment_regen)
-				`)
-			}
-		}
-	}()
+	pick := func(r SeasonRules) int { return r.PortTickSeconds }
+	startRulesTicker(ctx, pool, tickSeconds, 5, pick, func() {
+		_, _ = pool.Exec(ctx, `
+			UPDATE ports SET
+				ore_qty = LEAST(ore_base_qty, ore_qty + ore_regen),
+				organics_qty = LEAST(organics_base_qty, organics_qty + organics_regen),
+				equipment_qty = LEAST(equipment_base_qty, equipment_qty + equipment_regen)
+		`)
+	})
 }
 
+// StartPlanetTicker runs planet production chains (see runPlanetTick).
 func StartPlanetTicker(ctx context.Context, pool *pgxpool.Pool, tickSeconds int) {
 	if tickSeconds < 5 {
 		tickSeconds = 5
 	}
-	ticker := time.NewTicker(time.Duration(tickSeconds) * time.Second)
-
-	go func() {
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				_, _ = pool.Exec(ctx, `
-					UPDATE planets SET
-						storage_ore = LEAST(storage_max, storage_ore + production_ore),
-						storage_organics = LEAST(storage_max, storage_organics + production_organics),
-						storage_equipment = LEAST(storage_max, storage_equipment + production_equipment),
-						last_produced = now()
-				`)
-			}
-		}
-	}()
+	pick := func(r SeasonRules) int { return r.PlanetTickSeconds }
+	startRulesTicker(ctx, pool, tickSeconds, 5, pick, func() { _ = runPlanetTick(ctx, pool) })
 }
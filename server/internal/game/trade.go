@@ -34,6 +34,9 @@ func executeTrade(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRequest)
 	if action != "BUY" && action != "SELL" {
 		return "Trade action must be BUY or SELL.", false, nil
 	}
+	if commodity == "COLONISTS" {
+		return tradeColonists(ctx, tx, p, action, qty)
+	}
 	if commodity != "ORE" && commodity != "ORGANICS" && commodity != "EQUIPMENT" {
 		return "Commodity must be ORE, ORGANICS, or EQUIPMENT.", false, nil
 	}
@@ -62,8 +65,7 @@ func executeTrade(ctx context.Context, tx pgx.Tx, p *Player, cmd CommandRequest)
 		return "Trade failed.", false, err
 	}
 
-	totalCargo := p.CargoOre + p.CargoOrganics + p.CargoEquipment
-	freeCargo := p.CargoMax - totalCargo
+	freeCargo := p.CargoMax - totalCargo(p)
 	if freeCargo < 0 {
 		freeCargo = 0
 	}
@@ -16,23 +16,32 @@ type Player struct {
 	ShipType          string
 	ShipCargoUpgrades int
 	ShipTurnUpgrades  int
-
-	Turns          int
-	TurnsMax       int
-	SectorID       int
-	CargoMax       int
-	CargoOre       int
-	CargoOrganics  int
-	CargoEquipment int
-	LastTurnRegen  time.Time
-
-	SeasonID   int
-	SeasonName string
+	ShipMines         int
+	ShipScanner       bool
+
+	Turns           int
+	TurnsMax        int
+	SectorID        int
+	CargoMax        int
+	CargoOre        int
+	CargoOrganics   int
+	CargoEquipment  int
+	CargoColonists  int
+	CargoContraband int
+	LastTurnRegen   time.Time
+
+	SeasonID     int
+	SeasonName   string
+	SeasonFrozen bool
+	Rules        SeasonRules
 
 	CorpID      string
 	CorpName    string
 	CorpRole    string
 	CorpCredits int64
+	CorpPerms   CorpPermissions
+	// Corps allied with shared planets; their planets are usable like the player's own corp's.
+	CorpPlanetAllies map[string]bool
 }
 
 type PlayerState struct {
@@ -49,6 +58,8 @@ type PlayerState struct {
 	ShipType          string `json:"ship_type"`
 	ShipCargoUpgrades int    `json:"ship_cargo_upgrades"`
 	ShipTurnUpgrades  int    `json:"ship_turn_upgrades"`
+	ShipMines         int    `json:"ship_mines"`
+	ShipScanner       bool   `json:"ship_scanner"`
 	Turns             int    `json:"turns"`
 	TurnsMax          int    `json:"turns_max"`
 	SectorID          int    `json:"sector_id"`
@@ -56,6 +67,8 @@ type PlayerState struct {
 	CargoOre          int    `json:"cargo_ore"`
 	CargoOrganics     int    `json:"cargo_organics"`
 	CargoEquipment    int    `json:"cargo_equipment"`
+	CargoColonists    int    `json:"cargo_colonists"`
+	CargoContraband   int    `json:"cargo_contraband"`
 
 	SeasonID   int    `json:"season_id"`
 	SeasonName string `json:"season_name"`
@@ -92,6 +105,8 @@ func (p Player) ToState() PlayerState {
 		ShipType:          p.ShipType,
 		ShipCargoUpgrades: p.ShipCargoUpgrades,
 		ShipTurnUpgrades:  p.ShipTurnUpgrades,
+		ShipMines:         p.ShipMines,
+		ShipScanner:       p.ShipScanner,
 		Turns:             p.Turns,
 		TurnsMax:          p.TurnsMax,
 		SectorID:          p.SectorID,
@@ -99,6 +114,8 @@ func (p Player) ToState() PlayerState {
 		CargoOre:          p.CargoOre,
 		CargoOrganics:     p.CargoOrganics,
 		CargoEquipment:    p.CargoEquipment,
+		CargoColonists:    p.CargoColonists,
+		CargoContraband:   p.CargoContraband,
 		SeasonID:          p.SeasonID,
 		SeasonName:        p.SeasonName,
 		CorpID:            p.CorpID,
@@ -109,6 +126,8 @@ func (p Player) ToState() PlayerState {
 }
 
 type PortView struct {
+	Name             string `json:"name,omitempty"`
+	Class            string `json:"class"`
 	OreMode          string `json:"ore_mode"`
 	OreQty           int    `json:"ore_qty"`
 	OreBaseQty       int    `json:"ore_base_qty"`
@@ -121,6 +140,9 @@ type PortView struct {
 	EquipmentQty     int    `json:"equipment_qty"`
 	EquipmentBaseQty int    `json:"equipment_base_qty"`
 	EquipmentPrice   int    `json:"equipment_price"`
+	// Black markets only: what they charge for contraband and what they pay for it.
+	ContrabandPrice int `json:"contraband_price,omitempty"`
+	ContrabandBid   int `json:"contraband_bid,omitempty"`
 }
 
 type PlanetView struct {
@@ -136,6 +158,9 @@ type PlanetView struct {
 	StorageEquipment    int    `json:"storage_equipment"`
 	StorageMax          int    `json:"storage_max"`
 	CitadelLevel        int    `json:"citadel_level"`
+	Class               string `json:"class"`
+	Population          int    `json:"population"`
+	Shortage            string `json:"shortage,omitempty"`
 }
 
 type EventView struct {
@@ -152,14 +177,32 @@ type EventView struct {
 type SectorView struct {
 	ID                   int         `json:"id"`
 	Name                 string      `json:"name"`
+	Region               string      `json:"region,omitempty"`
+	Description          string      `json:"description,omitempty"`
+	Environment          string      `json:"environment"`
 	IsProtectorate       bool        `json:"is_protectorate"`
 	ProtectorateFighters int         `json:"protectorate_fighters"`
 	HasShipyard          bool        `json:"has_shipyard"`
 	Warps                []int       `json:"warps"`
+	Gates                []GateView  `json:"gates,omitempty"` // warps in Warps that are player-built gates
 	Port                 *PortView   `json:"port,omitempty"`
 	Planet               *PlanetView `json:"planet,omitempty"`
 	Event                *EventView  `json:"event,omitempty"`
+	Beacon               *BeaconView `json:"beacon,omitempty"`
 	Mines                int         `json:"mines"`
+	MinesHidden          bool        `json:"mines_hidden,omitempty"` // nebula: mine count unknown
+}
+
+type GateView struct {
+	To    int    `json:"to"`
+	Owner string `json:"owner"`
+	Toll  int64  `json:"toll"`
+}
+
+type BeaconView struct {
+	Message  string    `json:"message"`
+	Author   string    `json:"author"`
+	PlacedAt time.Time `json:"placed_at"`
 }
 
 type CommandRequest struct {
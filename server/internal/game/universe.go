@@ -2,7 +2,6 @@ package game
 
 import (
 	"context"
-	"fmt"
 	"math/rand"
 	"time"
 
@@ -13,6 +12,9 @@ import (
 type UniverseConfig struct {
 	Seed    int64
 	Sectors int
+	// Generator selects the warp topology (RING, CLUSTERED, TREE or ONEWAY); Params are its knobs.
+	Generator string
+	Params    GeneratorParams
 }
 
 func EnsureUniverse(ctx context.Context, pool *pgxpool.Pool, cfg UniverseConfig) error {
@@ -27,40 +29,33 @@ func EnsureUniverse(ctx context.Context, pool *pgxpool.Pool, cfg UniverseConfig)
 	if cfg.Sectors < 20 {
 		cfg.Sectors = 20
 	}
+	gen, err := NewUniverseGenerator(cfg.Generator, cfg.Params)
+	if err != nil {
+		return err
+	}
 	rng := rand.New(rand.NewSource(cfg.Seed))
+	// Names use their own stream so the topology for a given seed does not depend on them.
+	names := newNameGenerator(cfg.Seed)
 
 	// Insert sectors
 	batch := &pgx.Batch{}
+	region := ""
 	for i := 1; i <= cfg.Sectors; i++ {
-		name := fmt.Sprintf("Sector %d", i)
-		batch.Queue("INSERT INTO sectors(id, name) VALUES ($1, $2)", i, name)
+		if (i-1)%universeRegionSize == 0 {
+			region = names.RegionName()
+		}
+		queueNamedSector(batch, names, i, region)
 	}
 	br := pool.SendBatch(ctx, batch)
 	if err := br.Close(); err != nil {
 		return err
 	}
 
-	// Create a ring for guaranteed connectivity (bidirectional)
+	// Warp topology comes from the configured generator. Reachability of Protectorate space is
+	// enforced afterwards by EnsureProtectorateReachability.
 	warpBatch := &pgx.Batch{}
-	for i := 1; i <= cfg.Sectors; i++ {
-		next := i + 1
-		if next > cfg.Sectors {
-			next = 1
-		}
-		warpBatch.Queue("INSERT INTO warps(from_sector, to_sector, one_way) VALUES ($1,$2,false) ON CONFLICT DO NOTHING", i, next)
-		warpBatch.Queue("INSERT INTO warps(from_sector, to_sector, one_way) VALUES ($1,$2,false) ON CONFLICT DO NOTHING", next, i)
-	}
-	// Add random extra connections
-	for i := 1; i <= cfg.Sectors; i++ {
-		extra := 2 + rng.Intn(3) // 2..4 extra edges
-		for j := 0; j < extra; j++ {
-			to := 1 + rng.Intn(cfg.Sectors)
-			if to == i {
-				continue
-			}
-			warpBatch.Queue("INSERT INTO warps(from_sector, to_sector, one_way) VALUES ($1,$2,false) ON CONFLICT DO NOTHING", i, to)
-			warpBatch.Queue("INSERT INTO warps(from_sector, to_sector, one_way) VALUES ($1,$2,false) ON CONFLICT DO NOTHING", to, i)
-		}
+	for _, w := range gen.Warps(rng, cfg.Sectors) {
+		warpBatch.Queue("INSERT INTO warps(from_sector, to_sector, one_way) VALUES ($1,$2,$3) ON CONFLICT DO NOTHING", w.From, w.To, w.OneWay)
 	}
 	wbr := pool.SendBatch(ctx, warpBatch)
 	if err := wbr.Close(); err != nil {
@@ -73,49 +68,7 @@ func EnsureUniverse(ctx context.Context, pool *pgxpool.Pool, cfg UniverseConfig)
 		if rng.Float64() > 0.60 {
 			continue
 		}
-
-		oreMode := pickMode(rng)
-		orgMode := pickMode(rng)
-		eqMode := pickMode(rng)
-
-		// Ensure at least one BUY and one SELL across commodities
-		if oreMode == orgMode && orgMode == eqMode {
-			eqMode = flipMode(eqMode)
-		}
-
-		oreBaseQty := 4000 + rng.Intn(4001) // 4000..8000
-		orgBaseQty := 2000 + rng.Intn(3001) // 2000..5000
-		eqBaseQty := 1500 + rng.Intn(2501)  // 1500..4000
-
-		oreBasePrice := 8 + rng.Intn(7)   // 8..14
-		orgBasePrice := 15 + rng.Intn(16) // 15..30
-		eqBasePrice := 40 + rng.Intn(41)  // 40..80
-
-		oreRegen := max(50, oreBaseQty/25) // ~4%% per tick
-		orgRegen := max(30, orgBaseQty/25)
-		eqRegen := max(20, eqBaseQty/25)
-
-		oreQty := initialQty(oreMode, oreBaseQty)
-		orgQty := initialQty(orgMode, orgBaseQty)
-		eqQty := initialQty(eqMode, eqBaseQty)
-
-		portBatch.Queue(
-			`INSERT INTO ports(
-				sector_id,
-				ore_mode, ore_qty, ore_base_qty, ore_base_price, ore_regen,
-				organics_mode, organics_qty, organics_base_qty, organics_base_price, organics_regen,
-				equipment_mode, equipment_qty, equipment_base_qty, equipment_base_price, equipment_regen
-			) VALUES (
-				$1,
-				$2,$3,$4,$5,$6,
-				$7,$8,$9,$10,$11,
-				$12,$13,$14,$15,$16
-			)`,
-			i,
-			oreMode, oreQty, oreBaseQty, oreBasePrice, oreRegen,
-			orgMode, orgQty, orgBaseQty, orgBasePrice, orgRegen,
-			eqMode, eqQty, eqBaseQty, eqBasePrice, eqRegen,
-		)
+		queueRandomPort(portBatch, rng, i, names.PortName())
 	}
 	pbr := pool.SendBatch(ctx, portBatch)
 	if err := pbr.Close(); err != nil {
@@ -128,16 +81,7 @@ func EnsureUniverse(ctx context.Context, pool *pgxpool.Pool, cfg UniverseConfig)
 		if rng.Float64() > 0.35 {
 			continue
 		}
-		name := fmt.Sprintf("Planet %d", i)
-		prodOre := 10 + rng.Intn(21)        // 10..30 per tick
-		prodOrg := 5 + rng.Intn(16)         // 5..20 per tick
-		prodEq := 2 + rng.Intn(9)           // 2..10 per tick
-		storageMax := 2000 + rng.Intn(3001) // 2000..5000 per commodity
-		planetBatch.Queue(
-			`INSERT INTO planets(sector_id, name, production_ore, production_organics, production_equipment, storage_max)
-			 VALUES ($1,$2,$3,$4,$5,$6)`,
-			i, name, prodOre, prodOrg, prodEq, storageMax,
-		)
+		queueRandomPlanet(planetBatch, rng, i, names.PlanetName())
 	}
 	plbr := pool.SendBatch(ctx, planetBatch)
 	if err := plbr.Close(); err != nil {
@@ -150,6 +94,75 @@ func EnsureUniverse(ctx context.Context, pool *pgxpool.Pool, cfg UniverseConfig)
 	return nil
 }
 
+// queueNamedSector queues sector id with a generated name and optional flavor description.
+func queueNamedSector(batch *pgx.Batch, names *nameGenerator, id int, region string) {
+	batch.Queue(
+		"INSERT INTO sectors(id, name, region, description) VALUES ($1, $2, $3, $4)",
+		id, names.SectorName(), region, names.SectorDescription(),
+	)
+}
+
+// queueRandomPort queues a port with randomized modes, stock and prices for sector id.
+func queueRandomPort(batch *pgx.Batch, rng *rand.Rand, id int, name string) {
+	oreMode := pickMode(rng)
+	orgMode := pickMode(rng)
+	eqMode := pickMode(rng)
+
+	// Ensure at least one BUY and one SELL across commodities
+	if oreMode == orgMode && orgMode == eqMode {
+		eqMode = flipMode(eqMode)
+	}
+
+	oreBaseQty := 4000 + rng.Intn(4001) // 4000..8000
+	orgBaseQty := 2000 + rng.Intn(3001) // 2000..5000
+	eqBaseQty := 1500 + rng.Intn(2501)  // 1500..4000
+
+	oreBasePrice := 8 + rng.Intn(7)   // 8..14
+	orgBasePrice := 15 + rng.Intn(16) // 15..30
+	eqBasePrice := 40 + rng.Intn(41)  // 40..80
+
+	oreRegen := max(50, oreBaseQty/25) // ~4%% per tick
+	orgRegen := max(30, orgBaseQty/25)
+	eqRegen := max(20, eqBaseQty/25)
+
+	oreQty := initialQty(oreMode, oreBaseQty)
+	orgQty := initialQty(orgMode, orgBaseQty)
+	eqQty := initialQty(eqMode, eqBaseQty)
+
+	batch.Queue(
+		`INSERT INTO ports(
+			sector_id, name,
+			ore_mode, ore_qty, ore_base_qty, ore_base_price, ore_regen,
+			organics_mode, organics_qty, organics_base_qty, organics_base_price, organics_regen,
+			equipment_mode, equipment_qty, equipment_base_qty, equipment_base_price, equipment_regen
+		) VALUES (
+			$1, $17,
+			$2,$3,$4,$5,$6,
+			$7,$8,$9,$10,$11,
+			$12,$13,$14,$15,$16
+		)`,
+		id,
+		oreMode, oreQty, oreBaseQty, oreBasePrice, oreRegen,
+		orgMode, orgQty, orgBaseQty, orgBasePrice, orgRegen,
+		eqMode, eqQty, eqBaseQty, eqBasePrice, eqRegen,
+		name,
+	)
+}
+
+// queueRandomPlanet queues an unowned planet with randomized production for sector id.
+func queueRandomPlanet(batch *pgx.Batch, rng *rand.Rand, id int, name string) {
+	prodOre := 10 + rng.Intn(21)        // 10..30 per tick
+	prodOrg := 5 + rng.Intn(16)         // 5..20 per tick
+	prodEq := 2 + rng.Intn(9)           // 2..10 per tick
+	storageMax := 2000 + rng.Intn(3001) // 2000..5000 per commodity
+	class := planetRecipes[rng.Intn(len(planetRecipes))].Class
+	batch.Queue(
+		`INSERT INTO planets(sector_id, name, planet_class, production_ore, production_organics, production_equipment, storage_max)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+		id, name, class, prodOre, prodOrg, prodEq, storageMax,
+	)
+}
+
 func pickMode(rng *rand.Rand) string {
 	if rng.Intn(2) == 0 {
 		return "BUY"
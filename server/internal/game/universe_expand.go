@@ -0,0 +1,256 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const expandMaxSectors = 1000
+
+// ErrInvalidExpansion wraps request problems (bad size, generator or gateway) as opposed to db errors.
+var ErrInvalidExpansion = errors.New("invalid expansion")
+
+type ExpandUniverseRequest struct {
+	Sectors   int
+	Seed      int64 // 0 picks a time-based seed
+	Generator string
+	Params    GeneratorParams
+	// Frontier grafts the new sectors on only through Gateways (existing sector IDs). With no
+	// gateways a random non-Protectorate sector is chosen.
+	Frontier bool
+	Gateways []int
+}
+
+type ExpandUniverseResult struct {
+	FirstSectorID int       `json:"first_sector_id"`
+	LastSectorID  int       `json:"last_sector_id"`
+	Generator     string    `json:"generator"`
+	Regions       []string  `json:"regions"`
+	Seed          int64     `json:"seed"`
+	Warps         int       `json:"warps"`
+	Links         [][2]int  `json:"links"` // two-way lanes joining old and new sectors
+	Gateways      []int     `json:"gateways,omitempty"`
+	Ports         int       `json:"ports"`
+	Planets       int       `json:"planets"`
+	RescueLinks   int       `json:"rescue_links"` // lanes added by the Protectorate reachability pass
+	At            time.Time `json:"at"`
+}
+
+// ExpandUniverse appends sectors to a running universe in one transaction. The new sectors get
+// warps from the chosen generator, ports and planets on the same odds as EnsureUniverse, and are
+// grafted onto the existing graph. Existing sectors, discoveries and intel are left untouched.
+func ExpandUniverse(ctx context.Context, pool *pgxpool.Pool, req ExpandUniverseRequest) (ExpandUniverseResult, error) {
+	if req.Sectors < 1 || req.Sectors > expandMaxSectors {
+		return ExpandUniverseResult{}, fmt.Errorf("%w: sectors must be between 1 and %d", ErrInvalidExpansion, expandMaxSectors)
+	}
+	gen, err := NewUniverseGenerator(req.Generator, req.Params)
+	if err != nil {
+		return ExpandUniverseResult{}, fmt.Errorf("%w: %v", ErrInvalidExpansion, err)
+	}
+	if len(req.Gateways) > 0 && !req.Frontier {
+		return ExpandUniverseResult{}, fmt.Errorf("%w: gateways only apply to a frontier expansion", ErrInvalidExpansion)
+	}
+	seed := req.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return ExpandUniverseResult{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Serialize expansions; readers (moves, scans) are not blocked.
+	if _, err := tx.Exec(ctx, "LOCK TABLE sectors IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return ExpandUniverseResult{}, err
+	}
+
+	names := newNameGenerator(seed)
+	rows, err := tx.Query(ctx, "SELECT id, name, is_protectorate FROM sectors ORDER BY id")
+	if err != nil {
+		return ExpandUniverseResult{}, err
+	}
+	var existing, candidates []int
+	known := map[int]bool{}
+	for rows.Next() {
+		var id int
+		var name string
+		var prot bool
+		if err := rows.Scan(&id, &name, &prot); err != nil {
+			rows.Close()
+			return ExpandUniverseResult{}, err
+		}
+		names.reserve(name)
+		existing = append(existing, id)
+		known[id] = true
+		if !prot {
+			candidates = append(candidates, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return ExpandUniverseResult{}, err
+	}
+	if len(existing) == 0 {
+		return ExpandUniverseResult{}, fmt.Errorf("%w: the universe has not been generated yet", ErrInvalidExpansion)
+	}
+	if err := reserveExistingNames(ctx, tx, names); err != nil {
+		return ExpandUniverseResult{}, err
+	}
+	for _, g := range req.Gateways {
+		if !known[g] {
+			return ExpandUniverseResult{}, fmt.Errorf("%w: gateway sector %d does not exist", ErrInvalidExpansion, g)
+		}
+	}
+
+	base := existing[len(existing)-1]
+	res := ExpandUniverseResult{
+		FirstSectorID: base + 1,
+		LastSectorID:  base + req.Sectors,
+		Generator:     gen.Name(),
+		Seed:          seed,
+		At:            time.Now().UTC(),
+	}
+
+	// A frontier is one named region; otherwise new sectors get regions in the usual blocks.
+	batch := &pgx.Batch{}
+	region := ""
+	for i := res.FirstSectorID; i <= res.LastSectorID; i++ {
+		if region == "" || (!req.Frontier && (i-res.FirstSectorID)%universeRegionSize == 0) {
+			region = names.RegionName()
+			res.Regions = append(res.Regions, region)
+		}
+		queueNamedSector(batch, names, i, region)
+	}
+
+	// Generators build sectors 1..n; shift them past the current highest ID.
+	for _, w := range gen.Warps(rng, req.Sectors) {
+		batch.Queue("INSERT INTO warps(from_sector, to_sector, one_way) VALUES ($1,$2,$3) ON CONFLICT DO NOTHING", w.From+base, w.To+base, w.OneWay)
+		res.Warps++
+	}
+
+	if req.Frontier {
+		res.Gateways = append([]int(nil), req.Gateways...)
+		if len(res.Gateways) == 0 {
+			choices := candidates
+			if len(choices) == 0 {
+				choices = existing
+			}
+			res.Gateways = []int{choices[rng.Intn(len(choices))]}
+		}
+	}
+	res.Links = graftLinks(rng, existing, res.FirstSectorID, res.LastSectorID, res.Gateways)
+	for _, l := range res.Links {
+		for _, e := range [][2]int{{l[0], l[1]}, {l[1], l[0]}} {
+			batch.Queue("INSERT INTO warps(from_sector, to_sector, one_way) VALUES ($1,$2,false) ON CONFLICT DO NOTHING", e[0], e[1])
+		}
+	}
+
+	for i := res.FirstSectorID; i <= res.LastSectorID; i++ {
+		if rng.Float64() > 0.60 {
+			continue
+		}
+		queueRandomPort(batch, rng, i, names.PortName())
+		res.Ports++
+	}
+	for i := res.FirstSectorID; i <= res.LastSectorID; i++ {
+		if rng.Float64() > 0.35 {
+			continue
+		}
+		queueRandomPlanet(batch, rng, i, names.PlanetName())
+		res.Planets++
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return ExpandUniverseResult{}, err
+	}
+
+	// New ports take their share of the special classes.
+	if _, err := ensurePortClassesTx(ctx, tx, seed); err != nil {
+		return ExpandUniverseResult{}, err
+	}
+	if err := ensureSectorEnvironmentsTx(ctx, tx, seed); err != nil {
+		return ExpandUniverseResult{}, err
+	}
+
+	// One-way lanes from the generator can still leave new sectors cut off from Protectorate space.
+	// The pass links to the numerically nearest reaching sector; in a frontier the first new sector
+	// is the gateway entry and always reaches, so rescue lanes stay inside the region.
+	res.RescueLinks, err = ensureProtectorateReachabilityTx(ctx, tx)
+	if err != nil {
+		return ExpandUniverseResult{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ExpandUniverseResult{}, err
+	}
+	return res, nil
+}
+
+// graftLinks picks the two-way lanes joining sectors first..last to the existing graph.
+// With gateways every lane starts at a gateway, so the new region is only reachable through them;
+// otherwise roughly one lane per ten new sectors joins random old and new sectors.
+func graftLinks(rng *rand.Rand, existing []int, first, last int, gateways []int) [][2]int {
+	n := last - first + 1
+	var links [][2]int
+	seen := map[[2]int]bool{}
+	add := func(old, fresh int) {
+		l := [2]int{old, fresh}
+		if !seen[l] {
+			seen[l] = true
+			links = append(links, l)
+		}
+	}
+	if len(gateways) > 0 {
+		for i, g := range gateways {
+			// Spread gateways across the new region; the first gateway enters at its first sector.
+			entry := first
+			if i > 0 {
+				entry = first + rng.Intn(n)
+			}
+			add(g, entry)
+		}
+	} else {
+		count := max(1, n/10)
+		for i := 0; i < count; i++ {
+			add(existing[rng.Intn(len(existing))], first+rng.Intn(n))
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i][0] != links[j][0] {
+			return links[i][0] < links[j][0]
+		}
+		return links[i][1] < links[j][1]
+	})
+	return links
+}
+
+// reserveExistingNames marks region, port and planet names already in the galaxy as used so an
+// expansion never repeats them. Sector names are reserved while the sectors are loaded.
+func reserveExistingNames(ctx context.Context, tx pgx.Tx, names *nameGenerator) error {
+	rows, err := tx.Query(ctx, `
+		SELECT region FROM sectors WHERE region <> ''
+		UNION SELECT name FROM ports
+		UNION SELECT name FROM planets
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		names.reserve(name)
+	}
+	return rows.Err()
+}
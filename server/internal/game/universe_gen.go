@@ -0,0 +1,509 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	GeneratorRing      = "RING"
+	GeneratorClustered = "CLUSTERED"
+	GeneratorTree      = "TREE"
+	GeneratorOneWay    = "ONEWAY"
+)
+
+// Warp is one directed warp lane. Two-way lanes are stored as a pair of rows.
+type Warp struct {
+	From   int
+	To     int
+	OneWay bool
+}
+
+// UniverseGenerator builds the warp topology for sectors 1..n.
+type UniverseGenerator interface {
+	Name() string
+	Warps(rng *rand.Rand, sectors int) []Warp
+}
+
+// GeneratorParams are the generator-specific knobs from UNIVERSE_GENERATOR_PARAMS ("key=value,...").
+type GeneratorParams map[string]int
+
+// ParseGeneratorParams parses "regions=6,chokepoints=1" into params. Values must be non-negative integers.
+func ParseGeneratorParams(s string) (GeneratorParams, error) {
+	out := GeneratorParams{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("generator param %q must be key=value", part)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("generator param %q must be a non-negative integer", part)
+		}
+		out[strings.ToLower(strings.TrimSpace(k))] = n
+	}
+	return out, nil
+}
+
+func (gp GeneratorParams) get(key string, def int) int {
+	if v, ok := gp[key]; ok {
+		return v
+	}
+	return def
+}
+
+// NewUniverseGenerator returns the named generator configured from params. Unknown names and
+// parameters are rejected so a typo in the config does not silently fall back to defaults.
+func NewUniverseGenerator(name string, params GeneratorParams) (UniverseGenerator, error) {
+	allowed := map[string][]string{
+		GeneratorRing:      {"extra_min", "extra_max", "one_way_pct"},
+		GeneratorClustered: {"regions", "chokepoints", "extra_min", "extra_max", "one_way_pct"},
+		GeneratorTree:      {"branching", "loops_pct", "one_way_pct"},
+		GeneratorOneWay:    {"extra_min", "extra_max", "one_way_pct"},
+	}
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		name = GeneratorRing
+	}
+	keys, ok := allowed[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown universe generator %q (use RING, CLUSTERED, TREE or ONEWAY)", name)
+	}
+	for k := range params {
+		known := false
+		for _, a := range keys {
+			known = known || a == k
+		}
+		if !known {
+			return nil, fmt.Errorf("generator %s does not accept param %q", name, k)
+		}
+	}
+	defOneWay := 0
+	if name == GeneratorOneWay {
+		defOneWay = 50
+	}
+	oneWay := params.get("one_way_pct", defOneWay)
+	if oneWay > 100 {
+		return nil, fmt.Errorf("one_way_pct must be 0..100")
+	}
+
+	switch name {
+	case GeneratorClustered:
+		g := clusteredGenerator{
+			Regions:     params.get("regions", 6),
+			Chokepoints: params.get("chokepoints", 1),
+			ExtraMin:    params.get("extra_min", 1),
+			ExtraMax:    params.get("extra_max", 3),
+			OneWayPct:   oneWay,
+		}
+		if g.Regions < 2 || g.Chokepoints < 1 || g.ExtraMax < g.ExtraMin {
+			return nil, fmt.Errorf("CLUSTERED needs regions>=2, chokepoints>=1 and extra_max>=extra_min")
+		}
+		return g, nil
+	case GeneratorTree:
+		g := treeGenerator{
+			Branching: params.get("branching", 3),
+			LoopsPct:  params.get("loops_pct", 10),
+			OneWayPct: oneWay,
+		}
+		if g.Branching < 1 || g.LoopsPct > 100 {
+			return nil, fmt.Errorf("TREE needs branching>=1 and loops_pct 0..100")
+		}
+		return g, nil
+	case GeneratorOneWay:
+		g := oneWayGenerator{
+			ExtraMin:  params.get("extra_min", 1),
+			ExtraMax:  params.get("extra_max", 3),
+			OneWayPct: oneWay,
+		}
+		if g.ExtraMax < g.ExtraMin {
+			return nil, fmt.Errorf("ONEWAY needs extra_max>=extra_min")
+		}
+		return g, nil
+	default:
+		g := ringGenerator{
+			ExtraMin:  params.get("extra_min", 2),
+			ExtraMax:  params.get("extra_max", 4),
+			OneWayPct: oneWay,
+		}
+		if g.ExtraMax < g.ExtraMin {
+			return nil, fmt.Errorf("RING needs extra_max>=extra_min")
+		}
+		return g, nil
+	}
+}
+
+// warpSet collects lanes; a two-way lane always wins over a one-way lane between the same sectors.
+type warpSet map[[2]int]bool // directed edge -> one_way
+
+func (ws warpSet) twoWay(a, b int) {
+	if a == b {
+		return
+	}
+	ws[[2]int{a, b}] = false
+	ws[[2]int{b, a}] = false
+}
+
+func (ws warpSet) oneWay(from, to int) {
+	if from == to {
+		return
+	}
+	if _, ok := ws[[2]int{from, to}]; ok {
+		return
+	}
+	ws[[2]int{from, to}] = true
+}
+
+// link adds a random lane between a and b that is one-way (a->b) with probability pct%.
+func (ws warpSet) link(rng *rand.Rand, a, b, pct int) {
+	if pct > 0 && rng.Intn(100) < pct {
+		ws.oneWay(a, b)
+		return
+	}
+	ws.twoWay(a, b)
+}
+
+func (ws warpSet) list() []Warp {
+	out := make([]Warp, 0, len(ws))
+	for e, ow := range ws {
+		out = append(out, Warp{From: e[0], To: e[1], OneWay: ow})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].From != out[j].From {
+			return out[i].From < out[j].From
+		}
+		return out[i].To < out[j].To
+	})
+	return out
+}
+
+// ringGenerator is the original topology: a two-way ring plus random extra lanes.
+type ringGenerator struct {
+	ExtraMin  int
+	ExtraMax  int
+	OneWayPct int
+}
+
+func (ringGenerator) Name() string { return GeneratorRing }
+
+func (g ringGenerator) Warps(rng *rand.Rand, n int) []Warp {
+	ws := warpSet{}
+	for i := 1; i <= n; i++ {
+		next := i + 1
+		if next > n {
+			next = 1
+		}
+		ws.twoWay(i, next)
+	}
+	for i := 1; i <= n; i++ {
+		extra := g.ExtraMin + rng.Intn(g.ExtraMax-g.ExtraMin+1)
+		for j := 0; j < extra; j++ {
+			to := 1 + rng.Intn(n)
+			if to == i {
+				continue
+			}
+			ws.link(rng, i, to, g.OneWayPct)
+		}
+	}
+	return ws.list()
+}
+
+// clusteredGenerator splits sectors into contiguous regions that are dense inside and joined to
+// the next region only through a few chokepoint lanes.
+type clusteredGenerator struct {
+	Regions     int
+	Chokepoints int
+	ExtraMin    int
+	ExtraMax    int
+	OneWayPct   int
+}
+
+func (clusteredGenerator) Name() string { return GeneratorClustered }
+
+// regionBounds returns the first and last sector of region r (0-based) for n sectors.
+func regionBounds(n, regions, r int) (int, int) {
+	size := n / regions
+	lo := 1 + r*size
+	hi := lo + size - 1
+	if r == regions-1 {
+		hi = n
+	}
+	return lo, hi
+}
+
+func (g clusteredGenerator) Warps(rng *rand.Rand, n int) []Warp {
+	regions := g.Regions
+	if regions > n/3 {
+		regions = max(1, n/3)
+	}
+	ws := warpSet{}
+	for r := 0; r < regions; r++ {
+		lo, hi := regionBounds(n, regions, r)
+		for i := lo; i <= hi; i++ {
+			next := i + 1
+			if next > hi {
+				next = lo
+			}
+			ws.twoWay(i, next)
+		}
+		for i := lo; i <= hi; i++ {
+			extra := g.ExtraMin + rng.Intn(g.ExtraMax-g.ExtraMin+1)
+			for j := 0; j < extra; j++ {
+				to := lo + rng.Intn(hi-lo+1)
+				if to == i {
+					continue
+				}
+				ws.link(rng, i, to, g.OneWayPct)
+			}
+		}
+	}
+	// Chokepoints: region r links to region r+1 (wrapping) only through a few gateway lanes.
+	if regions > 1 {
+		for r := 0; r < regions; r++ {
+			alo, ahi := regionBounds(n, regions, r)
+			blo, bhi := regionBounds(n, regions, (r+1)%regions)
+			for c := 0; c < g.Chokepoints; c++ {
+				a := alo + rng.Intn(ahi-alo+1)
+				b := blo + rng.Intn(bhi-blo+1)
+				ws.twoWay(a, b)
+			}
+		}
+	}
+	return ws.list()
+}
+
+// treeGenerator grows a random tree from sector 1, leaving many dead ends, with a few loops.
+type treeGenerator struct {
+	Branching int
+	LoopsPct  int
+	OneWayPct int
+}
+
+func (treeGenerator) Name() string { return GeneratorTree }
+
+func (g treeGenerator) Warps(rng *rand.Rand, n int) []Warp {
+	ws := warpSet{}
+	children := make([]int, n+1)
+	for i := 2; i <= n; i++ {
+		parent := 1 + rng.Intn(i-1)
+		for children[parent] >= g.Branching {
+			parent = parent%(i-1) + 1
+		}
+		children[parent]++
+		ws.twoWay(parent, i)
+	}
+	loops := n * g.LoopsPct / 100
+	for k := 0; k < loops; k++ {
+		a := 1 + rng.Intn(n)
+		b := 1 + rng.Intn(n)
+		ws.link(rng, a, b, g.OneWayPct)
+	}
+	return ws.list()
+}
+
+// oneWayGenerator is a one-way ring (1->2->...->n->1) plus random extra lanes, one_way_pct of which
+// are one-way too. The directed ring alone keeps every sector reachable from every other, but the
+// way back is often the long way round.
+type oneWayGenerator struct {
+	ExtraMin  int
+	ExtraMax  int
+	OneWayPct int
+}
+
+func (oneWayGenerator) Name() string { return GeneratorOneWay }
+
+func (g oneWayGenerator) Warps(rng *rand.Rand, n int) []Warp {
+	ws := warpSet{}
+	for i := 1; i <= n; i++ {
+		next := i + 1
+		if next > n {
+			next = 1
+		}
+		ws.oneWay(i, next)
+	}
+	for i := 1; i <= n; i++ {
+		extra := g.ExtraMin + rng.Intn(g.ExtraMax-g.ExtraMin+1)
+		for j := 0; j < extra; j++ {
+			to := 1 + rng.Intn(n)
+			if to == i {
+				continue
+			}
+			ws.link(rng, i, to, g.OneWayPct)
+		}
+	}
+	return ws.list()
+}
+
+// protectorateLinks returns the two-way lanes to add so every sector can reach a sector in
+// targets by following warps. A sector that cannot reach is preferably given a return lane along
+// an existing one-way lane into it; otherwise it is linked to the nearest-numbered reachable sector.
+func protectorateLinks(sectors []int, warps []Warp, targets map[int]bool) [][2]int {
+	if len(targets) == 0 {
+		return nil
+	}
+	incoming := map[int][]int{} // to -> froms
+	for _, w := range warps {
+		incoming[w.To] = append(incoming[w.To], w.From)
+	}
+
+	reach := map[int]bool{}
+	var queue []int
+	for _, id := range sectors {
+		if targets[id] {
+			reach[id] = true
+			queue = append(queue, id)
+		}
+	}
+	// Reverse BFS: anything with a lane into a reaching sector can reach too.
+	expand := func() {
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			for _, from := range incoming[cur] {
+				if !reach[from] {
+					reach[from] = true
+					queue = append(queue, from)
+				}
+			}
+		}
+	}
+	expand()
+
+	var added [][2]int
+	sorted := append([]int(nil), sectors...)
+	sort.Ints(sorted)
+	for _, id := range sorted {
+		if reach[id] {
+			continue
+		}
+		// Any lane u->id with u reaching lets us add the return lane id->u.
+		to := 0
+		for _, w := range warps {
+			if w.To == id && reach[w.From] && (to == 0 || w.From < to) {
+				to = w.From
+			}
+		}
+		if to == 0 {
+			to = nearestReaching(sorted, reach, id)
+		}
+		added = append(added, [2]int{id, to})
+		incoming[to] = append(incoming[to], id)
+		incoming[id] = append(incoming[id], to)
+		reach[id] = true
+		queue = append(queue, id)
+		expand()
+	}
+	return added
+}
+
+func nearestReaching(sorted []int, reach map[int]bool, id int) int {
+	best, bestDist := 0, 0
+	for _, other := range sorted {
+		if !reach[other] {
+			continue
+		}
+		d := other - id
+		if d < 0 {
+			d = -d
+		}
+		if best == 0 || d < bestDist {
+			best, bestDist = other, d
+		}
+	}
+	return best
+}
+
+// EnsureProtectorateReachability adds two-way lanes until every sector can reach Protectorate
+// space. It runs after Protectorate sectors are assigned and is safe to run on every startup.
+func EnsureProtectorateReachability(ctx context.Context, pool *pgxpool.Pool) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	added, err := ensureProtectorateReachabilityTx(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if added == 0 {
+		return nil
+	}
+	return tx.Commit(ctx)
+}
+
+func ensureProtectorateReachabilityTx(ctx context.Context, tx pgx.Tx) (int, error) {
+	rows, err := tx.Query(ctx, "SELECT id, is_protectorate FROM sectors ORDER BY id")
+	if err != nil {
+		return 0, err
+	}
+	var sectors []int
+	targets := map[int]bool{}
+	for rows.Next() {
+		var id int
+		var prot bool
+		if err := rows.Scan(&id, &prot); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		sectors = append(sectors, id)
+		if prot {
+			targets[id] = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	wrows, err := tx.Query(ctx, "SELECT from_sector, to_sector, one_way FROM warps ORDER BY from_sector, to_sector")
+	if err != nil {
+		return 0, err
+	}
+	var warps []Warp
+	for wrows.Next() {
+		var w Warp
+		if err := wrows.Scan(&w.From, &w.To, &w.OneWay); err != nil {
+			wrows.Close()
+			return 0, err
+		}
+		warps = append(warps, w)
+	}
+	wrows.Close()
+	if err := wrows.Err(); err != nil {
+		return 0, err
+	}
+
+	links := protectorateLinks(sectors, warps, targets)
+	for _, l := range links {
+		if err := insertTwoWayWarp(ctx, tx, l[0], l[1]); err != nil {
+			return 0, err
+		}
+	}
+	return len(links), nil
+}
+
+// insertTwoWayWarp adds (or upgrades a one-way lane to) a two-way lane between a and b.
+func insertTwoWayWarp(ctx context.Context, tx pgx.Tx, a, b int) error {
+	for _, e := range [][2]int{{a, b}, {b, a}} {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO warps(from_sector, to_sector, one_way) VALUES ($1,$2,false)
+			ON CONFLICT (from_sector, to_sector) DO UPDATE SET one_way=false
+		`, e[0], e[1])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
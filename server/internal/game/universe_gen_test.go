@@ -0,0 +1,153 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func reachableFrom(start int, warps []Warp) map[int]bool {
+	adj := map[int][]int{}
+	for _, w := range warps {
+		adj[w.From] = append(adj[w.From], w.To)
+	}
+	seen := map[int]bool{start: true}
+	queue := []int{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range adj[cur] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return seen
+}
+
+func TestGeneratorsConnectAllSectors(t *testing.T) {
+	for _, name := range []string{GeneratorRing, GeneratorClustered, GeneratorTree, GeneratorOneWay} {
+		gen, err := NewUniverseGenerator(name, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		warps := gen.Warps(rand.New(rand.NewSource(7)), 60)
+		if got := len(reachableFrom(1, warps)); got != 60 {
+			t.Fatalf("%s: expected all 60 sectors reachable from 1, got %d", name, got)
+		}
+		again := gen.Warps(rand.New(rand.NewSource(7)), 60)
+		if len(again) != len(warps) {
+			t.Fatalf("%s: expected deterministic output for the same seed", name)
+		}
+	}
+}
+
+func TestOnlyOneWayGeneratorDefaultsToOneWayWarps(t *testing.T) {
+	for _, name := range []string{GeneratorRing, GeneratorClustered, GeneratorTree, GeneratorOneWay} {
+		gen, err := NewUniverseGenerator(name, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		warps := gen.Warps(rand.New(rand.NewSource(11)), 200)
+		oneWay := 0
+		for _, w := range warps {
+			if w.OneWay {
+				oneWay++
+			}
+		}
+		if name != GeneratorOneWay && oneWay != 0 {
+			t.Fatalf("%s: expected only two-way warps by default, got %d one-way", name, oneWay)
+		}
+		if name == GeneratorOneWay && oneWay == 0 {
+			t.Fatalf("%s: expected one-way warps", name)
+		}
+		for _, start := range []int{1, 57, 200} {
+			if got := len(reachableFrom(start, warps)); got != 200 {
+				t.Fatalf("%s: expected all 200 sectors reachable from %d, got %d", name, start, got)
+			}
+		}
+	}
+}
+
+func TestTreeGeneratorHasDeadEnds(t *testing.T) {
+	gen, _ := NewUniverseGenerator(GeneratorTree, GeneratorParams{"loops_pct": 0})
+	warps := gen.Warps(rand.New(rand.NewSource(3)), 50)
+	// A pure tree has exactly n-1 two-way lanes.
+	if len(warps) != 2*49 {
+		t.Fatalf("expected 98 directed warps, got %d", len(warps))
+	}
+	degree := map[int]int{}
+	for _, w := range warps {
+		degree[w.From]++
+	}
+	deadEnds := 0
+	for _, d := range degree {
+		if d == 1 {
+			deadEnds++
+		}
+	}
+	if deadEnds == 0 {
+		t.Fatalf("expected dead-end sectors in a tree universe")
+	}
+}
+
+func TestNewUniverseGeneratorRejectsBadConfig(t *testing.T) {
+	if _, err := NewUniverseGenerator("SPIRAL", nil); err == nil {
+		t.Fatalf("expected unknown generator error")
+	}
+	if _, err := NewUniverseGenerator(GeneratorRing, GeneratorParams{"regions": 4}); err == nil {
+		t.Fatalf("expected RING to reject regions param")
+	}
+	if _, err := ParseGeneratorParams("regions=six"); err == nil {
+		t.Fatalf("expected parse error")
+	}
+	p, err := ParseGeneratorParams(" regions=4, Chokepoints=2 ")
+	if err != nil || p["regions"] != 4 || p["chokepoints"] != 2 {
+		t.Fatalf("unexpected params %v (%v)", p, err)
+	}
+}
+
+func TestProtectorateLinksFixOneWayTraps(t *testing.T) {
+	// 1 <-> 2 (2 is Protectorate), 2 -> 3 one-way, 4 isolated.
+	warps := []Warp{
+		{From: 1, To: 2}, {From: 2, To: 1},
+		{From: 2, To: 3, OneWay: true},
+	}
+	links := protectorateLinks([]int{1, 2, 3, 4}, warps, map[int]bool{2: true})
+	if len(links) != 2 {
+		t.Fatalf("expected 2 links, got %v", links)
+	}
+	// Sector 3 gets the return lane along the one-way warp into it.
+	if links[0] != [2]int{3, 2} {
+		t.Fatalf("expected 3->2 return lane, got %v", links[0])
+	}
+	// Sector 4 is linked to the nearest reaching sector.
+	if links[1] != [2]int{4, 3} {
+		t.Fatalf("expected 4->3 link, got %v", links[1])
+	}
+}
+
+func TestGraftLinksFrontierUsesOnlyGateways(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	links := graftLinks(rng, []int{1, 2, 3, 4, 5}, 6, 25, []int{4, 2})
+	if len(links) != 2 {
+		t.Fatalf("expected one lane per gateway, got %v", links)
+	}
+	for _, l := range links {
+		if l[0] != 2 && l[0] != 4 {
+			t.Fatalf("lane %v does not start at a gateway", l)
+		}
+		if l[1] < 6 || l[1] > 25 {
+			t.Fatalf("lane %v does not end in the new region", l)
+		}
+	}
+	// The first gateway enters at the first new sector, which the generators treat as the root.
+	if links[1] != [2]int{4, 6} {
+		t.Fatalf("expected 4->6 entry lane, got %v", links)
+	}
+
+	open := graftLinks(rand.New(rand.NewSource(5)), []int{1, 2, 3}, 4, 33, nil)
+	if len(open) == 0 || len(open) > 3 {
+		t.Fatalf("expected up to 3 random lanes for 30 sectors, got %v", open)
+	}
+}
@@ -102,6 +102,14 @@ ALTER TABLE players
 	ADD COLUMN IF NOT EXISTS ship_cargo_upgrades integer NOT NULL DEFAULT 0;
 ALTER TABLE players
 	ADD COLUMN IF NOT EXISTS ship_turn_upgrades integer NOT NULL DEFAULT 0;
+ALTER TABLE players
+	ADD COLUMN IF NOT EXISTS cargo_colonists integer NOT NULL DEFAULT 0;
+ALTER TABLE players
+	ADD COLUMN IF NOT EXISTS cargo_contraband integer NOT NULL DEFAULT 0;
+ALTER TABLE players
+	ADD COLUMN IF NOT EXISTS ship_mines integer NOT NULL DEFAULT 0;
+ALTER TABLE players
+	ADD COLUMN IF NOT EXISTS ship_scanner boolean NOT NULL DEFAULT false;
 
 CREATE INDEX IF NOT EXISTS idx_players_season_xp ON players(season_id, xp DESC);
 CREATE INDEX IF NOT EXISTS idx_players_season_level ON players(season_id, level DESC);
@@ -206,6 +214,15 @@ CREATE INDEX IF NOT EXISTS idx_planets_sector_id ON planets(sector_id);
 CREATE INDEX IF NOT EXISTS idx_planets_owner_player_id ON planets(owner_player_id);
 CREATE INDEX IF NOT EXISTS idx_planets_owner_corp_id ON planets(owner_corp_id);
 
+-- Planet production chains: class selects the recipe; shortage records why production stalled
+ALTER TABLE planets
+	ADD COLUMN IF NOT EXISTS planet_class text NOT NULL DEFAULT 'M',
+	ADD COLUMN IF NOT EXISTS shortage text NOT NULL DEFAULT '';
+
+-- Planet population: existing planets keep a full workforce so their output is unchanged
+ALTER TABLE planets
+	ADD COLUMN IF NOT EXISTS population integer NOT NULL DEFAULT 1000;
+
 -- Phase 2: mines
 CREATE TABLE IF NOT EXISTS mines (
 	sector_id integer NOT NULL REFERENCES sectors(id) ON DELETE CASCADE,
@@ -245,6 +262,15 @@ CREATE TABLE IF NOT EXISTS player_sector_intel (
 CREATE INDEX IF NOT EXISTS idx_player_sector_intel_player_id_scanned_at ON player_sector_intel(player_id, scanned_at DESC);
 CREATE INDEX IF NOT EXISTS idx_player_sector_intel_sector_id ON player_sector_intel(sector_id);
 
+-- Per-player hazard intel (hostile minefields observed via SCAN or on entry), used by SAFE routing
+CREATE TABLE IF NOT EXISTS player_sector_hazards (
+	player_id text NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+	sector_id integer NOT NULL REFERENCES sectors(id) ON DELETE CASCADE,
+	hostile_mines integer NOT NULL DEFAULT 0,
+	observed_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (player_id, sector_id)
+);
+
 -- Phase 3: scheduled events (anomalies, invasions, limited-time sectors)
 CREATE TABLE IF NOT EXISTS events (
 	id bigserial PRIMARY KEY,
@@ -308,6 +334,213 @@ CREATE TABLE IF NOT EXISTS direct_message_attachments (
 );
 
 CREATE INDEX IF NOT EXISTS idx_direct_message_attachments_message_id ON direct_message_attachments(message_id);
+
+-- Protectorate bank: player deposits and loans (collected by the bank ticker on default)
+CREATE TABLE IF NOT EXISTS bank_accounts (
+	player_id text PRIMARY KEY REFERENCES players(id) ON DELETE CASCADE,
+	deposit bigint NOT NULL DEFAULT 0,
+	loan bigint NOT NULL DEFAULT 0,
+	loan_due_at timestamptz,
+	defaulted boolean NOT NULL DEFAULT false,
+	updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_accounts_loan_due_at ON bank_accounts(loan_due_at) WHERE loan > 0;
+
+-- Corp member interest: the corp pays members a per-tick rate on what they have deposited
+ALTER TABLE corporations
+	ADD COLUMN IF NOT EXISTS member_interest_bps integer NOT NULL DEFAULT 0;
+
+ALTER TABLE corp_members
+	ADD COLUMN IF NOT EXISTS deposit_balance bigint NOT NULL DEFAULT 0;
+
+-- Procedural names: regions and flavor text for sectors, names for ports
+ALTER TABLE sectors
+	ADD COLUMN IF NOT EXISTS region text NOT NULL DEFAULT '';
+ALTER TABLE sectors
+	ADD COLUMN IF NOT EXISTS description text NOT NULL DEFAULT '';
+ALTER TABLE ports
+	ADD COLUMN IF NOT EXISTS name text NOT NULL DEFAULT '';
+
+-- Special port classes (STARDOCK, BLACK_MARKET, FUEL_DEPOT) on top of commodity trading
+ALTER TABLE ports
+	ADD COLUMN IF NOT EXISTS port_class text NOT NULL DEFAULT 'TRADING';
+ALTER TABLE ports
+	ADD COLUMN IF NOT EXISTS contraband_price integer NOT NULL DEFAULT 0;
+CREATE TABLE IF NOT EXISTS fuel_purchases (
+	id bigserial PRIMARY KEY,
+	player_id text NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+	turns integer NOT NULL,
+	credits bigint NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_fuel_purchases_player_created ON fuel_purchases(player_id, created_at DESC);
+
+-- Sector environments (NEBULA, ION_STORM, ASTEROID_FIELD); '' means not yet assigned
+ALTER TABLE sectors
+	ADD COLUMN IF NOT EXISTS environment text NOT NULL DEFAULT '';
+
+-- Corporation membership modes (OPEN, INVITE, APPLY), pending invites and applications
+ALTER TABLE corporations
+	ADD COLUMN IF NOT EXISTS membership text NOT NULL DEFAULT 'OPEN';
+CREATE TABLE IF NOT EXISTS corp_invites (
+	id bigserial PRIMARY KEY,
+	corp_id text NOT NULL REFERENCES corporations(id) ON DELETE CASCADE,
+	player_id text NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+	kind text NOT NULL,
+	created_by text REFERENCES players(id) ON DELETE SET NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	expires_at timestamptz NOT NULL,
+	UNIQUE (corp_id, player_id)
+);
+CREATE INDEX IF NOT EXISTS idx_corp_invites_player_id ON corp_invites(player_id);
+
+-- Player-built warp gates (GATE BUILD). A gate's two warps reference it and are removed with it.
+CREATE TABLE IF NOT EXISTS warp_gates (
+	id bigserial PRIMARY KEY,
+	sector_a integer NOT NULL REFERENCES sectors(id) ON DELETE CASCADE,
+	sector_b integer NOT NULL REFERENCES sectors(id) ON DELETE CASCADE,
+	owner_player_id text REFERENCES players(id) ON DELETE SET NULL,
+	owner_corp_id text REFERENCES corporations(id) ON DELETE SET NULL,
+	toll bigint NOT NULL DEFAULT 0,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	UNIQUE (sector_a, sector_b)
+);
+ALTER TABLE warps
+	ADD COLUMN IF NOT EXISTS gate_id bigint REFERENCES warp_gates(id) ON DELETE CASCADE;
+
+-- Corporation permission matrix: the lowest role allowed each permission (defaults apply when absent)
+CREATE TABLE IF NOT EXISTS corp_permissions (
+	corp_id text NOT NULL REFERENCES corporations(id) ON DELETE CASCADE,
+	permission text NOT NULL,
+	min_role text NOT NULL,
+	PRIMARY KEY (corp_id, permission)
+);
+ALTER TABLE corp_members
+	ADD COLUMN IF NOT EXISTS muted boolean NOT NULL DEFAULT false;
+
+-- Corporation diplomacy: one row per pair of corps (corp_a < corp_b) with the current state
+-- (ALLIED, NEUTRAL, NAP, WAR) and any pending proposal
+CREATE TABLE IF NOT EXISTS corp_relations (
+	corp_a text NOT NULL REFERENCES corporations(id) ON DELETE CASCADE,
+	corp_b text NOT NULL REFERENCES corporations(id) ON DELETE CASCADE,
+	state text NOT NULL DEFAULT 'NEUTRAL',
+	shared_planets boolean NOT NULL DEFAULT false,
+	since timestamptz NOT NULL DEFAULT now(),
+	proposed_state text NOT NULL DEFAULT '',
+	proposed_shared_planets boolean NOT NULL DEFAULT false,
+	proposed_by text REFERENCES corporations(id) ON DELETE CASCADE,
+	proposed_at timestamptz,
+	PRIMARY KEY (corp_a, corp_b),
+	CHECK (corp_a < corp_b)
+);
+CREATE INDEX IF NOT EXISTS idx_corp_relations_corp_b ON corp_relations(corp_b);
+
+-- Galaxy news feed (war declarations, treaties)
+CREATE TABLE IF NOT EXISTS galaxy_news (
+	id bigserial PRIMARY KEY,
+	kind text NOT NULL,
+	headline text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_galaxy_news_created_at ON galaxy_news(created_at DESC);
+
+-- Corp-shared market intel: corps may pool all members' port scans (share_intel), and members
+-- may push individual scans to their corp (CORP SHARE INTEL)
+ALTER TABLE corporations
+	ADD COLUMN IF NOT EXISTS share_intel boolean NOT NULL DEFAULT false;
+ALTER TABLE player_sector_intel
+	ADD COLUMN IF NOT EXISTS shared boolean NOT NULL DEFAULT false;
+
+-- Corp bank ledger, per-role daily withdrawal limits (0 = unlimited) and withdrawals awaiting
+-- leader approval (above withdraw_approval_threshold when it is set)
+CREATE TABLE IF NOT EXISTS corp_bank_transactions (
+	id bigserial PRIMARY KEY,
+	corp_id text NOT NULL REFERENCES corporations(id) ON DELETE CASCADE,
+	player_id text REFERENCES players(id) ON DELETE SET NULL,
+	kind text NOT NULL,
+	amount bigint NOT NULL,
+	balance_after bigint NOT NULL,
+	note text NOT NULL DEFAULT '',
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_corp_bank_transactions_corp_created ON corp_bank_transactions(corp_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_corp_bank_transactions_player_created ON corp_bank_transactions(player_id, created_at DESC);
+ALTER TABLE corporations
+	ADD COLUMN IF NOT EXISTS withdraw_limit_officer bigint NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS withdraw_limit_member bigint NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS withdraw_approval_threshold bigint NOT NULL DEFAULT 0;
+CREATE TABLE IF NOT EXISTS corp_withdrawal_requests (
+	id bigserial PRIMARY KEY,
+	corp_id text NOT NULL REFERENCES corporations(id) ON DELETE CASCADE,
+	player_id text NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+	amount bigint NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_corp_withdrawal_requests_corp_id ON corp_withdrawal_requests(corp_id);
+
+-- Corp chat history API: per-member read marker and an id index for cursor pagination
+ALTER TABLE corp_members
+	ADD COLUMN IF NOT EXISTS chat_read_id bigint NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_corp_messages_corp_id_id ON corp_messages(corp_id, id DESC);
+
+-- Season lifecycle: scheduled end, victory targets, end-of-season freeze
+ALTER TABLE seasons
+	ADD COLUMN IF NOT EXISTS ends_at timestamptz,
+	ADD COLUMN IF NOT EXISTS victory_net_worth bigint NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS victory_planets integer NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS warn_stage integer NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS frozen_at timestamptz,
+	ADD COLUMN IF NOT EXISTS end_reason text NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS winner_player_id text REFERENCES players(id) ON DELETE SET NULL,
+	ADD COLUMN IF NOT EXISTS winner_name text NOT NULL DEFAULT '';
+
+-- Season archive: final standings written by the soft wipe (HALL, /api/seasons)
+CREATE TABLE IF NOT EXISTS season_results (
+	season_id integer NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+	rank integer NOT NULL,
+	player_id text REFERENCES players(id) ON DELETE SET NULL,
+	username text NOT NULL,
+	corp_name text NOT NULL DEFAULT '',
+	credits bigint NOT NULL DEFAULT 0,
+	net_worth bigint NOT NULL DEFAULT 0,
+	xp bigint NOT NULL DEFAULT 0,
+	level integer NOT NULL DEFAULT 1,
+	planets integer NOT NULL DEFAULT 0,
+	PRIMARY KEY (season_id, rank)
+);
+CREATE INDEX IF NOT EXISTS idx_season_results_player_id ON season_results(player_id);
+
+-- Per-season rule sets (game.SeasonRules as JSON; omitted keys use the server defaults)
+ALTER TABLE seasons
+	ADD COLUMN IF NOT EXISTS rules jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+-- Ranking snapshot for the active season, rebuilt by the ranking ticker (RANKINGS)
+CREATE TABLE IF NOT EXISTS ranking_snapshots (
+	season_id integer NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+	player_id text NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+	username text NOT NULL,
+	corp_id text NOT NULL DEFAULT '',
+	corp_name text NOT NULL DEFAULT '',
+	net_worth bigint NOT NULL DEFAULT 0,
+	credits bigint NOT NULL DEFAULT 0,
+	xp bigint NOT NULL DEFAULT 0,
+	level integer NOT NULL DEFAULT 1,
+	planets integer NOT NULL DEFAULT 0,
+	updated_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (season_id, player_id)
+);
+CREATE INDEX IF NOT EXISTS idx_ranking_snapshots_net_worth ON ranking_snapshots(season_id, net_worth DESC);
+CREATE INDEX IF NOT EXISTS idx_ranking_snapshots_xp ON ranking_snapshots(season_id, xp DESC);
+CREATE INDEX IF NOT EXISTS idx_ranking_snapshots_planets ON ranking_snapshots(season_id, planets DESC);
+
+-- Navigation beacons: one player message per sector (BEACON SET)
+CREATE TABLE IF NOT EXISTS sector_beacons (
+	sector_id integer PRIMARY KEY REFERENCES sectors(id) ON DELETE CASCADE,
+	player_id text NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+	message text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now()
+);
 `
 
 func Ensure(ctx context.Context, pool *pgxpool.Pool) error {
@@ -9,8 +9,10 @@
   let currentPlayer = null;
   let currentSector = null;
   let unreadTimer = null;
-  let activePage = "game"; // game | messages | adminMap
+  let activePage = "game"; // game | messages | corpChat | adminMap
   let activeMsgTab = "inbox"; // inbox | sent
+  let corpChatMessages = []; // newest first, as returned by /api/corp/chat
+  let corpChatNextBefore = null;
 
   // Auth UI
   const auth = $("auth");
@@ -37,6 +39,8 @@
 
   const messagesNavBtn = $("messagesNavBtn");
   const msgBadge = $("msgBadge");
+  const corpChatNavBtn = $("corpChatNavBtn");
+  const corpChatBadge = $("corpChatBadge");
   const adminMapBtn = $("adminMapBtn");
   const refreshBtn = $("refreshBtn");
   const logoutBtn = $("logoutBtn");
@@ -59,6 +63,7 @@
   // Pages
   const pageGame = $("pageGame");
   const pageMessages = $("pageMessages");
+  const pageCorpChat = $("pageCorpChat");
   const pageAdminMap = $("pageAdminMap");
 
   // Messaging page
@@ -82,6 +87,14 @@
   const cancelReplyBtn = $("cancelReplyBtn");
   const msgSendStatus = $("msgSendStatus");
 
+  // Corp chat page
+  const refreshCorpChatBtn = $("refreshCorpChatBtn");
+  const olderCorpChatBtn = $("olderCorpChatBtn");
+  const corpChatList = $("corpChatList");
+  const corpChatForm = $("corpChatForm");
+  const corpChatText = $("corpChatText");
+  const corpChatStatus = $("corpChatStatus");
+
   // Admin map page
   const refreshAdminMapBtn = $("refreshAdminMapBtn");
   const adminMapPre = $("adminMapPre");
@@ -178,6 +191,7 @@
     activePage = page;
     show(pageGame, page === "game");
     show(pageMessages, page === "messages");
+    show(pageCorpChat, page === "corpChat");
     show(pageAdminMap, page === "adminMap");
   }
 
@@ -191,6 +205,16 @@
     }
   }
 
+  function updateCorpChatBadge(unread) {
+    const n = Number(unread || 0);
+    if (n > 0) {
+      corpChatBadge.textContent = String(n);
+      show(corpChatBadge, true);
+    } else {
+      show(corpChatBadge, false);
+    }
+  }
+
   async function refreshUnreadCount() {
     if (!token) return;
     try {
@@ -199,6 +223,17 @@
     } catch {
       // ignore
     }
+    if (!currentPlayer?.corp_name) return;
+    if (activePage === "corpChat") {
+      await refreshCorpChat();
+      return;
+    }
+    try {
+      const data = await apiFetch("/api/corp/chat?limit=1");
+      updateCorpChatBadge(data?.unread || 0);
+    } catch {
+      // ignore
+    }
   }
 
   function startUnreadPolling() {
@@ -235,13 +270,18 @@
     seasonName.textContent = p.season_name || "-";
     credits.textContent = String(p.credits ?? 0);
     turns.textContent = `${p.turns ?? 0}/${p.turns_max ?? 0}`;
-    cargo.textContent = `${(p.cargo_ore ?? 0) + (p.cargo_organics ?? 0) + (p.cargo_equipment ?? 0)}`;
+    cargo.textContent = `${(p.cargo_ore ?? 0) + (p.cargo_organics ?? 0) + (p.cargo_equipment ?? 0) + (p.cargo_colonists ?? 0) + (p.cargo_contraband ?? 0)}`;
     cargoCap.textContent = String(p.cargo_max ?? 0);
 
     // Optional status placeholders
     discCount.textContent = "-";
     playerCount.textContent = "-";
     corpInfo.textContent = p.corp_name ? `${p.corp_name} (${p.corp_role || ""})` : "-";
+    show(corpChatNavBtn, !!p.corp_name);
+    if (!p.corp_name) {
+      updateCorpChatBadge(0);
+      if (activePage === "corpChat") setPage("game");
+    }
 
     // Admin-only UI
     if (p.is_admin) {
@@ -257,12 +297,21 @@
 
     const lines = [];
     lines.push(`Sector: ${s.id} ${s.name}`);
+    if (s.region) lines.push(`Region: ${s.region}`);
+    if (s.description) lines.push(s.description);
+    const envLabels = { NEBULA: "Nebula (scans blocked)", ION_STORM: "Ion storm (MOVE costs double)", ASTEROID_FIELD: "Asteroid field (hull damage risk)" };
+    if (envLabels[s.environment]) lines.push(`Environment: ${envLabels[s.environment]}`);
     if (s.is_protectorate) {
       lines.push(`Protectorate space: ${s.protectorate_fighters ?? 0} fighters on patrol.`);
       lines.push(`Shipyard: ${s.has_shipyard ? "available" : "-"}`);
     }
-    lines.push(`Warps: ${(s.warps || []).join(", ") || "(none)"}`);
-    lines.push(`Mines: ${s.mines ?? 0}`);
+    const gateTo = new Set((s.gates || []).map((g) => g.to));
+    lines.push(`Warps: ${(s.warps || []).map((w) => (gateTo.has(w) ? `${w}[gate]` : `${w}`)).join(", ") || "(none)"}`);
+    for (const g of s.gates || []) {
+      lines.push(`Gate to ${g.to}: owner ${g.owner || "unknown"}, toll ${g.toll} credits`);
+    }
+    lines.push(s.mines_hidden ? "Mines: unknown (nebula)" : `Mines: ${s.mines ?? 0}`);
+    if (s.beacon) lines.push(`Beacon (${s.beacon.author || "unknown"}): ${s.beacon.message}`);
 
     if (s.planet) {
       const owner = s.planet.owner || "(unowned)";
@@ -290,6 +339,8 @@
 
     const lines = [];
     lines.push(`Port: ${p.name || "(spaceport)"}`);
+    if (p.class && p.class !== "TRADING") lines.push(`Class: ${p.class.replace("_", " ")}`);
+    if (p.contraband_price) lines.push(`Contraband: sells ${p.contraband_price} / buys ${p.contraband_bid}`);
     lines.push(`Ore: ${p.ore_mode} Qty=${p.ore_qty} Price=${p.ore_price}`);
     lines.push(`Organics: ${p.organics_mode} Qty=${p.organics_qty} Price=${p.organics_price}`);
     lines.push(`Equipment: ${p.equipment_mode} Qty=${p.equipment_qty} Price=${p.equipment_price}`);
@@ -346,7 +397,11 @@
     const parts = raw.split(/\s+/);
     const type = (parts[0] || "").toUpperCase();
 
-    if (type === "SCAN") return { type: "SCAN" };
+    if (type === "SCAN") {
+      const action = (parts[1] || "").toUpperCase();
+      if (action === "DENSITY" || action === "HOLO") return { type: "SCAN", action };
+      return { type: "SCAN" };
+    }
 
     if (type === "MOVE") {
       const to = Number(parts[1]);
@@ -365,13 +420,54 @@
     if (type === "PLANET") {
       const action = (parts[1] || "INFO").toUpperCase();
       const name = parts.slice(2).join(" ");
+      if (action === "LOAD" || action === "UNLOAD") {
+        const qty = Number(parts[3]);
+        return { type: "PLANET", action, commodity: (parts[2] || "").toUpperCase(), quantity: Number.isFinite(qty) ? qty : 0 };
+      }
       return { type: "PLANET", action, name };
     }
 
     if (type === "CORP") {
       const action = (parts[1] || "INFO").toUpperCase();
       const name = parts.slice(2).join(" ");
-      return { type: "CORP", action, name, text: parts.slice(2).join(" ") };
+      const qty = Number(parts[2]);
+      return { type: "CORP", action, name, text: parts.slice(2).join(" "), quantity: Number.isFinite(qty) ? qty : 0 };
+    }
+
+    if (type === "BANK") {
+      const action = (parts[1] || "INFO").toUpperCase();
+      const qty = parts[2] ? Number(parts[2]) : 0;
+      return { type: "BANK", action, quantity: Number.isFinite(qty) ? qty : 0 };
+    }
+
+    if (type === "STARDOCK") {
+      const action = (parts[1] || "INFO").toUpperCase();
+      const qty = parts[3] ? Number(parts[3]) : 0;
+      return { type: "STARDOCK", action, name: (parts[2] || "").toUpperCase(), quantity: Number.isFinite(qty) ? qty : 0 };
+    }
+
+    if (type === "FUEL" || type === "BLACKMARKET") {
+      const action = (parts[1] || "INFO").toUpperCase();
+      const qty = parts[2] ? Number(parts[2]) : 0;
+      return { type, action, quantity: Number.isFinite(qty) ? qty : 0 };
+    }
+
+    if (type === "GATE") {
+      const action = (parts[1] || "INFO").toUpperCase();
+      const to = Number(parts[2]);
+      const qty = Number(parts[3]);
+      return {
+        type: "GATE",
+        action,
+        to: Number.isFinite(to) ? to : 0,
+        quantity: Number.isFinite(qty) ? qty : 0,
+        name: (parts[3] || "").toUpperCase(),
+      };
+    }
+
+    if (type === "BEACON") {
+      const action = (parts[1] || "INFO").toUpperCase();
+      return { type: "BEACON", action, text: parts.slice(2).join(" ") };
     }
 
     if (type === "MINE") {
@@ -387,11 +483,31 @@
     }
 
     if (type === "HELP") return { type: "HELP" };
-    if (type === "RANKINGS") return { type: "RANKINGS" };
+    if (type === "RANKINGS") {
+      const args = parts.slice(1);
+      const page = Number(args.find((a) => /^\d+$/.test(a)) || 0);
+      const action = (args.find((a) => !/^\d+$/.test(a)) || "").toUpperCase();
+      return { type: "RANKINGS", action, quantity: page };
+    }
     if (type === "SEASON") return { type: "SEASON" };
-    if (type === "MARKET") return { type: "MARKET" };
-    if (type === "ROUTE") return { type: "ROUTE" };
+    if (type === "HALL") {
+      const season = Number(parts[1]);
+      return { type: "HALL", quantity: Number.isFinite(season) ? season : 0 };
+    }
+    if (type === "MARKET") return { type: "MARKET", commodity: (parts[1] || "").toUpperCase() };
+    if (type === "ROUTE") {
+      let args = parts.slice(1).map((p) => p.toUpperCase());
+      const run = args[0] === "RUN";
+      if (run) args = args.slice(1);
+      const modes = ["FAST", "SAFE", "PROTECTED"];
+      const mode = args.find((a) => modes.includes(a)) || "";
+      const trips = Number(args.find((a) => /^\d+$/.test(a)) || 0);
+      const commodity = args.find((a) => !modes.includes(a) && !/^\d+$/.test(a)) || "";
+      if (run) return { type: "ROUTE", action: "RUN", name: mode, commodity, quantity: trips };
+      return { type: "ROUTE", action: mode, commodity };
+    }
     if (type === "EVENTS") return { type: "EVENTS" };
+    if (type === "NEWS") return { type: "NEWS" };
 
     return null;
   }
@@ -627,6 +743,112 @@
     }
   }
 
+  function renderCorpChat() {
+    show(olderCorpChatBtn, corpChatNextBefore !== null);
+    if (corpChatMessages.length === 0) {
+      corpChatList.textContent = "(no messages)";
+      return;
+    }
+
+    corpChatList.innerHTML = "";
+    // Oldest at the top, like a chat window.
+    for (const m of [...corpChatMessages].reverse()) {
+      const item = document.createElement("div");
+      item.className = "msgitem" + (m.unread ? " unread" : "");
+
+      const meta = document.createElement("div");
+      meta.className = "msgmeta";
+      meta.innerHTML =
+        `<span><strong>${escapeHtml(m.from)}</strong></span>` +
+        `<span class="small">${escapeHtml(fmtTime(m.created_at))}</span>`;
+
+      const body = document.createElement("div");
+      body.className = "pre";
+      body.textContent = m.message || "";
+
+      const actions = document.createElement("div");
+      actions.className = "msgactions";
+      const delBtn = document.createElement("button");
+      delBtn.className = "ghost";
+      delBtn.textContent = "Delete";
+      delBtn.addEventListener("click", async () => {
+        if (!confirm("Delete this corp message?")) return;
+        try {
+          await apiFetch("/api/corp/chat/delete", { method: "POST", json: { message_id: m.id } });
+          corpChatMessages = corpChatMessages.filter((x) => x.id !== m.id);
+          renderCorpChat();
+        } catch (e) {
+          alert(e.message || "Delete failed");
+        }
+      });
+      actions.appendChild(delBtn);
+
+      item.appendChild(meta);
+      item.appendChild(body);
+      item.appendChild(actions);
+      corpChatList.appendChild(item);
+    }
+  }
+
+  async function markCorpChatRead() {
+    const newest = corpChatMessages[0];
+    if (!newest || !corpChatMessages.some((m) => m.unread)) {
+      updateCorpChatBadge(0);
+      return;
+    }
+    try {
+      await apiFetch("/api/corp/chat/mark_read", { method: "POST", json: { up_to: newest.id } });
+      updateCorpChatBadge(0);
+    } catch {
+      // ignore
+    }
+  }
+
+  async function refreshCorpChat() {
+    try {
+      const data = await apiFetch("/api/corp/chat");
+      corpChatMessages = data?.messages || [];
+      corpChatNextBefore = data?.next_before ?? null;
+      renderCorpChat();
+      await markCorpChatRead();
+    } catch (e) {
+      corpChatList.textContent = e.message || "Failed to load corp chat";
+      if (e.status === 401) logout();
+    }
+  }
+
+  async function loadOlderCorpChat() {
+    if (corpChatNextBefore === null) return;
+    try {
+      const data = await apiFetch(`/api/corp/chat?before=${corpChatNextBefore}`);
+      corpChatMessages = corpChatMessages.concat(data?.messages || []);
+      corpChatNextBefore = data?.next_before ?? null;
+      renderCorpChat();
+    } catch (e) {
+      corpChatStatus.textContent = e.message || "Failed to load older messages";
+      if (e.status === 401) logout();
+    }
+  }
+
+  async function sendCorpChat(e) {
+    e.preventDefault();
+    corpChatStatus.textContent = "";
+    const text = (corpChatText.value || "").trim();
+    if (!text) return;
+    try {
+      const resp = await apiFetch("/api/command", { method: "POST", json: { type: "CORP", action: "SAY", text } });
+      if (!resp?.ok) {
+        corpChatStatus.textContent = resp?.message || "Send failed";
+        return;
+      }
+      corpChatText.value = "";
+      await refreshCorpChat();
+    } catch (e2) {
+      corpChatStatus.textContent = e2.message || "Send failed";
+      if (e2.status === 401) logout();
+    }
+  }
+
   async function refreshAdminMap() {
     adminMapMsg.textContent = "";
     adminMapPre.textContent = "";
@@ -740,6 +962,15 @@
     }
   });
 
+  corpChatNavBtn.addEventListener("click", async () => {
+    if (activePage !== "corpChat") {
+      setPage("corpChat");
+      await refreshCorpChat();
+    } else {
+      setPage("game");
+    }
+  });
+
   adminMapBtn.addEventListener("click", async () => {
     if (activePage !== "adminMap") {
       setPage("adminMap");
@@ -764,6 +995,10 @@
 
   sendMsgForm.addEventListener("submit", sendMessage);
 
+  refreshCorpChatBtn.addEventListener("click", refreshCorpChat);
+  olderCorpChatBtn.addEventListener("click", loadOlderCorpChat);
+  corpChatForm.addEventListener("submit", sendCorpChat);
+
   refreshAdminMapBtn.addEventListener("click", refreshAdminMap);
 
   // Init